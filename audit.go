@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gocrawler/github"
+	"gocrawler/notify"
+	"gocrawler/storage"
+)
+
+// auditListLimit caps the URLs listed per section of the summary
+const auditListLimit = 20
+
+// audit is what a finished crawl reports to GitHub
+type audit struct {
+	startURL   string
+	stats      storage.Stats
+	broken     []storage.BrokenLink
+	comparison *storage.Comparison // nil without -baseline
+	checks     []ciCheck           // empty outside -ci
+	code       int                 // process exit code
+}
+
+// state maps the exit code to a commit status state
+func (a audit) state() string {
+	switch a.code {
+	case exitOK:
+		return github.StateSuccess
+	case exitErrors:
+		return github.StateFailure
+	default:
+		return github.StateError
+	}
+}
+
+// description is the one-line commit status text
+func (a audit) description() string {
+	desc := fmt.Sprintf("%d pages, %d failed", a.stats.TotalPages, a.stats.FailCount)
+	if a.comparison != nil {
+		desc += fmt.Sprintf(", %d new broken links, %d regressions", len(a.comparison.NewBroken), len(a.comparison.Regressions))
+	} else {
+		desc += fmt.Sprintf(", %d broken links", len(a.broken))
+	}
+	if a.code == exitAborted {
+		desc += " (aborted)"
+	}
+	return desc
+}
+
+// markdown renders the pull request comment
+func (a audit) markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### 🕷️ Crawl audit of %s\n\n", a.startURL)
+	sb.WriteString("| Pages | Failed | Broken links | Duration |\n|---|---|---|---|\n")
+	fmt.Fprintf(&sb, "| %d | %d | %d | %s |\n", a.stats.TotalPages, a.stats.FailCount, len(a.broken), a.stats.Duration.Round(time.Second))
+
+	if c := a.comparison; c != nil {
+		fmt.Fprintf(&sb, "\nCompared with the baseline crawl (%d pages): **%d new broken links**, %d fixed, **%d regressions**.\n",
+			c.BaselinePages, len(c.NewBroken), c.FixedBroken, len(c.Regressions))
+		if len(c.NewBroken) > 0 {
+			sb.WriteString("\n#### New broken links\n\n")
+			for i, b := range c.NewBroken {
+				if i == auditListLimit {
+					fmt.Fprintf(&sb, "- … and %d more\n", len(c.NewBroken)-auditListLimit)
+					break
+				}
+				fmt.Fprintf(&sb, "- %s → %s (%s)\n", b.Source, b.Target, brokenReason(b))
+			}
+		}
+		if len(c.Regressions) > 0 {
+			sb.WriteString("\n#### Pages that loaded in the baseline\n\n")
+			for i, page := range c.Regressions {
+				if i == auditListLimit {
+					fmt.Fprintf(&sb, "- … and %d more\n", len(c.Regressions)-auditListLimit)
+					break
+				}
+				fmt.Fprintf(&sb, "- %s: %s\n", page.URL, page.Error)
+			}
+		}
+	}
+
+	if len(a.checks) > 0 {
+		sb.WriteString("\n#### CI checks\n\n")
+		for _, check := range a.checks {
+			if check.failure == "" {
+				fmt.Fprintf(&sb, "- ✅ %s\n", check.name)
+			} else {
+				message, _, _ := strings.Cut(check.failure, "\n")
+				fmt.Fprintf(&sb, "- ❌ %s\n", message)
+			}
+		}
+	}
+	if a.code == exitAborted {
+		sb.WriteString("\n⚠️ The crawl was aborted, results are partial.\n")
+	}
+	return sb.String()
+}
+
+// brokenReason is the status code of a broken link, or its error type
+func brokenReason(b storage.BrokenLink) string {
+	if b.StatusCode != 0 {
+		return fmt.Sprintf("%d", b.StatusCode)
+	}
+	return string(b.ErrorType)
+}
+
+// githubTarget is where a commit status links to: the Actions run when
+// running in GitHub Actions
+func githubTarget(repo string) string {
+	server, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || runID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, runID)
+}
+
+// publishAudit posts the commit status and/or pull request comment,
+// logging failures since the crawl itself succeeded
+func publishAudit(gh *github.Client, repo, sha string, pr int, a audit) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if sha != "" {
+		status := github.Status{
+			State:       a.state(),
+			Description: a.description(),
+			Context:     "gocrawler",
+			TargetURL:   githubTarget(repo),
+		}
+		if err := gh.SetStatus(ctx, sha, status); err != nil {
+			log.Printf("Error setting GitHub commit status: %v", err)
+		} else {
+			fmt.Printf("🐙 GitHub status of %s set to %s\n", sha, status.State)
+		}
+	}
+	if pr > 0 {
+		if err := gh.Comment(ctx, pr, a.markdown()); err != nil {
+			log.Printf("Error commenting on pull request #%d: %v", pr, err)
+		} else {
+			fmt.Printf("🐙 Audit posted on pull request #%d\n", pr)
+		}
+	}
+}
+
+// severity grades the run for chat notifications
+func (a audit) severity() notify.Severity {
+	switch {
+	case a.code != exitOK:
+		return notify.SeverityError
+	case a.stats.FailCount > 0 || len(a.broken) > 0:
+		return notify.SeverityWarning
+	}
+	return notify.SeverityInfo
+}
+
+// notification summarizes the run for chat webhooks: failed checks,
+// then new broken links versus the baseline, or all broken links
+func (a audit) notification() notify.Summary {
+	s := notify.Summary{
+		StartURL:  a.startURL,
+		Severity:  a.severity(),
+		Pages:     a.stats.TotalPages,
+		Failed:    a.stats.FailCount,
+		Broken:    len(a.broken),
+		NewBroken: -1,
+		Duration:  a.stats.Duration,
+	}
+	for _, check := range a.checks {
+		if check.failure != "" {
+			message, _, _ := strings.Cut(check.failure, "\n")
+			s.Problems = append(s.Problems, message)
+		}
+	}
+	broken := a.broken
+	if a.comparison != nil {
+		broken = a.comparison.NewBroken
+		s.NewBroken = len(broken)
+	}
+	for i, b := range broken {
+		if i == ciExamples {
+			s.Problems = append(s.Problems, fmt.Sprintf("… and %d more broken links", len(broken)-ciExamples))
+			break
+		}
+		s.Problems = append(s.Problems, fmt.Sprintf("%s → %s (%s)", b.Source, b.Target, brokenReason(b)))
+	}
+	if a.code == exitAborted {
+		s.Problems = append(s.Problems, "The crawl was aborted, results are partial")
+	}
+	return s
+}
+
+// notifyAudit posts the run summary to the chat webhooks whose minimum
+// severity it reaches, logging failures since the crawl itself succeeded
+func notifyAudit(hooks []notify.Webhook, a audit) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	summary := a.notification()
+	for _, hook := range hooks {
+		sent, err := notify.Send(ctx, hook, summary)
+		switch {
+		case err != nil:
+			log.Printf("Error notifying %s: %v", hook.Service, err)
+		case sent:
+			fmt.Printf("💬 Crawl summary posted to %s\n", hook.Service)
+		}
+	}
+}
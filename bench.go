@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gocrawler/crawler"
+	"gocrawler/events"
+	"gocrawler/storage"
+	"gocrawler/testsite"
+)
+
+// benchCommand is the first argument selecting the benchmark:
+//
+//	gocrawler bench [flags]
+//
+// It crawls a synthetic site served in-process, so throughput and the
+// worker, rate, shard and frontier flags can be measured without
+// hitting real sites.
+const benchCommand = "bench"
+
+// bench crawls a generated site of the given shape with cfg and prints
+// the throughput. The whole site is crawled unless -depth is set. It
+// returns exitErrors when pages were missed or failed.
+func bench(cfg crawler.Config, site testsite.Config) int {
+	s, err := testsite.Start(site)
+	if err != nil {
+		log.Fatalf("Error starting the test site: %v", err)
+	}
+	defer s.Close()
+
+	depthSet := false
+	flag.Visit(func(f *flag.Flag) { depthSet = depthSet || f.Name == "depth" })
+	if !depthSet {
+		cfg.MaxDepth = s.Depth()
+	}
+	cfg.Scope = crawler.Scope{Mode: crawler.ScopeHost}
+	cfg.LogLevel = crawler.LogError
+
+	fmt.Printf("🏋️  Benchmark: %d pages, %d links each, %s latency, depth %d\n", site.Pages, site.Links, site.Latency, cfg.MaxDepth)
+	fmt.Printf("   %d workers, %d req/s, %d shards, frontier of %d\n\n", cfg.Workers, cfg.RateLimit, max(cfg.Shards, 1), cfg.FrontierSize)
+
+	results := storage.NewResults()
+	bus := events.NewBus()
+	events.Record(bus, results)
+	c := crawler.New(cfg)
+	start := time.Now()
+	c.Crawl(context.Background(), s.URL, bus)
+	elapsed := time.Since(start)
+
+	// throughput is measured up to the last page, not the idle detection
+	pages := results.GetPages()
+	var last time.Time
+	for _, page := range pages {
+		if page.CrawledAt.After(last) {
+			last = page.CrawledAt
+		}
+	}
+
+	stats := results.GetStats()
+	fmt.Printf("\n📄 Pages crawled:   %d of %d (%d failed)\n", stats.TotalPages, site.Pages, stats.FailCount)
+	fmt.Printf("⚡ Duration:        %s, last page after %s\n", elapsed.Round(time.Millisecond), last.Sub(start).Round(time.Millisecond))
+	if crawling := last.Sub(start); crawling > 0 {
+		fmt.Printf("🚀 Throughput:      %.1f pages/s\n", float64(stats.TotalPages)/crawling.Seconds())
+	}
+	p50, p95 := responsePercentiles(pages)
+	fmt.Printf("⏱️  Response time:   avg %.1f ms, p50 %d ms, p95 %d ms\n", stats.AvgResponseTime, p50.Milliseconds(), p95.Milliseconds())
+	fmt.Printf("🔁 Requests served: %d\n", s.Requests())
+	printConnStats(c.ConnStats())
+
+	if stats.TotalPages < site.Pages && !depthSet || stats.FailCount > 0 {
+		return exitErrors
+	}
+	return exitOK
+}
+
+// responsePercentiles returns the median and 95th percentile response
+// times of pages
+func responsePercentiles(pages []*storage.Page) (p50, p95 time.Duration) {
+	if len(pages) == 0 {
+		return 0, 0
+	}
+	times := make([]time.Duration, len(pages))
+	for i, page := range pages {
+		times[i] = page.ResponseTime
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times[len(times)/2], times[len(times)*95/100]
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+
+	"gocrawler/storage"
+)
+
+// ciThresholds are the limits a -ci run must stay within, negative
+// values disable a check
+type ciThresholds struct {
+	maxBroken        int     // internal links to failed pages
+	max5xx           float64 // percentage of pages answering 5xx
+	maxMissingTitles int     // successful pages without a title
+	maxViolations    int     // -rules violations
+	maxMigration     int     // -migration-map URLs not redirecting as expected
+	maxFailed        float64 // percentage of failed pages, from -error-threshold
+}
+
+// ciExamples is how many offending URLs are printed per breached check
+const ciExamples = 5
+
+// ciCheck is the outcome of one enabled threshold
+type ciCheck struct {
+	name    string
+	failure string // description of the breach, empty if the check passed
+}
+
+// ciChecks checks a finished crawl against the enabled thresholds,
+// breaches are described with a few offending URLs
+func ciChecks(results *storage.Results, t ciThresholds) []ciCheck {
+	var checks []ciCheck
+
+	if t.maxBroken >= 0 {
+		check := ciCheck{name: "broken internal links"}
+		var broken []storage.BrokenLink
+		for _, b := range results.BrokenLinks() {
+			if !b.External {
+				broken = append(broken, b)
+			}
+		}
+		if len(broken) > t.maxBroken {
+			urls := make([]string, len(broken))
+			for i, b := range broken {
+				urls[i] = fmt.Sprintf("%s -> %s", b.Source, b.Target)
+			}
+			check.failure = describe(fmt.Sprintf("%d broken internal links (max %d)", len(broken), t.maxBroken), urls)
+		}
+		checks = append(checks, check)
+	}
+
+	if t.maxFailed >= 0 {
+		check := ciCheck{name: "failed pages"}
+		stats := results.GetStats()
+		if exitCode(stats, false, t.maxFailed) == exitErrors {
+			check.failure = fmt.Sprintf("%d of %d pages failed (max %.1f%%)", stats.FailCount, stats.TotalPages, t.maxFailed)
+		}
+		checks = append(checks, check)
+	}
+
+	if t.max5xx >= 0 {
+		check := ciCheck{name: "5xx responses"}
+		stats := results.GetStats()
+		if stats.TotalPages > 0 {
+			share := float64(stats.ErrorTypes[storage.ErrorHTTP5xx]) / float64(stats.TotalPages) * 100
+			if share > t.max5xx {
+				check.failure = fmt.Sprintf("%.1f%% of pages answered 5xx (max %.1f%%)", share, t.max5xx)
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	if t.maxMissingTitles >= 0 {
+		check := ciCheck{name: "missing titles"}
+		if missing := results.MissingTitles(); len(missing) > t.maxMissingTitles {
+			check.failure = describe(fmt.Sprintf("%d pages without a title (max %d)", len(missing), t.maxMissingTitles), missing)
+		}
+		checks = append(checks, check)
+	}
+
+	if t.maxViolations >= 0 {
+		check := ciCheck{name: "page rules"}
+		if violations := results.Violations(); len(violations) > t.maxViolations {
+			urls := make([]string, len(violations))
+			for i, v := range violations {
+				urls[i] = fmt.Sprintf("%s: %s %s, expected %s, got %s", v.Rule, v.URL, v.Check, v.Expected, v.Actual)
+			}
+			check.failure = describe(fmt.Sprintf("%d page rule violations (max %d)", len(violations), t.maxViolations), urls)
+		}
+		checks = append(checks, check)
+	}
+
+	if t.maxMigration >= 0 {
+		check := ciCheck{name: "migration redirects"}
+		var urls []string
+		for _, c := range results.MigrationChecks() {
+			if c.Verdict != storage.MigrationOK {
+				urls = append(urls, fmt.Sprintf("%s -> %s (%s, expected %s)", c.Old, c.Actual, c.Verdict, c.Expected))
+			}
+		}
+		if len(urls) > t.maxMigration {
+			check.failure = describe(fmt.Sprintf("%d old URLs don't redirect to their new URL in one hop (max %d)", len(urls), t.maxMigration), urls)
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// describe appends the first offending URLs to a violation
+func describe(violation string, urls []string) string {
+	for i, u := range urls {
+		if i == ciExamples {
+			violation += fmt.Sprintf("\n       … and %d more", len(urls)-ciExamples)
+			break
+		}
+		violation += "\n       " + u
+	}
+	return violation
+}
+
+// printCIResult reports the threshold checks of a -ci run and whether
+// any failed
+func printCIResult(checks []ciCheck) bool {
+	failed := false
+	for _, check := range checks {
+		if check.failure == "" {
+			continue
+		}
+		if !failed {
+			fmt.Println("\n❌ CI checks failed:")
+			failed = true
+		}
+		fmt.Printf("   • %s\n", check.failure)
+	}
+	if !failed {
+		fmt.Println("\n✅ CI checks passed")
+	}
+	return failed
+}
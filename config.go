@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gocrawler/crawler"
+)
+
+// setting is one "name = value" line of a config file
+type setting struct {
+	name, value string
+	line        int
+}
+
+// readConfig parses a config file of "name = value" lines, as written
+// by the init wizard. Lines starting with # are comments, values may be
+// double-quoted, and repeatable flags may appear more than once.
+func readConfig(file string) ([]setting, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var settings []setting
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: want name = value", file, n)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+			}
+		}
+		if name == "config" || flag.Lookup(name) == nil {
+			return nil, fmt.Errorf("%s:%d: unknown setting %q", file, n, name)
+		}
+		settings = append(settings, setting{name: name, value: value, line: n})
+	}
+	return settings, scanner.Err()
+}
+
+// explicitFlags returns the flags given on the command line, which win
+// over the config file at startup and on every reload
+func explicitFlags() map[string]bool {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// loadConfig sets the flags of a config file that were not given on the
+// command line
+func loadConfig(file string, explicit map[string]bool) error {
+	settings, err := readConfig(file)
+	if err != nil {
+		return err
+	}
+	for _, s := range settings {
+		if explicit[s.name] {
+			continue
+		}
+		if err := flag.Set(s.name, s.value); err != nil {
+			return fmt.Errorf("%s:%d: %s: %w", file, s.line, s.name, err)
+		}
+	}
+	return nil
+}
+
+// reloadConfig applies the settings of a changed config file that are
+// safe to change during a crawl, and logs the others as needing a restart
+func reloadConfig(file string, explicit map[string]bool, c *crawler.Crawler) error {
+	settings, err := readConfig(file)
+	if err != nil {
+		return err
+	}
+
+	next := c.Settings()
+	for _, s := range settings {
+		if explicit[s.name] {
+			continue
+		}
+		switch s.name {
+		case "rate":
+			next.RateLimit, err = strconv.Atoi(s.value)
+		case "max-url-length":
+			next.MaxURLLength, err = strconv.Atoi(s.value)
+		case "max-query-params":
+			next.MaxQueryParams, err = strconv.Atoi(s.value)
+		case "log-level":
+			next.LogLevel, err = crawler.ParseLogLevel(s.value)
+		default:
+			f := flag.Lookup(s.name)
+			if _, repeatable := f.Value.(*stringList); !repeatable && f.Value.String() != s.value {
+				log.Printf("⚠️  %s changed in %s, restart to apply it", s.name, file)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s: %w", file, s.line, s.name, err)
+		}
+	}
+	if err := c.Apply(next); err != nil {
+		return err
+	}
+	log.Printf("🔄 Settings reloaded from %s: rate %d req/sec, max URL length %d, max query params %d, log level %s",
+		file, next.RateLimit, next.MaxURLLength, next.MaxQueryParams, next.LogLevel)
+	return nil
+}
+
+// watchConfig reloads the config file when it changes on disk or on
+// SIGHUP, until ctx is cancelled
+func watchConfig(ctx context.Context, file string, explicit map[string]bool, c *crawler.Crawler) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	modified := func() time.Time {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+	last := modified()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-ticker.C:
+			if m := modified(); m.IsZero() || m.Equal(last) {
+				continue
+			}
+		}
+		last = modified()
+		if err := reloadConfig(file, explicit, c); err != nil {
+			log.Printf("Error reloading config, keeping the current settings: %v", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"gocrawler/events"
+	"gocrawler/storage"
+)
+
+// maxAssetBytes is how much of an asset body is read before closing
+const maxAssetBytes = 1 << 20
+
+// verifyAssets fetches the favicons and manifest a page declares, each
+// URL once per run, and publishes whether they resolve
+func (r *run) verifyAssets(ctx context.Context, page *storage.Page) {
+	for _, icon := range page.Icons {
+		r.checkAsset(ctx, icon, storage.AssetFavicon)
+	}
+	if page.Manifest != "" {
+		r.checkAsset(ctx, page.Manifest, storage.AssetManifest)
+	}
+}
+
+// claimResource reports whether a page resource (asset, script) wasn't
+// fetched yet in this run, marking it as fetched
+func (r *run) claimResource(resourceURL string) bool {
+	r.assetsMu.Lock()
+	defer r.assetsMu.Unlock()
+
+	if r.assets[resourceURL] {
+		return false
+	}
+	r.assets[resourceURL] = true
+	return true
+}
+
+// checkAsset fetches one asset unless another worker already did,
+// og:images also have their dimensions checked
+func (r *run) checkAsset(ctx context.Context, assetURL, kind string) {
+	if !r.claimResource(assetURL) {
+		return
+	}
+
+	r.rateLimiter.Wait(ctx)
+	asset := &storage.Asset{URL: assetURL, Kind: kind}
+	resp, err := r.fetch(ctx, assetURL, "", nil)
+	if err != nil {
+		asset.Error = err.Error()
+	} else {
+		asset.StatusCode = resp.StatusCode
+		asset.OK = resp.StatusCode == http.StatusOK
+		if asset.OK && kind == storage.AssetOGImage {
+			r.inspectImage(asset, io.LimitReader(resp.Body, maxAssetBytes))
+		}
+		drainClose(resp.Body)
+	}
+	if !asset.OK {
+		r.logf(LogWarn, "⚠️  Broken %s %s (status %d) %s", kind, assetURL, asset.StatusCode, asset.Error)
+	}
+	r.bus.Publish(events.Event{Type: events.AssetChecked, URL: assetURL, Asset: asset})
+}
@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials authenticate every request to one host
+type Credentials struct {
+	Host     string // hostname, or host:port
+	Username string // preemptive basic auth
+	Password string
+	Token    string  // static bearer token
+	OAuth2   *OAuth2 // bearer token from a client-credentials flow
+}
+
+// hostPrefix matches the host of "host=secret" credentials. Requiring a
+// dot, port or localhost keeps "user=x:pass" and padded tokens intact.
+var hostPrefix = regexp.MustCompile(`^(?:localhost|[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)(?::\d+)?$|^[A-Za-z0-9-]+:\d+$`)
+
+// ParseCredentials parses "[host=]secret" flag values, secret being
+// "user:pass" for basic auth or a bearer token. Without a host the
+// credentials apply to defaultHost.
+func ParseCredentials(spec, defaultHost string, bearer bool) (Credentials, error) {
+	creds := Credentials{Host: defaultHost}
+	if host, secret, ok := strings.Cut(spec, "="); ok && hostPrefix.MatchString(host) {
+		creds.Host, spec = host, secret
+	}
+	if bearer {
+		creds.Token = spec
+		return creds, nil
+	}
+
+	user, pass, ok := strings.Cut(spec, ":")
+	if !ok {
+		return creds, fmt.Errorf("invalid basic auth %q (want [host=]user:pass)", spec)
+	}
+	creds.Username, creds.Password = user, pass
+	return creds, nil
+}
+
+// OAuth2 fetches and caches tokens with the client-credentials grant,
+// refreshing them shortly before they expire (thread-safe)
+type OAuth2 struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	client *http.Client
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// accessToken returns a valid access token, requesting a new one if needed
+func (o *OAuth2) accessToken(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != "" && (o.expiry.IsZero() || time.Until(o.expiry) > 30*time.Second) {
+		return o.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(o.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+
+	if o.client == nil {
+		o.client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oauth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oauth2 token: status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("oauth2 token: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("oauth2 token: no access_token in response")
+	}
+
+	o.token = body.AccessToken
+	o.expiry = time.Time{}
+	if body.ExpiresIn > 0 {
+		o.expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return o.token, nil
+}
+
+// useTransport fetches tokens through the crawler's transport, so token
+// endpoints get the same CA bundle, client certificates, -resolve
+// overrides and proxies as pages
+func (o *OAuth2) useTransport(transport http.RoundTripper) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// invalidate drops a token the server rejected
+func (o *OAuth2) invalidate() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.token = ""
+}
+
+// authTransport adds credentials to requests for configured hosts.
+// It runs for every redirect hop, so credentials never follow a
+// redirect to another host.
+type authTransport struct {
+	base  http.RoundTripper
+	hosts map[string]Credentials
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds, ok := lookupHost(t.hosts, req)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	switch {
+	case creds.OAuth2 != nil:
+		token, err := creds.OAuth2.accessToken(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case creds.Token != "":
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	case creds.Username != "":
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && creds.OAuth2 != nil {
+		creds.OAuth2.invalidate()
+	}
+	return resp, err
+}
@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// dialFunc is the signature of http.Transport.DialContext
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// bandwidth counts the bytes received per host on the crawler's
+// connections, TLS and headers included (thread-safe). Requests sent
+// through a proxy are counted under the proxy.
+type bandwidth struct {
+	hosts map[string]*atomic.Int64
+	mu    sync.Mutex
+}
+
+// counter returns the byte counter of the host dialed at addr. Default
+// ports are dropped so hosts match those of page URLs.
+func (b *bandwidth) counter(addr string) *atomic.Int64 {
+	host := strings.TrimSuffix(strings.TrimSuffix(addr, ":443"), ":80")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.hosts == nil {
+		b.hosts = make(map[string]*atomic.Int64)
+	}
+	n := b.hosts[host]
+	if n == nil {
+		n = new(atomic.Int64)
+		b.hosts[host] = n
+	}
+	return n
+}
+
+// snapshot returns the bytes received per host so far
+func (b *bandwidth) snapshot() map[string]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bytes := make(map[string]int64, len(b.hosts))
+	for host, n := range b.hosts {
+		bytes[host] = n.Load()
+	}
+	return bytes
+}
+
+// dialer wraps dial so every connection counts what it reads
+func (b *bandwidth) dialer(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &meteredConn{Conn: conn, n: b.counter(addr)}, nil
+	}
+}
+
+// meteredConn adds the bytes read from a connection to a host counter
+type meteredConn struct {
+	net.Conn
+	n *atomic.Int64
+}
+
+func (c *meteredConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// downloaded returns the bytes received per host during this run
+func (r *run) downloaded() map[string]int64 {
+	bytes := r.bandwidth.snapshot()
+	for host, n := range bytes {
+		bytes[host] = n - r.bandwidthBase[host]
+	}
+	return bytes
+}
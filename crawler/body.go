@@ -0,0 +1,72 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// maxDrain is how much of an unread body is discarded to keep its
+// connection alive, larger leftovers are cheaper to drop with it
+const maxDrain = 64 << 10
+
+// maxStoredBody is how much of a page body -store-bodies keeps
+const maxStoredBody = 1 << 20
+
+// countingReader counts the bytes read from a response body
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// drainClose reads what is left of a response body and closes it. The
+// transport only reuses connections whose body was read to the end.
+func drainClose(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrain))
+	body.Close()
+}
+
+// ConnStats counts the connections requests were sent on
+type ConnStats struct {
+	Requests int64 `json:"requests"`
+	Reused   int64 `json:"reused"` // sent on an idle keep-alive connection
+}
+
+// ReuseRate is the share of requests sent on a reused connection
+func (s ConnStats) ReuseRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Reused) / float64(s.Requests)
+}
+
+// connStats is the thread-safe counter behind ConnStats
+type connStats struct {
+	requests atomic.Int64
+	reused   atomic.Int64
+}
+
+// trace returns ctx with a client trace counting the connection of
+// every request sent with it, redirects included
+func (s *connStats) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			s.requests.Add(1)
+			if info.Reused {
+				s.reused.Add(1)
+			}
+		},
+	})
+}
+
+// ConnStats reports connection reuse since the crawler was created
+func (c *Crawler) ConnStats() ConnStats {
+	return ConnStats{Requests: c.conns.requests.Load(), Reused: c.conns.reused.Load()}
+}
@@ -0,0 +1,155 @@
+package crawler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"gocrawler/events"
+)
+
+// DefaultBreakerCooldown is how long a host is left alone after its
+// circuit breaker trips
+const DefaultBreakerCooldown = 30 * time.Second
+
+// hostBreaker is the circuit breaker of one host. It is closed while
+// the host answers, open during the cooldown after too many consecutive
+// failures, and half-open once the cooldown ends: the next result
+// either closes it or trips it again.
+type hostBreaker struct {
+	failures  int       // consecutive
+	openUntil time.Time // zero unless open
+	halfOpen  bool
+	parked    []Job // held back while open
+}
+
+// breakers tracks the circuit breakers of a run's hosts (thread-safe)
+type breakers struct {
+	threshold int // consecutive failures tripping a breaker, 0 disables
+	cooldown  time.Duration
+	hosts     map[string]*hostBreaker
+	parked    int // jobs held back across hosts
+	mu        sync.Mutex
+}
+
+func newBreakers(threshold int, cooldown time.Duration) *breakers {
+	return &breakers{threshold: threshold, cooldown: cooldown, hosts: make(map[string]*hostBreaker)}
+}
+
+// breakerHost returns the host a job's breaker is keyed by
+func breakerHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Host
+	}
+	return rawURL
+}
+
+// hostFailed reports whether a fetch outcome counts against the host:
+// it could not be reached or answered with a server error
+func hostFailed(resp *http.Response, err error) bool {
+	if err != nil {
+		// redirect errors come with the response that caused them
+		return resp == nil
+	}
+	return resp.StatusCode >= 500
+}
+
+// admit reports whether job may be fetched now. Jobs of a host whose
+// breaker is open are parked until the cooldown ends, before they take
+// a rate limit token.
+func (r *run) admit(job Job) bool {
+	b := r.breakers
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+
+	host := breakerHost(job.URL)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h := b.hosts[host]
+	if h == nil || !time.Now().Before(h.openUntil) {
+		return true
+	}
+	h.parked = append(h.parked, job)
+	b.parked++
+	r.tracef("circuit breaker of %s is open, parked until %s", host, h.openUntil.Format(time.TimeOnly))
+	return false
+}
+
+// reportHost feeds the outcome of a fetch to its host's breaker,
+// tripping it after threshold consecutive failures or any failure
+// while half-open
+func (r *run) reportHost(ctx context.Context, rawURL string, failed bool) {
+	b := r.breakers
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	host := breakerHost(rawURL)
+	b.mu.Lock()
+	h := b.hosts[host]
+	if h == nil {
+		h = &hostBreaker{}
+		b.hosts[host] = h
+	}
+	now := time.Now()
+	if now.Before(h.openUntil) {
+		// sent before the breaker tripped
+		b.mu.Unlock()
+		return
+	}
+	if !failed {
+		h.failures, h.halfOpen = 0, false
+		b.mu.Unlock()
+		return
+	}
+	h.failures++
+	if !h.halfOpen && h.failures < b.threshold {
+		b.mu.Unlock()
+		return
+	}
+	failures := h.failures
+	h.halfOpen = false
+	h.openUntil = now.Add(b.cooldown)
+	b.mu.Unlock()
+
+	log.Printf("🔌 Circuit breaker tripped for %s after %d consecutive failures, pausing it for %s", host, failures, b.cooldown)
+	r.bus.Publish(events.Event{Type: events.BreakerTripped, URL: host})
+	time.AfterFunc(b.cooldown, func() { r.releaseHost(ctx, host) })
+}
+
+// releaseHost half-opens a host's breaker once its cooldown ended and
+// queues its parked jobs again
+func (r *run) releaseHost(ctx context.Context, host string) {
+	b := r.breakers
+	b.mu.Lock()
+	h := b.hosts[host]
+	h.halfOpen = true
+	parked := h.parked
+	h.parked = nil
+	b.mu.Unlock()
+
+	for _, job := range parked {
+		if !r.push(ctx, job) {
+			break
+		}
+	}
+
+	// counted until queued, so the crawl cannot look finished meanwhile
+	b.mu.Lock()
+	b.parked -= len(parked)
+	b.mu.Unlock()
+}
+
+// parkedJobs counts the jobs held back by open breakers
+func (r *run) parkedJobs() int {
+	if r.breakers == nil {
+		return 0
+	}
+	r.breakers.mu.Lock()
+	defer r.breakers.mu.Unlock()
+	return r.breakers.parked
+}
@@ -0,0 +1,26 @@
+package crawler
+
+import (
+	"net/http"
+
+	"gocrawler/events"
+)
+
+// recordCertificate publishes the DNS names of a host's TLS certificate
+// the first time the host answers over HTTPS
+func (r *run) recordCertificate(resp *http.Response) {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return
+	}
+
+	host := resp.Request.URL.Hostname()
+	r.assetsMu.Lock()
+	seen := r.certHosts[host]
+	r.certHosts[host] = true
+	r.assetsMu.Unlock()
+	if seen {
+		return
+	}
+
+	r.bus.Publish(events.Event{Type: events.CertificateSeen, URL: host, Names: resp.TLS.PeerCertificates[0].DNSNames})
+}
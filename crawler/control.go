@@ -0,0 +1,18 @@
+package crawler
+
+// Limiter returns the rate limiter of the running crawl, so it can be
+// paused or retuned while crawling, or nil between crawls
+func (c *Crawler) Limiter() *RateLimiter {
+	c.limiterMu.Lock()
+	defer c.limiterMu.Unlock()
+	return c.limiter
+}
+
+// setActive publishes the rate limiter and worker table of the running
+// crawl, nil for both once it ends
+func (c *Crawler) setActive(rl *RateLimiter, workers *workerTable) {
+	c.limiterMu.Lock()
+	defer c.limiterMu.Unlock()
+	c.limiter = rl
+	c.workerTable = workers
+}
@@ -1,70 +1,346 @@
 package crawler
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"gocrawler/events"
 	"gocrawler/parser"
 	"gocrawler/storage"
+	"gocrawler/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Crawler represents a concurrent web crawler
+// Config holds the crawler settings
+type Config struct {
+	Workers          int
+	RateLimit        int           // requests per second
+	Delay            time.Duration // pause of a worker before each request, randomized ±50% (0 disables)
+	MaxDepth         int
+	Scope            Scope
+	Params           ParamPolicies            // query parameter handling during normalization
+	MaxPagination    int                      // pages followed per rel=next series beyond the depth limit
+	CrawlAlternates  bool                     // also fetch AMP/mobile alternates for parity checks
+	MaxPages         int                      // stop claiming new URLs after this many (0 = unlimited)
+	Previous         map[string]*storage.Page // pages of an earlier run, re-requested with If-Modified-Since
+	Grep             *regexp.Regexp           // search page bodies for this pattern (nil disables)
+	CheckAssets      bool                     // verify that favicons and manifests resolve
+	CheckOGImages    bool                     // verify og:images resolve and meet the minimum size
+	OGMinWidth       int
+	OGMinHeight      int
+	CrawlIframes     bool              // also crawl in-scope iframe pages
+	FragmentRoutes   []string          // fragment prefixes kept as distinct pages, e.g. "!/"
+	ScanJS           bool              // report URLs found in inline and same-origin scripts
+	Recon            bool              // collect URLs hidden in HTML comments
+	Credentials      []Credentials     // authentication per host
+	Headers          []HeaderRule      // per-URL-pattern header overrides
+	Rules            []Rule            // per-URL-pattern page assertions
+	Parse            parser.Options    // boilerplate left out of page text
+	MaxURLLength     int               // skip longer URLs (0 disables)
+	MaxQueryParams   int               // skip URLs with more query parameters (0 disables)
+	UpgradeHTTPS     bool              // crawl https versions of http links when they resolve
+	FollowRefresh    bool              // crawl meta refresh targets like redirects
+	Proxies          []*url.URL        // proxy pool, rotated per request
+	IgnoreRobots     bool              // follow links of nofollow pages anyway
+	ClientCerts      []ClientCert      // mTLS client certificates
+	RootCAs          *x509.CertPool    // trusted CAs, nil for the system pool
+	InsecureTLS      bool              // skip certificate verification (staging only)
+	KeepText         bool              // keep the visible text of pages for snapshots
+	StoreBodies      bool              // keep the raw HTML of pages for the dashboard preview
+	Articles         bool              // extract the main content of pages
+	Keywords         bool              // count keywords and entities of pages
+	UserAgent        string            // User-Agent of every request, empty for Go's default
+	LogLevel         LogLevel          // per-page logging, empty for info
+	FrontierSize     int               // jobs queued in memory before spilling to disk, 0 for DefaultFrontierSize
+	Shards           int               // frontier partitions by host, each served by its own workers (0 or 1 disables)
+	BreakerFailures  int               // consecutive failures pausing a host (0 disables)
+	BreakerCooldown  time.Duration     // how long a tripped host is paused, 0 for DefaultBreakerCooldown
+	SpillDir         string            // directory of the frontier spill file, empty for the system temp dir
+	VCR              VCRMode           // record responses to VCRDir or replay them from it
+	Languages        []string          // languages crawled, by path segment and declared language (empty for all)
+	ThrottleP95      time.Duration     // p95 response time above which a host is slowed down (0 disables)
+	CheckExternal    bool              // fetch out-of-scope link targets and report the broken ones
+	Wayback          bool              // look broken external targets up in the Wayback Machine
+	ThrottleMaxDelay time.Duration     // longest pause between requests to a slow host, 0 for DefaultThrottleMaxDelay
+	Resolve          map[string]string // host:port -> addr:port to connect to instead (-resolve)
+	VCRDir           string
+}
+
+// Crawler represents a concurrent web crawler. It only holds settings
+// and the shared HTTP client, every Crawl call gets its own state so one
+// Crawler can run independent crawls concurrently.
 type Crawler struct {
-	workers     int
-	maxDepth    int
-	rateLimiter *RateLimiter
-	results     *storage.Results
-	visited     map[string]bool
-	visitedMu   sync.RWMutex
-	client      *http.Client
-	startTime   time.Time
+	workers            int
+	delay              time.Duration
+	settings           Settings // changeable during a crawl, see Apply
+	settingsMu         sync.RWMutex
+	maxDepth           int
+	scope              Scope
+	params             ParamPolicies
+	maxPagination      int
+	crawlAlternates    bool
+	maxPages           int
+	previous           map[string]*storage.Page
+	grep               *regexp.Regexp
+	checkAssets        bool
+	checkOGImages      bool
+	ogMinWidth         int
+	ogMinHeight        int
+	crawlIframes       bool
+	fragmentRoutes     []string
+	scanJS             bool
+	recon              bool
+	insecureTLS        bool
+	headers            []HeaderRule
+	rules              []Rule
+	parse              parser.Options
+	upgradeHTTPS       bool
+	followRefresh      bool
+	proxies            *ProxyPool
+	ignoreRobots       bool
+	keepText           bool
+	storeBodies        bool
+	articles           bool
+	keywords           bool
+	userAgent          string
+	frontierSize       int
+	shards             int
+	breakerThreshold   int
+	breakerCooldown    time.Duration
+	spillDir           string
+	languages          map[string]bool // primary subtags, nil for all
+	throttleP95        time.Duration
+	checkExternalLinks bool
+	wayback            bool
+	throttleMaxDelay   time.Duration
+	conns              connStats
+	bandwidth          *bandwidth   // bytes received per host
+	limiter            *RateLimiter // of the running crawl, see Limiter
+	workerTable        *workerTable // of the running crawl, see Workers
+	limiterMu          sync.Mutex   // guards limiter and workerTable
+	client             *http.Client
+}
+
+// run is the state of a single Crawl call
+type run struct {
+	*Crawler
+	scope         Scope // bound to this run's start URL
+	bus           *events.Bus
+	rateLimiter   *RateLimiter
+	workerTable   *workerTable
+	shards        []*shard         // the frontier, split by host
+	ring          *hashRing        // assigns hosts to shards
+	breakers      *breakers        // per-host circuit breakers
+	throttles     *throttles       // per-host adaptive politeness
+	bandwidthBase map[string]int64 // bytes received per host before this run
+	frontierMu    sync.RWMutex     // held to send, so closing cannot race a send
+	closed        bool             // frontiers closed, late enqueues are dropped
+	visited       map[string]bool
+	queued        map[string]bool // in the frontier, spilled or parked, not claimed yet
+	visitedMu     sync.RWMutex    // guards visited and queued
+	assets        map[string]bool // asset and script URLs already fetched
+	assetsMu      sync.Mutex
+	contents      map[string]string // body hash → first URL crawled with it
+	contentsMu    sync.Mutex
+	certHosts     map[string]bool // hosts whose TLS certificate was published
+	upgrades      map[string]bool // https URLs probed by -upgrade-https, true if they resolve
+	inFlight      atomic.Int64    // jobs taken off a frontier whose links aren't queued yet
+	startTime     time.Time
+	trace         func(format string, args ...any) // set by Replay, explains every decision
+	seedsOnly     bool                             // set by Refetch, links are not queued
+}
+
+// newRun returns the run state shared by Crawl and Replay, with every
+// map the pipeline records into allocated. Crawl adds the frontier,
+// workers and per-host state on top.
+func (c *Crawler) newRun(bus *events.Bus) *run {
+	return &run{
+		Crawler:     c,
+		scope:       c.scope,
+		bus:         bus,
+		rateLimiter: NewRateLimiter(c.Settings().RateLimit),
+		visited:     make(map[string]bool),
+		queued:      make(map[string]bool),
+		assets:      make(map[string]bool),
+		contents:    make(map[string]string),
+		certHosts:   make(map[string]bool),
+		upgrades:    make(map[string]bool),
+		startTime:   time.Now(),
+	}
 }
 
 // Job represents a crawl job
 type Job struct {
-	URL   string
-	Depth int
+	URL         string
+	Depth       int
+	Series      string // pagination series this job was reached through
+	SeriesPage  int
+	AlternateOf string // canonical page when this job fetches an AMP/mobile version
+	Parent      string // page the URL was discovered on, sent as Referer
 }
 
 // New creates a new Crawler instance
-func New(workers, rateLimit, maxDepth int, results *storage.Results) *Crawler {
+func New(cfg Config) *Crawler {
+	var proxies *ProxyPool
+	meter := &bandwidth{}
+	if len(cfg.Proxies) > 0 {
+		proxies = newProxyPool(cfg.Proxies)
+	}
+
+	logLevel := cfg.LogLevel
+	if logLevel == "" {
+		logLevel = LogInfo
+	}
+	frontierSize := cfg.FrontierSize
+	if frontierSize <= 0 {
+		frontierSize = DefaultFrontierSize
+	}
+	breakerCooldown := cfg.BreakerCooldown
+	if breakerCooldown <= 0 {
+		breakerCooldown = DefaultBreakerCooldown
+	}
+	throttleMaxDelay := cfg.ThrottleMaxDelay
+	if throttleMaxDelay <= 0 {
+		throttleMaxDelay = DefaultThrottleMaxDelay
+	}
+	// every shard needs a worker
+	shards := min(max(cfg.Shards, 1), max(cfg.Workers, 1))
+	// robots directives for our User-Agent apply like those for every crawler
+	parse := cfg.Parse
+	parse.RobotsAgent = productToken(cfg.UserAgent)
+
 	return &Crawler{
-		workers:     workers,
-		maxDepth:    maxDepth,
-		rateLimiter: NewRateLimiter(rateLimit),
-		results:     results,
-		visited:     make(map[string]bool),
+		workers: cfg.Workers,
+		delay:   cfg.Delay,
+		settings: Settings{
+			RateLimit:      cfg.RateLimit,
+			MaxURLLength:   cfg.MaxURLLength,
+			MaxQueryParams: cfg.MaxQueryParams,
+			LogLevel:       logLevel,
+		},
+		maxDepth:           cfg.MaxDepth,
+		scope:              cfg.Scope,
+		params:             cfg.Params,
+		maxPagination:      cfg.MaxPagination,
+		crawlAlternates:    cfg.CrawlAlternates,
+		maxPages:           cfg.MaxPages,
+		previous:           cfg.Previous,
+		grep:               cfg.Grep,
+		checkAssets:        cfg.CheckAssets,
+		checkOGImages:      cfg.CheckOGImages,
+		ogMinWidth:         cfg.OGMinWidth,
+		ogMinHeight:        cfg.OGMinHeight,
+		crawlIframes:       cfg.CrawlIframes,
+		fragmentRoutes:     cfg.FragmentRoutes,
+		scanJS:             cfg.ScanJS,
+		recon:              cfg.Recon,
+		insecureTLS:        cfg.InsecureTLS,
+		headers:            cfg.Headers,
+		rules:              cfg.Rules,
+		parse:              parse,
+		upgradeHTTPS:       cfg.UpgradeHTTPS,
+		followRefresh:      cfg.FollowRefresh,
+		proxies:            proxies,
+		ignoreRobots:       cfg.IgnoreRobots,
+		keepText:           cfg.KeepText,
+		storeBodies:        cfg.StoreBodies,
+		articles:           cfg.Articles,
+		keywords:           cfg.Keywords,
+		userAgent:          cfg.UserAgent,
+		frontierSize:       frontierSize,
+		shards:             shards,
+		breakerThreshold:   cfg.BreakerFailures,
+		breakerCooldown:    breakerCooldown,
+		spillDir:           cfg.SpillDir,
+		bandwidth:          meter,
+		languages:          languageSet(cfg.Languages),
+		throttleP95:        cfg.ThrottleP95,
+		checkExternalLinks: cfg.CheckExternal || cfg.Wayback,
+		wayback:            cfg.Wayback,
+		throttleMaxDelay:   throttleMaxDelay,
 		client: &http.Client{
-			Timeout: 10 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConnsPerHost: workers,
-			},
+			Timeout:       10 * time.Second,
+			Transport:     newTransport(cfg, proxies, meter),
+			CheckRedirect: checkRedirect,
 		},
 	}
 }
 
-// Crawl starts the crawling process
-func (c *Crawler) Crawl(ctx context.Context, startURL string) {
-	c.startTime = time.Now()
+// Crawl starts the crawling process, publishing its events to bus
+func (c *Crawler) Crawl(ctx context.Context, startURL string, bus *events.Bus) {
+	c.crawl(ctx, startURL, []Job{{URL: startURL, Depth: 0}}, false, bus)
+}
+
+// Refetch fetches jobs again without following their links, for pages
+// of a crawl of startURL that need a fresh copy. Their events are
+// published to bus like a crawl's.
+func (c *Crawler) Refetch(ctx context.Context, startURL string, jobs []Job, bus *events.Bus) {
+	c.crawl(ctx, startURL, jobs, true, bus)
+}
+
+// crawl runs workers over seeds until the frontier is drained, queueing
+// discovered links unless seedsOnly is set
+func (c *Crawler) crawl(ctx context.Context, startURL string, seeds []Job, seedsOnly bool, bus *events.Bus) {
+	r := c.newRun(bus)
+	r.workerTable = newWorkerTable(c.workers, c.shards)
+	r.shards = newShards(c.shards, c.frontierSize, c.spillDir)
+	r.ring = newHashRing(c.shards)
+	r.breakers = newBreakers(c.breakerThreshold, c.breakerCooldown)
+	r.throttles = newThrottles(c.throttleP95, c.throttleMaxDelay)
+	r.bandwidthBase = c.bandwidth.snapshot()
+	r.seedsOnly = seedsOnly
+	defer r.rateLimiter.Stop()
+	for _, s := range r.shards {
+		defer s.spill.close()
+	}
+	c.setActive(r.rateLimiter, r.workerTable)
+	defer c.setActive(nil, nil)
+	startURL = asciiURL(startURL)
+	r.scope.init(startURL)
 
-	// Create job queue (buffered channel)
-	jobs := make(chan Job, 100)
 	jobsDone := make(chan bool)
 
 	// Create worker pool using goroutines
 	var wg sync.WaitGroup
 	for i := 0; i < c.workers; i++ {
 		wg.Add(1)
-		go c.worker(ctx, i, jobs, &wg)
+		go r.worker(ctx, i, &wg)
 	}
 
-	// Send initial job
-	jobs <- Job{URL: startURL, Depth: 0}
+	// Send initial jobs
+	for _, job := range seeds {
+		job.URL = asciiURL(job.URL)
+		if r.markQueued(job.URL) {
+			r.push(ctx, job)
+		}
+	}
+
+	// Reload spilled jobs as the frontiers drain
+	stopFeed := make(chan struct{})
+	var feeders sync.WaitGroup
+	for _, s := range r.shards {
+		feeders.Add(1)
+		go func() {
+			defer feeders.Done()
+			r.feedFrontier(ctx, s, stopFeed)
+		}()
+	}
 
 	// Monitor goroutine to close jobs channel when done
 	go func() {
@@ -80,12 +356,25 @@ func (c *Crawler) Crawl(ctx context.Context, startURL string) {
 				jobsDone <- true
 				return
 			case <-ticker.C:
-				c.visitedMu.RLock()
-				currentVisited := len(c.visited)
-				c.visitedMu.RUnlock()
+				r.visitedMu.RLock()
+				currentVisited := len(r.visited)
+				r.visitedMu.RUnlock()
+				queued, waiting := r.queuedJobs()
+				r.bus.Publish(events.Event{
+					Type:       events.Progress,
+					Queued:     queued,
+					Visited:    currentVisited,
+					Elapsed:    time.Since(r.startTime),
+					Downloaded: r.downloaded(),
+				})
 
-				// If no new pages were visited, increment stable counter
-				if currentVisited == prevVisited {
+				// If no new pages were visited, increment stable counter.
+				// A paused crawl is idle but not done, nor is one with
+				// jobs queued in memory or on disk, held back by a circuit
+				// breaker or still being fetched, whose links would be lost.
+				if r.rateLimiter.Paused() || waiting > 0 {
+					stableCount = 0
+				} else if currentVisited == prevVisited {
 					stableCount++
 				} else {
 					stableCount = 0
@@ -103,116 +392,471 @@ func (c *Crawler) Crawl(ctx context.Context, startURL string) {
 
 	// Wait for completion signal then close channel
 	<-jobsDone
-	close(jobs)
+	close(stopFeed)
+	feeders.Wait()
+	r.frontierMu.Lock()
+	r.closed = true
+	for _, s := range r.shards {
+		close(s.frontier)
+	}
+	r.frontierMu.Unlock()
 
 	wg.Wait()
-	c.results.SetDuration(time.Since(c.startTime))
+	spilled := 0
+	for _, s := range r.shards {
+		spilled += s.spill.total()
+	}
+	if spilled > 0 {
+		log.Printf("💽 %d URLs were spilled to disk while the frontier was full", spilled)
+	}
+	r.bus.Publish(events.Event{Type: events.CrawlFinished, URL: startURL, Elapsed: time.Since(r.startTime), Downloaded: r.downloaded()})
 	log.Println("🏁 All workers finished")
 }
 
 // worker processes jobs from the queue
-func (c *Crawler) worker(ctx context.Context, id int, jobs chan Job, wg *sync.WaitGroup) {
+func (r *run) worker(ctx context.Context, id int, wg *sync.WaitGroup) {
 	defer wg.Done()
+	frontier := r.workerShard(id).frontier
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case job, ok := <-jobs:
+		case job, ok := <-frontier:
 			if !ok {
 				return
 			}
 
-			// Check if already visited
-			if c.isVisited(job.URL) {
+			// Hold back jobs of hosts whose circuit breaker is open,
+			// skip visited URLs and anything beyond the page budget
+			r.inFlight.Add(1)
+			if !r.admit(job) || !r.claim(job.URL) {
+				r.inFlight.Add(-1)
 				continue
 			}
-			c.markVisited(job.URL)
 
 			// Rate limiting
-			c.rateLimiter.Wait(ctx)
-
-			// Fetch and parse
-			start := time.Now()
-			resp, err := c.client.Get(job.URL)
-			duration := time.Since(start)
+			r.rateLimiter.Wait(ctx)
+			r.throttleWait(ctx, job.URL)
+			r.pause(ctx)
 
-			if err != nil {
-				c.results.AddPage(job.URL, "", "", nil, duration, err)
-				log.Printf("❌ [Worker %d] Error fetching %s: %v", id, job.URL, err)
-				continue
+			r.workerTable.busy(id, job.URL)
+			accepting := r.process(ctx, id, job)
+			r.inFlight.Add(-1)
+			r.workerTable.idle(id)
+			if !accepting {
+				return
 			}
-			defer resp.Body.Close()
+		}
+	}
+}
 
-			if resp.StatusCode != http.StatusOK {
-				c.results.AddPage(job.URL, "", "", nil, duration, fmt.Errorf("status %d", resp.StatusCode))
-				log.Printf("⚠️  [Worker %d] Non-200 status for %s: %d", id, job.URL, resp.StatusCode)
-				continue
+// process fetches and parses one job, records the page and queues its
+// links. It returns false once the crawl stops accepting jobs.
+func (r *run) process(ctx context.Context, id int, job Job) bool {
+	// Fetch and parse
+	reqCtx, span := tracing.Tracer().Start(ctx, "fetch", trace.WithAttributes(
+		tracing.URL(job.URL), attribute.Int("crawl.depth", job.Depth)))
+	prev := r.previous[job.URL]
+	if prev != nil {
+		r.tracef("previous run has this page, sending If-Modified-Since %s", prev.CrawledAt.UTC().Format(http.TimeFormat))
+	}
+	start := time.Now()
+	resp, err := r.fetch(reqCtx, job.URL, job.Parent, prev)
+	duration := time.Since(start)
+	if ctx.Err() == nil {
+		r.reportHost(ctx, job.URL, hostFailed(resp, err))
+		r.observeLatency(job.URL, duration)
+	}
+	if resp != nil {
+		r.traceRequest(resp.Request)
+	}
+
+	page := &storage.Page{URL: job.URL, Depth: job.Depth, ResponseTime: duration, Parent: job.Parent}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "fetch failed")
+		span.End()
+		page.ErrorType = classifyError(err)
+		if resp != nil {
+			// redirect loops return the last redirect response
+			page.Redirects = redirectChain(resp)
+		}
+		r.tracef("fetch failed after %dms: %v, recorded as %s", duration.Milliseconds(), err, page.ErrorType)
+		r.checkRules(page, nil, false)
+		r.record(page, err)
+		r.logf(LogError, "❌ [Worker %d] Error fetching %s: %v", id, job.URL, err)
+		return true
+	}
+	page.StatusCode = resp.StatusCode
+	page.Headers = resp.Header
+	page.Redirects = redirectChain(resp)
+	r.recordCertificate(resp)
+	page.TLSUnverified = r.insecureTLS && resp.TLS != nil
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	span.End()
+	r.traceResponse(resp, page.Redirects, duration)
+
+	notModified := resp.StatusCode == http.StatusNotModified && prev != nil
+	if resp.StatusCode != http.StatusOK && !notModified {
+		page.ErrorType = statusErrorType(resp.StatusCode)
+		r.tracef("status %d is not 200, recorded as failed (%s) without parsing", resp.StatusCode, page.ErrorType)
+		drainClose(resp.Body)
+		r.checkRules(page, resp.Header, false)
+		r.record(page, fmt.Errorf("status %d", resp.StatusCode))
+		r.logf(LogWarn, "⚠️  [Worker %d] Non-200 status for %s: %d", id, job.URL, resp.StatusCode)
+		return true
+	}
+
+	// Parse HTML, unchanged pages reuse what the previous run found
+	var pageInfo *parser.PageInfo
+	if notModified {
+		pageInfo = unchanged(prev)
+		page.Size = prev.Size
+		page.ContentHash = prev.ContentHash
+		page.NotModified = true
+		r.tracef("304 Not Modified, reusing title, links and directives of the previous run")
+	} else {
+		_, parseSpan := tracing.Tracer().Start(reqCtx, "parse", trace.WithAttributes(tracing.URL(job.URL)))
+		hash := sha256.New()
+		body := &countingReader{r: io.TeeReader(resp.Body, hash)}
+		var raw bytes.Buffer
+		if r.grep != nil || r.storeBodies {
+			body.r = io.TeeReader(body.r, &raw)
+		}
+		pageInfo, err = parser.ParseWith(body, job.URL, r.parse)
+		page.Size = body.n
+		if body.n > 0 {
+			page.ContentHash = hex.EncodeToString(hash.Sum(nil))
+		}
+		parseSpan.SetAttributes(attribute.Int64("crawl.body_bytes", body.n))
+		if err != nil {
+			parseSpan.RecordError(err)
+			parseSpan.SetStatus(codes.Error, "parse failed")
+		}
+		parseSpan.End()
+		if err != nil {
+			drainClose(resp.Body)
+			page.ErrorType = storage.ErrorParse
+			r.tracef("parse failed: %v", err)
+			r.checkRules(page, resp.Header, false)
+			r.record(page, err)
+			r.logf(LogError, "❌ [Worker %d] Error parsing %s: %v", id, job.URL, err)
+			return true
+		}
+		r.tracef("parsed %d bytes: title %q, %d links, %d headings, %d words of text", body.n, pageInfo.Title, len(pageInfo.Links), len(pageInfo.Headings), len(strings.Fields(pageInfo.Text)))
+		if r.grep != nil {
+			page.GrepMatches = grepSnippets(r.grep, raw.Bytes())
+			r.tracef("-grep %s: %d matches", r.grep, len(page.GrepMatches))
+		}
+		if r.storeBodies {
+			page.Body = string(raw.Bytes()[:min(raw.Len(), maxStoredBody)])
+		}
+	}
+	// Done with the body, release the connection before queueing links
+	drainClose(resp.Body)
+
+	// Relative links resolve against the final URL after redirects
+	baseURL, _ := url.Parse(job.URL)
+	if len(page.Redirects) > 0 {
+		baseURL = resp.Request.URL
+	}
+	source := baseURL.String()
+	r.tracef("links resolve against %s", source)
+
+	// Store results
+	page.Title = pageInfo.Title
+	page.Description = pageInfo.Description
+	if r.keepText {
+		page.Text = pageInfo.Text
+		page.Headings = pageInfo.Headings
+	}
+	if r.articles && pageInfo.Article != nil {
+		a := pageInfo.Article
+		page.Article = &storage.Article{Title: a.Title, Author: a.Author, Published: a.Published, Words: a.Words, Text: a.Text}
+	}
+	if r.keywords {
+		if notModified {
+			page.Keywords, page.Entities = prev.Keywords, prev.Entities
+		} else {
+			extractTerms(page, pageInfo)
+		}
+	}
+	page.Links = pageInfo.Links
+	page.Series, page.SeriesPage = r.seriesFor(job, pageInfo.Next != "" || pageInfo.Prev != "")
+	page.AlternateOf = job.AlternateOf
+	page.NoIndex, page.NoFollow = parseRobots(resp.Header.Values("X-Robots-Tag"), pageInfo.Robots, r.parse.RobotsAgent)
+	if pageInfo.AMP != "" {
+		page.AMPURL = r.resolveURL(baseURL, pageInfo.AMP)
+	}
+	if pageInfo.Mobile != "" {
+		page.MobileURL = r.resolveURL(baseURL, pageInfo.Mobile)
+	}
+	for _, icon := range pageInfo.Icons {
+		if resolved := r.resolveURL(baseURL, icon); resolved != "" {
+			page.Icons = append(page.Icons, resolved)
+		}
+	}
+	if pageInfo.Manifest != "" {
+		page.Manifest = r.resolveURL(baseURL, pageInfo.Manifest)
+	}
+	if pageInfo.OGImage != "" {
+		page.OGImage = r.resolveURL(baseURL, pageInfo.OGImage)
+	}
+	for _, a := range pageInfo.Anchors {
+		page.Anchors = append(page.Anchors, storage.Link{Href: a.Href, URL: r.resolveURL(baseURL, a.Href), Text: a.Text, Position: a.Position, Rel: a.Rel})
+	}
+	for _, e := range pageInfo.Embeds {
+		if resolved := r.resolveURL(baseURL, e.Src); resolved != "" {
+			page.Embeds = append(page.Embeds, storage.Embed{Kind: e.Kind, URL: resolved})
+		}
+	}
+	if r.recon {
+		page.CommentURLs = commentURLs(baseURL, pageInfo.Comments)
+	}
+	if pageInfo.Canonical != "" {
+		page.Canonical = r.resolveURL(baseURL, pageInfo.Canonical)
+	}
+	refresh := pageInfo.Refresh
+	if header := resp.Header.Get("Refresh"); header != "" && refresh == "" {
+		refresh = parser.RefreshURL(header)
+	}
+	if refresh != "" {
+		page.MetaRefresh = r.resolveURL(baseURL, refresh)
+	}
+	if page.ContentHash != "" {
+		if first := r.claimContent(page.ContentHash, job.URL); first != job.URL {
+			page.DuplicateOf = first
+		}
+	}
+	page.Language = pageInfo.Lang
+	if page.Language == "" {
+		page.Language = contentLanguage(resp.Header.Get("Content-Language"))
+	}
+	r.tracePage(page, pageInfo, resp.Header.Values("X-Robots-Tag"))
+	r.checkRules(page, resp.Header, true)
+	r.record(page, nil)
+	r.logf(LogInfo, "✅ [Worker %d] Crawled: %s (depth=%d, links=%d, %dms)",
+		id, job.URL, job.Depth, len(pageInfo.Links), duration.Milliseconds())
+
+	if r.checkAssets {
+		r.verifyAssets(ctx, page)
+	}
+	if r.checkOGImages && page.OGImage != "" {
+		r.checkAsset(ctx, page.OGImage, storage.AssetOGImage)
+	}
+	if r.scanJS {
+		r.scanScripts(ctx, baseURL, pageInfo.Inline, pageInfo.Scripts)
+	}
+
+	// Robots directives are still recorded when ignored
+	follow := !page.NoFollow || r.ignoreRobots
+	if !follow {
+		r.logf(LogInfo, "🚫 [Worker %d] nofollow, not following links of %s", id, job.URL)
+		r.tracef("nofollow, links are reported but not queued")
+	} else if page.NoFollow {
+		r.tracef("nofollow ignored (-ignore-robots), links are followed")
+	}
+	if follow && page.DuplicateOf != "" {
+		follow = false
+		r.logf(LogInfo, "👯 [Worker %d] %s has the same content as %s, not following its links", id, job.URL, page.DuplicateOf)
+		r.tracef("duplicate of %s, links are reported but not queued", page.DuplicateOf)
+	}
+	if follow && !r.wantLanguage(page.Language) {
+		follow = false
+		r.logf(LogInfo, "🌐 [Worker %d] lang=%s outside -languages, not following links of %s", id, page.Language, job.URL)
+		r.tracef("language %s not crawled, links are reported but not queued", page.Language)
+	}
+
+	// Follow rel=next within the pagination cap, without spending depth
+	if follow && pageInfo.Next != "" && page.SeriesPage < r.maxPagination {
+		if next := r.resolveURL(baseURL, pageInfo.Next); next != "" && r.shouldCrawl(next) {
+			if !r.enqueue(ctx, Job{URL: next, Depth: job.Depth, Series: page.Series, SeriesPage: page.SeriesPage + 1, Parent: source}) {
+				return false
 			}
+		}
+	}
 
-			// Parse HTML
-			pageInfo, err := parser.Parse(resp.Body, job.URL)
-			if err != nil {
-				c.results.AddPage(job.URL, "", "", nil, duration, err)
-				log.Printf("❌ [Worker %d] Error parsing %s: %v", id, job.URL, err)
-				continue
+	// Meta refreshes act as redirects, so they don't spend depth either
+	if r.followRefresh && page.MetaRefresh != "" && r.shouldCrawl(page.MetaRefresh) {
+		if !r.enqueue(ctx, Job{URL: page.MetaRefresh, Depth: job.Depth, Parent: source}) {
+			return false
+		}
+	}
+
+	// Alternates are fetched for parity checks even when off-scope (m. hosts)
+	if r.crawlAlternates && job.AlternateOf == "" {
+		for _, alt := range []string{page.AMPURL, page.MobileURL} {
+			if alt != "" && alt != job.URL {
+				if !r.enqueue(ctx, Job{URL: alt, Depth: job.Depth, AlternateOf: job.URL, Parent: source}) {
+					return false
+				}
 			}
+		}
+	}
 
-			// Store results
-			c.results.AddPage(job.URL, pageInfo.Title, pageInfo.Description, pageInfo.Links, duration, nil)
-			log.Printf("✅ [Worker %d] Crawled: %s (depth=%d, links=%d, %dms)",
-				id, job.URL, job.Depth, len(pageInfo.Links), duration.Milliseconds())
-
-			// Queue child URLs if depth allows
-			if job.Depth < c.maxDepth {
-				baseURL, _ := url.Parse(job.URL)
-				for _, link := range pageInfo.Links {
-					childURL := c.resolveURL(baseURL, link)
-					if childURL != "" && c.shouldCrawl(childURL, baseURL) {
-						select {
-						case jobs <- Job{URL: childURL, Depth: job.Depth + 1}:
-						case <-ctx.Done():
-							return
-						default:
-							// Queue full, skip this URL
-						}
-					}
+	// Same-site iframes are crawled like links
+	if r.crawlIframes && follow && job.Depth < r.maxDepth {
+		for _, e := range page.Embeds {
+			if e.Kind == "iframe" && r.shouldCrawl(e.URL) {
+				if !r.enqueue(ctx, Job{URL: e.URL, Depth: job.Depth + 1, Parent: source}) {
+					return false
 				}
 			}
 		}
 	}
+
+	// Queue child URLs if depth allows
+	for _, link := range pageInfo.Links {
+		childURL := r.resolveURL(baseURL, link)
+		if childURL == "" {
+			r.tracef("link %q: unparsable or rejected by a parameter policy", link)
+			continue
+		}
+		if !r.shouldCrawl(childURL) {
+			r.tracef("link %s: out of scope", childURL)
+			if r.checkExternalLinks {
+				r.checkExternal(ctx, childURL)
+			}
+			continue
+		}
+		if r.upgradeHTTPS {
+			childURL = r.upgradeScheme(ctx, childURL)
+		}
+		r.bus.Publish(events.Event{Type: events.URLDiscovered, URL: childURL, Depth: job.Depth + 1, Parent: source})
+		if follow && job.Depth < r.maxDepth {
+			if !r.enqueue(ctx, Job{URL: childURL, Depth: job.Depth + 1, Parent: source}) {
+				return false
+			}
+		} else if follow {
+			r.tracef("link %s: discovered, beyond the depth limit %d", childURL, r.maxDepth)
+		}
+	}
+	return true
+}
+
+// fetch issues a GET request, conditional when prev holds the page from
+// an earlier run. In-flight requests are not cancelled with the crawl,
+// ctx only carries the tracing span.
+func (c *Crawler) fetch(ctx context.Context, rawURL, referer string, prev *storage.Page) (*http.Response, error) {
+	ctx = c.conns.trace(context.WithoutCancel(ctx))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL(rawURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req, rawURL, referer)
+	if prev != nil && !prev.CrawledAt.IsZero() {
+		req.Header.Set("If-Modified-Since", prev.CrawledAt.UTC().Format(http.TimeFormat))
+	}
+	return c.client.Do(req)
+}
+
+// record publishes the outcome of a job
+func (r *run) record(page *storage.Page, err error) {
+	e := events.Event{Type: events.PageCrawled, URL: page.URL, Depth: page.Depth, Page: page}
+	if err != nil {
+		e.Type = events.PageFailed
+		e.Err = err
+	}
+	r.bus.Publish(e)
+}
+
+// enqueue offers a job to the queue without blocking, spilling it to
+// disk when the frontier is full. It returns false once the context is
+// cancelled or the crawl has finished.
+func (r *run) enqueue(ctx context.Context, job Job) bool {
+	if r.seedsOnly {
+		return true
+	}
+	if reason := r.skipReason(job.URL); reason != "" {
+		r.tracef("link %s: skipped (%s)", job.URL, reason)
+		r.bus.Publish(events.Event{Type: events.URLSkipped, URL: job.URL, Depth: job.Depth, Parent: job.Parent, Reason: reason})
+		return true
+	}
+	if r.trace != nil {
+		// replays only report what a crawl would queue
+		r.tracef("link %s: would be queued at depth %d", job.URL, job.Depth)
+		return true
+	}
+	if !r.markQueued(job.URL) {
+		// already crawled or waiting in the frontier
+		return true
+	}
+	return r.push(ctx, job)
+}
+
+// push adds a job to its shard's frontier, or to the shard's spill file
+// when the frontier is full, returning false once the context is
+// cancelled or the crawl has finished
+func (r *run) push(ctx context.Context, job Job) bool {
+	r.frontierMu.RLock()
+	defer r.frontierMu.RUnlock()
+	if r.closed {
+		return false
+	}
+	s := r.shardFor(job.URL)
+	if s.spill.len() == 0 {
+		select {
+		case s.frontier <- job:
+			return true
+		case <-ctx.Done():
+			return false
+		default:
+		}
+	}
+	// Queue full or jobs already waiting on disk, keep them in order
+	if err := s.spill.push(job); err != nil {
+		r.logf(LogError, "❌ Error spilling %s to disk, dropping it: %v", job.URL, err)
+	}
+	return true
 }
 
-// isVisited checks if URL was already visited (thread-safe)
-func (c *Crawler) isVisited(url string) bool {
-	c.visitedMu.RLock()
-	defer c.visitedMu.RUnlock()
-	return c.visited[url]
+// claim marks URL as visited unless it already was or the page budget
+// is spent, reporting whether the caller should crawl it (thread-safe)
+func (r *run) claim(url string) bool {
+	r.visitedMu.Lock()
+	defer r.visitedMu.Unlock()
+
+	delete(r.queued, url)
+	if r.visited[url] || (r.maxPages > 0 && len(r.visited) >= r.maxPages) {
+		return false
+	}
+	r.visited[url] = true
+	return true
 }
 
-// markVisited marks URL as visited (thread-safe)
-func (c *Crawler) markVisited(url string) {
-	c.visitedMu.Lock()
-	defer c.visitedMu.Unlock()
-	c.visited[url] = true
+// markQueued records URL as queued unless it was visited or already is,
+// reporting whether the caller should enqueue it (thread-safe)
+func (r *run) markQueued(url string) bool {
+	r.visitedMu.Lock()
+	defer r.visitedMu.Unlock()
+
+	if r.visited[url] || r.queued[url] {
+		return false
+	}
+	r.queued[url] = true
+	return true
 }
 
-// resolveURL resolves relative URLs to absolute
+// resolveURL resolves relative URLs to absolute, normalized form
 func (c *Crawler) resolveURL(base *url.URL, href string) string {
 	link, err := url.Parse(href)
 	if err != nil {
 		return ""
 	}
-	return base.ResolveReference(link).String()
+	resolved, ok := c.normalizeURL(base.ResolveReference(link))
+	if !ok {
+		return ""
+	}
+	return resolved
 }
 
-// shouldCrawl determines if URL should be crawled (same domain only)
-func (c *Crawler) shouldCrawl(targetURL string, baseURL *url.URL) bool {
+// shouldCrawl determines if URL should be crawled (in scope only)
+func (r *run) shouldCrawl(targetURL string) bool {
 	target, err := url.Parse(targetURL)
 	if err != nil {
 		return false
 	}
 
-	// Only crawl same domain
-	return target.Host == baseURL.Host && (target.Scheme == "http" || target.Scheme == "https")
+	return r.scope.Contains(target)
 }
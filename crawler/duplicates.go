@@ -0,0 +1,14 @@
+package crawler
+
+// claimContent records hash as the body of rawURL and returns the URL
+// first crawled with it: rawURL itself, or the page it duplicates
+func (r *run) claimContent(hash, rawURL string) string {
+	r.contentsMu.Lock()
+	defer r.contentsMu.Unlock()
+
+	if first, ok := r.contents[hash]; ok {
+		return first
+	}
+	r.contents[hash] = rawURL
+	return rawURL
+}
@@ -0,0 +1,53 @@
+package crawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+
+	"gocrawler/storage"
+)
+
+// classifyError maps a fetch error to its error type
+func classifyError(err error) storage.ErrorType {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case errors.Is(err, errRedirectLoop):
+		return storage.ErrorRedirectLoop
+	case errors.Is(err, errTooManyRedirects):
+		return storage.ErrorTooManyRedirects
+	case errors.As(err, &dnsErr):
+		return storage.ErrorDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return storage.ErrorTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return storage.ErrorTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return storage.ErrorConnRefused
+	default:
+		return storage.ErrorNetwork
+	}
+}
+
+// statusErrorType maps a non-200 status code to its error type
+func statusErrorType(code int) storage.ErrorType {
+	switch {
+	case code >= 400 && code < 500:
+		return storage.ErrorHTTP4xx
+	case code >= 500:
+		return storage.ErrorHTTP5xx
+	default:
+		return storage.ErrorHTTPOther
+	}
+}
@@ -0,0 +1,97 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gocrawler/events"
+	"gocrawler/storage"
+)
+
+// WaybackAPI is the Wayback Machine availability API
+const WaybackAPI = "https://archive.org/wayback/available"
+
+// checkExternal fetches an out-of-scope link target unless another
+// worker already did, and publishes whether it resolves. Broken targets
+// are looked up in the Wayback Machine with -wayback.
+func (r *run) checkExternal(ctx context.Context, target string) {
+	if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	if r.trace != nil || r.seedsOnly {
+		// replays and refetches don't follow links anywhere
+		return
+	}
+	if !r.claimResource(target) {
+		return
+	}
+
+	r.rateLimiter.Wait(ctx)
+	link := &storage.ExternalLink{URL: target}
+	resp, err := r.fetch(ctx, target, "", nil)
+	if err != nil {
+		link.Error = err.Error()
+		link.ErrorType = classifyError(err)
+	} else {
+		link.StatusCode = resp.StatusCode
+		link.OK = resp.StatusCode < 400
+		if !link.OK {
+			link.ErrorType = statusErrorType(resp.StatusCode)
+		}
+		drainClose(resp.Body)
+	}
+	if !link.OK {
+		r.logf(LogWarn, "⚠️  Broken external link %s (status %d) %s", target, link.StatusCode, link.Error)
+		if r.wayback {
+			archived, err := r.archivedCopy(ctx, target)
+			if err != nil {
+				r.logf(LogWarn, "⚠️  Wayback Machine lookup of %s failed: %v", target, err)
+			}
+			link.ArchiveURL = archived
+		}
+	}
+	r.bus.Publish(events.Event{Type: events.ExternalChecked, URL: target, External: link})
+}
+
+// archivedCopy returns the closest Wayback Machine snapshot of target,
+// "" if it was never archived
+func (r *run) archivedCopy(ctx context.Context, target string) (string, error) {
+	r.rateLimiter.Wait(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, WaybackAPI+"?url="+url.QueryEscape(target), nil)
+	if err != nil {
+		return "", err
+	}
+	if r.userAgent != "" {
+		req.Header.Set("User-Agent", r.userAgent)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer drainClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var availability struct {
+		ArchivedSnapshots struct {
+			Closest struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&availability); err != nil {
+		return "", err
+	}
+	closest := availability.ArchivedSnapshots.Closest
+	if !closest.Available {
+		return "", nil
+	}
+	// snapshots are also served over https
+	return strings.Replace(closest.URL, "http://", "https://", 1), nil
+}
@@ -0,0 +1,45 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ParseFragmentRoutes splits a -fragment-routes list such as "#!/,#/"
+// into fragment prefixes
+func ParseFragmentRoutes(s string) []string {
+	var prefixes []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimPrefix(strings.TrimSpace(p), "#"); p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
+}
+
+// isRoute reports whether a fragment is a configured SPA route that
+// identifies a distinct page
+func (c *Crawler) isRoute(fragment string) bool {
+	for _, prefix := range c.fragmentRoutes {
+		if strings.HasPrefix(fragment, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestURL maps a hashbang route to its _escaped_fragment_ form, so
+// servers implementing the AJAX crawling scheme return the route's HTML.
+// Other URLs are requested as they are.
+func requestURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.HasPrefix(u.Fragment, "!") {
+		return rawURL
+	}
+
+	query := u.Query()
+	query.Set("_escaped_fragment_", strings.TrimPrefix(u.Fragment, "!"))
+	u.RawQuery = query.Encode()
+	u.Fragment, u.RawFragment = "", ""
+	return u.String()
+}
@@ -0,0 +1,186 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultFrontierSize is how many jobs wait in memory before the
+// frontier spills to disk
+const DefaultFrontierSize = 1000
+
+// spillQueue is a disk-backed FIFO of jobs that did not fit in the
+// in-memory frontier. Jobs are appended as JSON lines and read back in
+// order; the file is truncated whenever it has been read to the end.
+// (thread-safe)
+type spillQueue struct {
+	dir     string
+	w       *os.File // appends, nil until the first spill
+	r       *os.File // reads from the oldest unread job
+	reader  *bufio.Reader
+	pending int
+	popped  bool // the feeder holds a popped job it didn't send yet
+	spilled int  // jobs ever spilled, for the end of crawl summary
+	ready   chan struct{}
+	mu      sync.Mutex
+}
+
+func newSpillQueue(dir string) *spillQueue {
+	return &spillQueue{dir: dir, ready: make(chan struct{}, 1)}
+}
+
+// push appends a job to the queue
+func (q *spillQueue) push(job Job) error {
+	line, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.w == nil {
+		if err := q.open(); err != nil {
+			return err
+		}
+	}
+	if _, err := q.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	q.pending++
+	q.spilled++
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// open creates the spill file with a second handle for reading
+func (q *spillQueue) open() error {
+	w, err := os.CreateTemp(q.dir, "gocrawler-frontier-*.jsonl")
+	if err != nil {
+		return err
+	}
+	r, err := os.Open(w.Name())
+	if err != nil {
+		w.Close()
+		os.Remove(w.Name())
+		return err
+	}
+	q.w, q.r, q.reader = w, r, bufio.NewReader(r)
+	return nil
+}
+
+// pop removes the oldest job, ok is false when the queue is empty. The
+// job still counts in len until sent is called, so that push keeps
+// spilling newer jobs behind it meanwhile.
+func (q *spillQueue) pop() (job Job, ok bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == 0 {
+		return Job{}, false, nil
+	}
+
+	line, err := q.reader.ReadBytes('\n')
+	if err != nil {
+		return Job{}, false, err
+	}
+	q.pending--
+	if q.pending == 0 {
+		// everything was read back, start the file over
+		if err := q.w.Truncate(0); err != nil {
+			return Job{}, false, err
+		}
+		if _, err := q.w.Seek(0, 0); err != nil {
+			return Job{}, false, err
+		}
+		if _, err := q.r.Seek(0, 0); err != nil {
+			return Job{}, false, err
+		}
+		q.reader.Reset(q.r)
+	}
+	if err := json.Unmarshal(line, &job); err != nil {
+		return Job{}, false, err
+	}
+	q.popped = true
+	return job, true, nil
+}
+
+// sent records that the job returned by pop reached the frontier
+func (q *spillQueue) sent() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.popped = false
+}
+
+// len returns the number of spilled jobs not yet in the frontier
+func (q *spillQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.popped {
+		return q.pending + 1
+	}
+	return q.pending
+}
+
+// total returns the number of jobs ever spilled
+func (q *spillQueue) total() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.spilled
+}
+
+// close deletes the spill file
+func (q *spillQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.w == nil {
+		return
+	}
+	q.r.Close()
+	q.w.Close()
+	os.Remove(q.w.Name())
+	q.w, q.r, q.reader, q.pending, q.popped = nil, nil, nil, 0, false
+}
+
+// feedFrontier moves spilled jobs of s back into its in-memory frontier
+// as workers free room, until ctx is cancelled or stop is closed
+func (r *run) feedFrontier(ctx context.Context, s *shard, stop <-chan struct{}) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-s.spill.ready:
+		case <-ticker.C:
+		}
+
+		for {
+			job, ok, err := s.spill.pop()
+			if err != nil {
+				r.logf(LogError, "❌ Error reading the frontier spill file: %v", err)
+				break
+			}
+			if !ok {
+				break
+			}
+			select {
+			case s.frontier <- job:
+				s.spill.sent()
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSnippets    = 10 // matches recorded per page
+	snippetContext = 40 // bytes kept on each side of a match
+)
+
+// ParseGrep compiles a -grep pattern, quoting it first when literal
+func ParseGrep(pattern string, literal bool) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	return regexp.Compile(pattern)
+}
+
+// grepSnippets returns the matches of re in body with some surrounding
+// context, whitespace collapsed so they fit on one line
+func grepSnippets(re *regexp.Regexp, body []byte) []string {
+	var snippets []string
+	for _, loc := range re.FindAllIndex(body, maxSnippets) {
+		start := max(loc[0]-snippetContext, 0)
+		end := min(loc[1]+snippetContext, len(body))
+		// Don't cut multi-byte characters in half
+		for start > 0 && !utf8.RuneStart(body[start]) {
+			start--
+		}
+		for end < len(body) && !utf8.RuneStart(body[end]) {
+			end++
+		}
+		snippets = append(snippets, strings.Join(strings.Fields(string(body[start:end])), " "))
+	}
+	return snippets
+}
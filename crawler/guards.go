@@ -0,0 +1,38 @@
+package crawler
+
+import (
+	"net/url"
+
+	"gocrawler/storage"
+)
+
+// skipReason reports why a URL should not be queued, or "" if it may be.
+// Very long URLs and URLs with many parameters are typical symptoms of
+// crawler traps such as calendars and faceted search.
+func (c *Crawler) skipReason(rawURL string) string {
+	s := c.Settings()
+	if s.MaxURLLength > 0 && len(rawURL) > s.MaxURLLength {
+		return storage.SkipURLTooLong
+	}
+	if s.MaxQueryParams > 0 {
+		if u, err := url.Parse(rawURL); err == nil && countParams(u.RawQuery) > s.MaxQueryParams {
+			return storage.SkipTooManyParams
+		}
+	}
+	if c.languages != nil {
+		if lang := pathLanguage(rawURL); lang != "" && !c.languages[lang] {
+			return storage.SkipLanguage
+		}
+	}
+	return ""
+}
+
+// countParams counts the parameters of a raw query, repeated keys included
+func countParams(rawQuery string) int {
+	params, _ := url.ParseQuery(rawQuery)
+	n := 0
+	for _, values := range params {
+		n += len(values)
+	}
+	return n
+}
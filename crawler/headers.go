@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+)
+
+// HeaderRule overrides request headers for URLs matching Pattern
+type HeaderRule struct {
+	Pattern *regexp.Regexp
+	Headers map[string]string // an empty value removes the header
+}
+
+// LoadHeaderRules reads header overrides from a JSON file such as
+//
+//	[{"pattern": "^https://api\\.example\\.com/", "headers": {"X-Env": "staging"}}]
+//
+// Rules apply in file order, so later matches win.
+func LoadHeaderRules(file string) ([]HeaderRule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var raw []struct {
+		Pattern string            `json:"pattern"`
+		Headers map[string]string `json:"headers"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+
+	rules := make([]HeaderRule, 0, len(raw))
+	for _, r := range raw {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid header pattern %q: %w", r.Pattern, err)
+		}
+		rules = append(rules, HeaderRule{Pattern: pattern, Headers: r.Headers})
+	}
+	return rules, nil
+}
+
+// setHeaders adds the User-Agent, the Referer and any matching header
+// overrides, which may replace the User-Agent
+func (c *Crawler) setHeaders(req *http.Request, rawURL, referer string) {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if ref, err := url.Parse(referer); err == nil && referer != "" {
+		ref.Fragment, ref.RawFragment, ref.User = "", "", nil
+		// like browsers, don't leak https URLs to plain http
+		if ref.Scheme != "https" || req.URL.Scheme == "https" {
+			req.Header.Set("Referer", ref.String())
+		}
+	}
+
+	for _, rule := range c.headers {
+		if !rule.Pattern.MatchString(rawURL) {
+			continue
+		}
+		for name, value := range rule.Headers {
+			if value == "" {
+				req.Header.Del(name)
+			} else {
+				req.Header.Set(name, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package crawler
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// percentEscape matches one percent-encoded byte
+var percentEscape = regexp.MustCompile(`%[0-9a-fA-F]{2}`)
+
+// asciiHost converts an internationalized hostname (with optional port)
+// to its lowercase punycode form, so Unicode and punycode spellings of
+// a host compare equal. Hosts that aren't valid IDNs are only lowercased.
+func asciiHost(host string) string {
+	host = strings.ToLower(host)
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, ""
+	}
+	if ascii, err := idna.Lookup.ToASCII(name); err == nil && ascii != "" {
+		name = ascii
+	}
+	if port != "" {
+		return net.JoinHostPort(name, port)
+	}
+	return name
+}
+
+// normalizeIDN puts the host of u in punycode and the escapes of its
+// path in uppercase, so "%c3%a9", "%C3%A9" and "é" give the same URL
+func normalizeIDN(u *url.URL) {
+	u.Host = asciiHost(u.Host)
+	if u.RawPath != "" {
+		u.RawPath = percentEscape.ReplaceAllStringFunc(u.RawPath, strings.ToUpper)
+	}
+}
+
+// asciiURL returns rawURL with its host in punycode
+func asciiURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	normalizeIDN(u)
+	return u.String()
+}
@@ -0,0 +1,37 @@
+package crawler
+
+import (
+	"gocrawler/parser"
+	"gocrawler/storage"
+)
+
+// unchanged rebuilds the parse result of a page that answered
+// 304 Not Modified from what the previous run stored for it
+func unchanged(prev *storage.Page) *parser.PageInfo {
+	info := &parser.PageInfo{
+		Title:       prev.Title,
+		Description: prev.Description,
+		Links:       prev.Links,
+		AMP:         prev.AMPURL,
+		Mobile:      prev.MobileURL,
+		Icons:       prev.Icons,
+		Manifest:    prev.Manifest,
+		OGImage:     prev.OGImage,
+		Canonical:   prev.Canonical,
+		Refresh:     prev.MetaRefresh,
+		Lang:        prev.Language,
+	}
+	for _, e := range prev.Embeds {
+		info.Embeds = append(info.Embeds, parser.Embed{Kind: e.Kind, Src: e.URL})
+	}
+	for _, a := range prev.Anchors {
+		info.Anchors = append(info.Anchors, parser.Link{Href: a.Href, Text: a.Text, Position: a.Position, Rel: a.Rel})
+	}
+	if prev.NoIndex {
+		info.Robots = "noindex"
+	}
+	if prev.NoFollow {
+		info.Robots += ",nofollow"
+	}
+	return info
+}
@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gocrawler/events"
+	"gocrawler/storage"
+)
+
+// maxScriptBytes is how much of an external script is scanned
+const maxScriptBytes = 5 << 20
+
+var (
+	// absoluteURL matches http(s) URLs inside JS strings
+	absoluteURL = regexp.MustCompile(`https?://[A-Za-z0-9.\-]+(?::\d+)?(?:/[^\s"'\x60<>\\)]*)?`)
+	// quotedPath matches string literals holding a root-relative path
+	quotedPath = regexp.MustCompile(`["'\x60](/[A-Za-z0-9_\-./~%]+(?:\?[^\s"'\x60<>\\]*)?)["'\x60]`)
+)
+
+// scanScripts looks for URLs and API endpoints in a page's inline scripts
+// and in external scripts of the same origin. Findings are published
+// for the endpoints report, they are not crawled.
+func (r *run) scanScripts(ctx context.Context, base *url.URL, inline, external []string) {
+	for _, script := range inline {
+		r.publishEndpoints(base, base.String(), script)
+	}
+
+	for _, src := range external {
+		ref, err := url.Parse(src)
+		if err != nil {
+			continue
+		}
+		scriptURL := base.ResolveReference(ref)
+		if scriptURL.Scheme != base.Scheme || scriptURL.Host != base.Host || !r.claimResource(scriptURL.String()) {
+			continue
+		}
+
+		r.rateLimiter.Wait(ctx)
+		resp, err := r.fetch(ctx, scriptURL.String(), base.String(), nil)
+		if err != nil {
+			continue
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxScriptBytes))
+		drainClose(resp.Body)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			continue
+		}
+		r.publishEndpoints(scriptURL, scriptURL.String(), string(body))
+	}
+}
+
+// publishEndpoints publishes every distinct URL-like string in a script,
+// paths resolved against the script's URL
+func (r *run) publishEndpoints(base *url.URL, source, script string) {
+	found := make(map[string]bool)
+	for _, match := range absoluteURL.FindAllString(script, -1) {
+		found[strings.TrimRight(match, ".,;")] = true
+	}
+	for _, match := range quotedPath.FindAllStringSubmatch(script, -1) {
+		if strings.HasPrefix(match[1], "//") {
+			continue // protocol-relative, or a comment marker
+		}
+		if ref, err := url.Parse(match[1]); err == nil {
+			found[base.ResolveReference(ref).String()] = true
+		}
+	}
+
+	for endpoint := range found {
+		r.bus.Publish(events.Event{
+			Type:     events.EndpointFound,
+			URL:      endpoint,
+			Endpoint: &storage.Endpoint{URL: endpoint, Source: source},
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package crawler
+
+import (
+	"gocrawler/parser"
+	"gocrawler/storage"
+)
+
+// termsPerPage is how many keywords and entities a page keeps
+const termsPerPage = 50
+
+// extractTerms fills the keywords and entities of a page from its main
+// content, or from all of its visible text
+func extractTerms(page *storage.Page, info *parser.PageInfo) {
+	text := info.Text
+	if info.Article != nil && info.Article.Words >= storage.MinArticleWords {
+		text = info.Article.Text
+	}
+	page.Keywords = parser.Keywords(text, termsPerPage)
+	page.Entities = parser.Entities(text, termsPerPage)
+}
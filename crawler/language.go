@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// languageSet indexes the primary subtags of languages ("en-GB" → "en"),
+// nil when every language is crawled
+func languageSet(languages []string) map[string]bool {
+	var set map[string]bool
+	for _, lang := range languages {
+		if primary := primaryLanguage(lang); primary != "" {
+			if set == nil {
+				set = make(map[string]bool)
+			}
+			set[primary] = true
+		}
+	}
+	return set
+}
+
+// primaryLanguage returns the lowercased primary subtag of a language
+// tag, or "" if tag doesn't look like one
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	if len(primary) < 2 || len(primary) > 3 {
+		return ""
+	}
+	for _, ch := range primary {
+		if ch < 'a' || ch > 'z' {
+			return ""
+		}
+	}
+	return primary
+}
+
+// isoLanguages are the ISO 639-1 codes recognized as path segments, so
+// that /js/ or /ui/ aren't mistaken for languages
+var isoLanguages = strings.Fields(
+	"aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo " +
+		"br bs ca ce ch co cr cs cu cv cy da de dv dz ee el en eo es " +
+		"et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr " +
+		"ht hu hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj " +
+		"kk kl km kn ko kr ks ku kv kw ky la lb lg li ln lo lt lu lv " +
+		"mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv " +
+		"ny oc oj om or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd " +
+		"se sg si sk sl sm sn so sq sr ss st su sv sw ta te tg th ti " +
+		"tk tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi " +
+		"yo za zh zu")
+
+// pathLanguage returns the language of a URL whose first path segment
+// is an ISO 639-1 code with an optional region, as in /en/ or /pt-br/,
+// or "" for any other path. Three-letter codes are left out, they clash
+// with too many ordinary segments (/api/, /faq/).
+func pathLanguage(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	lang, region, hasRegion := strings.Cut(strings.ReplaceAll(segment, "_", "-"), "-")
+	lang = strings.ToLower(lang)
+	if len(lang) != 2 || !slices.Contains(isoLanguages, lang) {
+		return ""
+	}
+	if hasRegion && (len(region) < 2 || len(region) > 4) {
+		return ""
+	}
+	return lang
+}
+
+// contentLanguage returns the first language of a Content-Language header
+func contentLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	return strings.TrimSpace(first)
+}
+
+// wantLanguage reports whether links of a page declaring lang are
+// followed. Pages that declare no language are followed.
+func (c *Crawler) wantLanguage(lang string) bool {
+	if c.languages == nil || lang == "" {
+		return true
+	}
+	return c.languages[primaryLanguage(lang)]
+}
@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamAction is what normalization does with a query parameter
+type ParamAction string
+
+const (
+	// ParamKeep leaves the parameter untouched (default)
+	ParamKeep ParamAction = "keep"
+	// ParamDrop removes the parameter from the URL
+	ParamDrop ParamAction = "drop"
+	// ParamPage treats the parameter as a page number capped at MaxPage
+	ParamPage ParamAction = "page"
+)
+
+// ParamPolicy describes how a single query parameter is handled
+type ParamPolicy struct {
+	Action  ParamAction
+	MaxPage int // only used by ParamPage
+}
+
+// ParamPolicies maps parameter names to policies, "utm_*" matches by prefix
+type ParamPolicies struct {
+	exact    map[string]ParamPolicy
+	prefixes []prefixPolicy // longest prefix first
+}
+
+// prefixPolicy is the policy of a "name*" pattern
+type prefixPolicy struct {
+	prefix string
+	policy ParamPolicy
+}
+
+// ParseParamPolicies parses "utm_*:drop,sid:drop,page:page=10". When
+// several patterns match a parameter the longest prefix wins, so
+// "utm_*:drop,utm_source_*:keep" keeps utm_source_x on every run.
+func ParseParamPolicies(s string) (ParamPolicies, error) {
+	policies := ParamPolicies{exact: make(map[string]ParamPolicy)}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(item, ":")
+		if !ok || name == "" {
+			return ParamPolicies{}, fmt.Errorf("invalid param policy %q (want name:action)", item)
+		}
+
+		action, arg, _ := strings.Cut(spec, "=")
+		policy := ParamPolicy{Action: ParamAction(action)}
+		switch policy.Action {
+		case ParamKeep, ParamDrop:
+		case ParamPage:
+			maxPage, err := strconv.Atoi(arg)
+			if err != nil || maxPage < 1 {
+				return ParamPolicies{}, fmt.Errorf("invalid page cap in %q (want name:page=N)", item)
+			}
+			policy.MaxPage = maxPage
+		default:
+			return ParamPolicies{}, fmt.Errorf("invalid param action %q (want keep, drop or page)", action)
+		}
+		policies.set(name, policy)
+	}
+	sort.SliceStable(policies.prefixes, func(i, j int) bool {
+		return len(policies.prefixes[i].prefix) > len(policies.prefixes[j].prefix)
+	})
+	return policies, nil
+}
+
+// set adds the policy of a name or pattern, replacing an earlier one
+func (p *ParamPolicies) set(name string, policy ParamPolicy) {
+	prefix, ok := strings.CutSuffix(name, "*")
+	if !ok {
+		p.exact[name] = policy
+		return
+	}
+	for i := range p.prefixes {
+		if p.prefixes[i].prefix == prefix {
+			p.prefixes[i].policy = policy
+			return
+		}
+	}
+	p.prefixes = append(p.prefixes, prefixPolicy{prefix: prefix, policy: policy})
+}
+
+// lookup finds the policy for a parameter, exact names win over
+// prefixes and longer prefixes over shorter ones
+func (p ParamPolicies) lookup(name string) ParamPolicy {
+	if policy, ok := p.exact[name]; ok {
+		return policy
+	}
+	for _, pp := range p.prefixes {
+		if strings.HasPrefix(name, pp.prefix) {
+			return pp.policy
+		}
+	}
+	return ParamPolicy{Action: ParamKeep}
+}
+
+// normalizeURL canonicalizes a URL so equivalent links dedupe to one entry.
+// It returns false when a parameter policy rejects the URL entirely.
+func (c *Crawler) normalizeURL(u *url.URL) (string, bool) {
+	u.Scheme = strings.ToLower(u.Scheme)
+	normalizeIDN(u)
+	if !c.isRoute(u.Fragment) {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+
+	if u.RawQuery != "" {
+		// Parameters keep their encoding, servers may tell "?foo" from
+		// "?foo=", only dropped ones and empty pairs are removed
+		pairs := make([]string, 0, strings.Count(u.RawQuery, "&")+1)
+		for _, pair := range strings.Split(u.RawQuery, "&") {
+			if pair == "" {
+				continue
+			}
+			policy := c.params.lookup(queryUnescape(paramName(pair)))
+			switch policy.Action {
+			case ParamDrop:
+				continue
+			case ParamPage:
+				_, value, _ := strings.Cut(pair, "=")
+				if n, err := strconv.Atoi(queryUnescape(value)); err == nil && n > policy.MaxPage {
+					return "", false
+				}
+			}
+			pairs = append(pairs, pair)
+		}
+		// Sort by name, so parameter order doesn't create duplicates
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return paramName(pairs[i]) < paramName(pairs[j])
+		})
+		u.RawQuery = strings.Join(pairs, "&")
+	}
+
+	return u.String(), true
+}
+
+// paramName returns the still encoded name of a "name=value" pair
+func paramName(pair string) string {
+	name, _, _ := strings.Cut(pair, "=")
+	return name
+}
+
+// queryUnescape decodes a query component, leaving malformed ones as is
+func queryUnescape(s string) string {
+	if decoded, err := url.QueryUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
@@ -0,0 +1,35 @@
+package crawler
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNormalizeURLQuery(t *testing.T) {
+	params, err := ParseParamPolicies("utm_*:drop,page:page=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Crawler{params: params}
+
+	for _, tt := range []struct {
+		in, want string
+		ok       bool
+	}{
+		{"https://example.com/?foo", "https://example.com/?foo", true},
+		{"https://example.com/?foo=", "https://example.com/?foo=", true},
+		{"https://example.com/?b=2&foo&a=1", "https://example.com/?a=1&b=2&foo", true},
+		{"https://example.com/?utm_source=x&foo&q=a%20b", "https://example.com/?foo&q=a%20b", true},
+		{"https://example.com/?utm_source=x", "https://example.com/", true},
+		{"https://example.com/?page=11", "", false},
+	} {
+		u, err := url.Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := c.normalizeURL(u)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalizeURL(%s) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
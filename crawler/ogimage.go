@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"fmt"
+	"image"
+	"io"
+
+	"gocrawler/storage"
+
+	// Formats understood by image.DecodeConfig
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// inspectImage reads the image header of an og:image and flags it when
+// it is smaller than the configured minimum dimensions
+func (r *run) inspectImage(asset *storage.Asset, body io.Reader) {
+	cfg, _, err := image.DecodeConfig(body)
+	if err != nil {
+		asset.OK = false
+		asset.Error = "not a recognized image"
+		return
+	}
+
+	asset.Width, asset.Height = cfg.Width, cfg.Height
+	if cfg.Width < r.ogMinWidth || cfg.Height < r.ogMinHeight {
+		asset.OK = false
+		asset.Error = fmt.Sprintf("too small: %dx%d, want at least %dx%d",
+			cfg.Width, cfg.Height, r.ogMinWidth, r.ogMinHeight)
+	}
+}
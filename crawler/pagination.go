@@ -0,0 +1,59 @@
+package crawler
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pageParams are query parameters commonly used for page numbers
+var pageParams = []string{"page", "p", "pg", "paged"}
+
+// pagePathPattern matches path-based pagination like /blog/page/3/
+var pagePathPattern = regexp.MustCompile(`/page/(\d+)/?$`)
+
+// paginationSeries detects common pagination patterns and returns the
+// series key (the URL without its page marker) and the page number
+func paginationSeries(rawURL string) (string, int, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, false
+	}
+
+	if m := pagePathPattern.FindStringSubmatch(u.Path); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		u.Path = strings.TrimSuffix(u.Path, m[0]) + "/"
+		return u.String(), n, true
+	}
+
+	query := u.Query()
+	for _, name := range pageParams {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				continue
+			}
+			query.Del(name)
+			u.RawQuery = query.Encode()
+			return u.String(), n, true
+		}
+	}
+
+	return "", 0, false
+}
+
+// seriesFor tags a job with its pagination series, if it belongs to one
+func (c *Crawler) seriesFor(job Job, hasPagination bool) (string, int) {
+	if job.Series != "" {
+		return job.Series, job.SeriesPage
+	}
+	if key, n, ok := paginationSeries(job.URL); ok {
+		return key, n
+	}
+	if hasPagination {
+		// rel=next/prev without a recognizable marker, the URL starts the series
+		return job.URL, 1
+	}
+	return "", 0
+}
@@ -0,0 +1,202 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// maxProxyFailures is how many consecutive failures evict a proxy
+const maxProxyFailures = 5
+
+// ProxyStat summarizes how a proxy performed
+type ProxyStat struct {
+	URL       string
+	Successes int
+	Failures  int
+	Evicted   bool
+}
+
+// SuccessRate returns the share of successful requests, 0 when unused
+func (s ProxyStat) SuccessRate() float64 {
+	if total := s.Successes + s.Failures; total > 0 {
+		return float64(s.Successes) / float64(total)
+	}
+	return 0
+}
+
+// ParseProxies parses a comma-separated list of proxy URLs
+// (http, https or socks5)
+func ParseProxies(list string) ([]*url.URL, error) {
+	var proxies []*url.URL
+	for _, raw := range strings.Split(list, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q", raw)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+		}
+		proxies = append(proxies, u)
+	}
+	return proxies, nil
+}
+
+// proxyState is one proxy of the pool
+type proxyState struct {
+	url         *url.URL
+	successes   int
+	failures    int
+	consecutive int // failures since the last success
+	evicted     bool
+}
+
+// ProxyPool rotates requests over proxies, evicting ones that keep
+// failing (thread-safe)
+type ProxyPool struct {
+	mu      sync.Mutex
+	proxies []*proxyState
+	next    int
+}
+
+func newProxyPool(proxies []*url.URL) *ProxyPool {
+	pool := &ProxyPool{}
+	for _, u := range proxies {
+		pool.proxies = append(pool.proxies, &proxyState{url: u})
+	}
+	return pool
+}
+
+// pick returns the next active proxy other than avoid, or nil
+func (p *ProxyPool) pick(avoid *proxyState) *proxyState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for range p.proxies {
+		proxy := p.proxies[p.next%len(p.proxies)]
+		p.next++
+		if !proxy.evicted && proxy != avoid {
+			return proxy
+		}
+	}
+	return nil
+}
+
+// report records the outcome of a request made through proxy. A proxy
+// failing maxProxyFailures times in a row is evicted, unless it is the
+// last one left.
+func (p *ProxyPool) report(proxy *proxyState, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ok {
+		proxy.successes++
+		proxy.consecutive = 0
+		return
+	}
+	proxy.failures++
+	proxy.consecutive++
+	if proxy.evicted || proxy.consecutive < maxProxyFailures {
+		return
+	}
+	active := 0
+	for _, other := range p.proxies {
+		if !other.evicted {
+			active++
+		}
+	}
+	if active > 1 {
+		proxy.evicted = true
+		log.Printf("🚫 Evicted proxy %s after %d consecutive failures", proxy.url.Redacted(), proxy.consecutive)
+	}
+}
+
+// Stats returns the per-proxy counters
+func (p *ProxyPool) Stats() []ProxyStat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]ProxyStat, 0, len(p.proxies))
+	for _, proxy := range p.proxies {
+		stats = append(stats, ProxyStat{
+			URL:       proxy.url.Redacted(),
+			Successes: proxy.successes,
+			Failures:  proxy.failures,
+			Evicted:   proxy.evicted,
+		})
+	}
+	return stats
+}
+
+type proxyKey struct{}
+
+// proxyFor is the http.Transport Proxy function, using the proxy the
+// proxyTransport put in the request context
+func proxyFor(req *http.Request) (*url.URL, error) {
+	if proxy, ok := req.Context().Value(proxyKey{}).(*proxyState); ok {
+		return proxy.url, nil
+	}
+	return nil, nil
+}
+
+// proxyTransport sends each request through a proxy of the pool and
+// retries once through a different proxy on network errors
+type proxyTransport struct {
+	base http.RoundTripper
+	pool *ProxyPool
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxy := t.pool.pick(nil)
+	if proxy == nil {
+		return nil, errors.New("no usable proxy left")
+	}
+	resp, err := t.through(req, proxy)
+	if err == nil || req.Context().Err() != nil {
+		return resp, err
+	}
+
+	retry := t.pool.pick(proxy)
+	if retry == nil || (req.Body != nil && req.GetBody == nil) {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	log.Printf("🔁 Retrying %s through another proxy: %v", req.URL, err)
+	return t.through(req, retry)
+}
+
+// through sends req via proxy and records the outcome
+func (t *proxyTransport) through(req *http.Request, proxy *proxyState) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyKey{}, proxy)))
+	if req.Context().Err() == nil {
+		t.pool.report(proxy, err == nil)
+	}
+	return resp, err
+}
+
+// ProxyStats returns the per-proxy counters, nil without a proxy pool
+func (c *Crawler) ProxyStats() []ProxyStat {
+	if c.proxies == nil {
+		return nil
+	}
+	return c.proxies.Stats()
+}
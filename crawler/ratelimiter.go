@@ -2,6 +2,8 @@ package crawler
 
 import (
 	"context"
+	"math/rand/v2"
+	"sync"
 	"time"
 )
 
@@ -9,6 +11,10 @@ import (
 type RateLimiter struct {
 	ticker *time.Ticker
 	tokens chan struct{}
+	done   chan struct{}
+	mu     sync.Mutex
+	rate   int
+	resume chan struct{} // non-nil while paused, closed on resume
 }
 
 // NewRateLimiter creates a rate limiter with specified requests per second
@@ -17,6 +23,8 @@ func NewRateLimiter(requestsPerSecond int) *RateLimiter {
 	rl := &RateLimiter{
 		ticker: time.NewTicker(interval),
 		tokens: make(chan struct{}, requestsPerSecond),
+		done:   make(chan struct{}),
+		rate:   requestsPerSecond,
 	}
 
 	// Fill initial tokens
@@ -26,7 +34,13 @@ func NewRateLimiter(requestsPerSecond int) *RateLimiter {
 
 	// Refill tokens continuously
 	go func() {
-		for range rl.ticker.C {
+		for {
+			select {
+			case <-rl.done:
+				return
+			case <-rl.ticker.C:
+			}
+
 			select {
 			case rl.tokens <- struct{}{}:
 			default:
@@ -38,8 +52,20 @@ func NewRateLimiter(requestsPerSecond int) *RateLimiter {
 	return rl
 }
 
-// Wait blocks until a token is available or context is cancelled
+// Wait blocks until a token is available or context is cancelled,
+// and for as long as the limiter is paused
 func (rl *RateLimiter) Wait(ctx context.Context) {
+	rl.mu.Lock()
+	resume := rl.resume
+	rl.mu.Unlock()
+	if resume != nil {
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return
+		}
+	}
+
 	select {
 	case <-rl.tokens:
 		return
@@ -48,8 +74,66 @@ func (rl *RateLimiter) Wait(ctx context.Context) {
 	}
 }
 
-// Stop stops the rate limiter
+// SetRate changes the requests per second, the burst size stays the same
+func (rl *RateLimiter) SetRate(requestsPerSecond int) {
+	if requestsPerSecond < 1 {
+		requestsPerSecond = 1
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.rate = requestsPerSecond
+	rl.ticker.Reset(time.Second / time.Duration(requestsPerSecond))
+}
+
+// Rate returns the current requests per second
+func (rl *RateLimiter) Rate() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.rate
+}
+
+// Pause blocks Wait callers until Resume
+func (rl *RateLimiter) Pause() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.resume == nil {
+		rl.resume = make(chan struct{})
+	}
+}
+
+// Resume releases the callers blocked by Pause
+func (rl *RateLimiter) Resume() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.resume != nil {
+		close(rl.resume)
+		rl.resume = nil
+	}
+}
+
+// Paused reports whether the limiter is paused
+func (rl *RateLimiter) Paused() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.resume != nil
+}
+
+// Stop stops the rate limiter and its refill goroutine
 func (rl *RateLimiter) Stop() {
 	rl.ticker.Stop()
-	close(rl.tokens)
+	close(rl.done)
+}
+
+// pause waits -delay before a request, between half and one and a half
+// of it so that workers don't hit the site in lockstep
+func (c *Crawler) pause(ctx context.Context) {
+	if c.delay <= 0 {
+		return
+	}
+	timer := time.NewTimer(c.delay/2 + rand.N(c.delay))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
 }
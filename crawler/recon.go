@@ -0,0 +1,40 @@
+package crawler
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// commentAttr matches href/src attributes in commented-out markup
+var commentAttr = regexp.MustCompile(`(?i)(?:href|src|action)\s*=\s*["']([^"']+)["']`)
+
+// commentURLs finds URLs in HTML comments: absolute URLs and the
+// targets of commented-out links, resolved against the page
+func commentURLs(base *url.URL, comments []string) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(raw string) {
+		ref, err := url.Parse(strings.TrimRight(raw, ".,;"))
+		if err != nil {
+			return
+		}
+		resolved := base.ResolveReference(ref).String()
+		if !seen[resolved] {
+			seen[resolved] = true
+			urls = append(urls, resolved)
+		}
+	}
+
+	for _, comment := range comments {
+		for _, match := range absoluteURL.FindAllString(comment, -1) {
+			add(match)
+		}
+		for _, match := range commentAttr.FindAllStringSubmatch(comment, -1) {
+			if !strings.HasPrefix(match[1], "#") && !strings.HasPrefix(match[1], "javascript:") {
+				add(match[1])
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,39 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxRedirects is how many redirects a request may follow
+const maxRedirects = 10
+
+var (
+	errRedirectLoop     = errors.New("redirect loop")
+	errTooManyRedirects = errors.New("too many redirects")
+)
+
+// checkRedirect stops a request that redirects back to a URL it already
+// visited, or that redirects more than maxRedirects times
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	for _, prev := range via {
+		if prev.URL.String() == req.URL.String() {
+			return fmt.Errorf("%w back to %s", errRedirectLoop, req.URL)
+		}
+	}
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("%w: stopped after %d", errTooManyRedirects, len(via))
+	}
+	return nil
+}
+
+// redirectChain lists the URLs a response was redirected through after
+// the requested one, ending with the final URL (nil without redirects)
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append([]string{req.URL.String()}, chain...)
+	}
+	return chain
+}
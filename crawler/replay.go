@@ -0,0 +1,158 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"gocrawler/events"
+	"gocrawler/parser"
+	"gocrawler/storage"
+)
+
+// replayHeaders are the response headers the pipeline acts on
+var replayHeaders = []string{"Content-Type", "Content-Length", "Last-Modified", "X-Robots-Tag", "Refresh"}
+
+// Replay runs one URL through the crawl pipeline at the given depth,
+// writing every decision to w: normalization, scope and guards, the
+// request as sent, the response, parsing and extraction, and what would
+// happen to each link. startURL binds the scope like a crawl's seed.
+// Links are not followed. It returns the page a crawl would store.
+func (c *Crawler) Replay(ctx context.Context, startURL, rawURL string, depth int, w io.Writer) *storage.Page {
+	bus := events.NewBus()
+	r := c.newRun(bus)
+	r.trace = func(format string, args ...any) {
+		fmt.Fprintf(w, "  • "+format+"\n", args...)
+	}
+	defer r.rateLimiter.Stop()
+	r.scope.init(asciiURL(startURL))
+
+	results := storage.NewResults()
+	events.Record(bus, results)
+	bus.Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.AssetChecked:
+			r.tracef("asset %s (%s): status %d %s", e.Asset.URL, e.Asset.Kind, e.Asset.StatusCode, e.Asset.Error)
+		case events.EndpointFound:
+			r.tracef("endpoint %s found in %s", e.Endpoint.URL, e.Endpoint.Source)
+		case events.CertificateSeen:
+			r.tracef("TLS certificate of %s covers %s", e.URL, strings.Join(e.Names, ", "))
+		}
+	}, events.AssetChecked, events.EndpointFound, events.CertificateSeen)
+
+	fmt.Fprintf(w, "🔁 Replaying %s\n", rawURL)
+	target := asciiURL(rawURL)
+	if target != rawURL {
+		r.tracef("internationalized host converted to %s", target)
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		r.tracef("unparsable URL: %v", err)
+		return nil
+	}
+	normalized, ok := c.normalizeURL(u)
+	if !ok {
+		r.tracef("rejected by a parameter policy, a crawl would never queue it")
+		return nil
+	}
+	if normalized != target {
+		r.tracef("normalized to %s", normalized)
+	}
+	if r.shouldCrawl(normalized) {
+		r.tracef("in scope of %s", startURL)
+	} else {
+		r.tracef("out of scope of %s, a crawl would not queue it (replaying anyway)", startURL)
+	}
+	if reason := c.skipReason(normalized); reason != "" {
+		r.tracef("a crawl would skip it (%s) (replaying anyway)", reason)
+	}
+
+	r.rateLimiter.Wait(ctx)
+	r.process(ctx, 0, Job{URL: normalized, Depth: depth})
+	if pages := results.GetPages(); len(pages) > 0 {
+		return pages[0]
+	}
+	return nil
+}
+
+// tracef explains a pipeline decision during a replay
+func (r *run) tracef(format string, args ...any) {
+	if r.trace != nil {
+		r.trace(format, args...)
+	}
+}
+
+// traceRequest reports the final request as sent, headers sorted
+func (r *run) traceRequest(req *http.Request) {
+	if r.trace == nil {
+		return
+	}
+	r.tracef("%s %s", req.Method, req.URL)
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(req.Header.Values(name), ", ")
+		if name == "Authorization" || name == "Cookie" {
+			value = "(redacted)"
+		}
+		r.tracef("  %s: %s", name, value)
+	}
+}
+
+// traceResponse reports the status, redirects and the headers that
+// change how the page is processed
+func (r *run) traceResponse(resp *http.Response, redirects []string, took time.Duration) {
+	if r.trace == nil {
+		return
+	}
+	r.tracef("%s in %dms", resp.Status, took.Milliseconds())
+	if len(redirects) > 0 {
+		r.tracef("redirected through %s", strings.Join(redirects, " → "))
+	}
+	for _, name := range replayHeaders {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			r.tracef("  %s: %s", name, strings.Join(values, ", "))
+		}
+	}
+}
+
+// tracePage reports what was extracted and the directives applied
+func (r *run) tracePage(page *storage.Page, info *parser.PageInfo, robotsHeaders []string) {
+	if r.trace == nil {
+		return
+	}
+	if len(robotsHeaders) > 0 || info.Robots != "" {
+		r.tracef("robots: X-Robots-Tag %q, meta robots %q → noindex=%t nofollow=%t", strings.Join(robotsHeaders, ", "), info.Robots, page.NoIndex, page.NoFollow)
+	}
+	if page.Canonical != "" {
+		r.tracef("canonical %s", page.Canonical)
+	}
+	if page.MetaRefresh != "" {
+		r.tracef("refreshes to %s (followed: %t)", page.MetaRefresh, r.followRefresh)
+	}
+	if page.Series != "" {
+		r.tracef("pagination series %s, page %d", page.Series, page.SeriesPage)
+	}
+	if page.AMPURL != "" || page.MobileURL != "" {
+		r.tracef("alternates: AMP %q, mobile %q", page.AMPURL, page.MobileURL)
+	}
+	if page.Article != nil {
+		r.tracef("article %q by %q, %d words", page.Article.Title, page.Article.Author, page.Article.Words)
+	}
+	if len(page.Keywords) > 0 {
+		top := make([]string, 0, 5)
+		for _, t := range page.Keywords[:min(5, len(page.Keywords))] {
+			top = append(top, fmt.Sprintf("%s (%d)", t.Term, t.Count))
+		}
+		r.tracef("top keywords: %s", strings.Join(top, ", "))
+	}
+	r.tracef("stored: %d links, %d anchors, %d embeds, %d icons", len(page.Links), len(page.Anchors), len(page.Embeds), len(page.Icons))
+}
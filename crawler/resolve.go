@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ParseResolve parses curl-style "host:port:addr" overrides into a map
+// from host:port to the address to connect to instead. IPv6 addresses
+// may be bracketed.
+func ParseResolve(specs []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		host, rest, ok1 := strings.Cut(spec, ":")
+		port, addr, ok2 := strings.Cut(rest, ":")
+		if !ok1 || !ok2 || host == "" || addr == "" {
+			return nil, fmt.Errorf("invalid -resolve %q (want host:port:addr)", spec)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("invalid port in -resolve %q", spec)
+		}
+		addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+		if net.ParseIP(addr) == nil {
+			return nil, fmt.Errorf("invalid address in -resolve %q (want an IP)", spec)
+		}
+		overrides[net.JoinHostPort(strings.ToLower(host), port)] = net.JoinHostPort(addr, port)
+	}
+	return overrides, nil
+}
+
+// resolvingDialer returns a DialContext connecting to the overridden
+// address of a host:port. TLS still verifies and sends the original
+// hostname, so staging servers answer as production would.
+func resolvingDialer(overrides map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil {
+			if target, ok := overrides[net.JoinHostPort(strings.ToLower(host), port)]; ok {
+				addr = target
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
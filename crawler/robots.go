@@ -0,0 +1,62 @@
+package crawler
+
+import "strings"
+
+// robotsValueDirectives take a value after a colon, so "name: x" is not a
+// user agent prefix for them
+var robotsValueDirectives = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// parseRobots merges X-Robots-Tag header values and the meta robots
+// content into noindex/nofollow. Values aimed at a named user agent
+// ("otherbot: noindex") only apply when it is agent, the crawler's
+// product token, on top of the values for every crawler.
+func parseRobots(headers []string, meta, agent string) (noindex, nofollow bool) {
+	for _, value := range append(headers, meta) {
+		if name, directives, ok := agentScoped(value); ok {
+			if agent == "" || !strings.EqualFold(name, agent) {
+				continue
+			}
+			value = directives
+		}
+		for _, directive := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "noindex":
+				noindex = true
+			case "nofollow":
+				nofollow = true
+			case "none":
+				noindex, nofollow = true, true
+			}
+		}
+	}
+	return noindex, nofollow
+}
+
+// agentScoped splits a robots value starting with "useragent:" into the
+// user agent and its directives
+func agentScoped(value string) (agent, directives string, ok bool) {
+	first, _, _ := strings.Cut(value, ",")
+	name, _, found := strings.Cut(first, ":")
+	name = strings.TrimSpace(name)
+	if !found || robotsValueDirectives[strings.ToLower(name)] {
+		return "", "", false
+	}
+	_, directives, _ = strings.Cut(value, ":")
+	return name, directives, true
+}
+
+// productToken returns the robots name of a User-Agent, "gocrawler" for
+// "gocrawler/1.0 (+https://example.com/bot)"
+func productToken(userAgent string) string {
+	fields := strings.Fields(userAgent)
+	if len(fields) == 0 {
+		return ""
+	}
+	name, _, _ := strings.Cut(fields[0], "/")
+	return name
+}
@@ -0,0 +1,43 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"gocrawler/parser"
+)
+
+func TestParseRobotsAgent(t *testing.T) {
+	agent := productToken("gocrawler/1.0 (+https://example.com/bot-info)")
+	if agent != "gocrawler" {
+		t.Fatalf("productToken = %q, want gocrawler", agent)
+	}
+
+	for _, tt := range []struct {
+		headers           []string
+		meta              string
+		noindex, nofollow bool
+	}{
+		{headers: []string{"GoCrawler: noindex"}, noindex: true},
+		{headers: []string{"otherbot: noindex"}},
+		{headers: []string{"otherbot: noindex", "gocrawler: nofollow, noarchive"}, nofollow: true},
+		{headers: []string{"nofollow"}, meta: "gocrawler: noindex", noindex: true, nofollow: true},
+		{headers: []string{"unavailable_after: 25 Jun 2010 15:00:00 PST"}},
+	} {
+		noindex, nofollow := parseRobots(tt.headers, tt.meta, agent)
+		if noindex != tt.noindex || nofollow != tt.nofollow {
+			t.Errorf("parseRobots(%q, %q) = %v, %v, want %v, %v", tt.headers, tt.meta, noindex, nofollow, tt.noindex, tt.nofollow)
+		}
+	}
+}
+
+func TestParseRobotsAgentMeta(t *testing.T) {
+	page := `<html><head><meta name="otherbot" content="nofollow"><meta name="gocrawler" content="noindex"></head></html>`
+	info, err := parser.ParseWith(strings.NewReader(page), "https://example.com/", parser.Options{RobotsAgent: "gocrawler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if noindex, nofollow := parseRobots(nil, info.Robots, "gocrawler"); !noindex || nofollow {
+		t.Errorf("got noindex %v, nofollow %v, want the gocrawler meta only", noindex, nofollow)
+	}
+}
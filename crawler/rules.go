@@ -0,0 +1,106 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gocrawler/events"
+	"gocrawler/storage"
+)
+
+// Rule asserts properties of the pages whose URL matches Pattern, zero
+// fields are not checked
+type Rule struct {
+	Name            string
+	Pattern         *regexp.Regexp
+	Status          int            // required status code
+	Title           *regexp.Regexp // the title must match
+	MaxResponseTime time.Duration
+	Headers         []string // response headers that must be present
+}
+
+// LoadRules reads page rules from a JSON file such as
+//
+//	[{"name": "blog", "pattern": "^https://example\\.com/blog/", "status": 200,
+//	  "title": "\\| Example Blog$", "max_response_ms": 800, "headers": ["Cache-Control"]}]
+//
+// A page is checked against every rule matching it, unnamed rules are
+// reported by their pattern.
+func LoadRules(file string) ([]Rule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var raw []struct {
+		Name          string   `json:"name"`
+		Pattern       string   `json:"pattern"`
+		Status        int      `json:"status"`
+		Title         string   `json:"title"`
+		MaxResponseMS int      `json:"max_response_ms"`
+		Headers       []string `json:"headers"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+
+	rules := make([]Rule, 0, len(raw))
+	for _, r := range raw {
+		rule := Rule{Name: r.Name, Status: r.Status, MaxResponseTime: time.Duration(r.MaxResponseMS) * time.Millisecond, Headers: r.Headers}
+		if rule.Pattern, err = regexp.Compile(r.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid rule pattern %q: %w", r.Pattern, err)
+		}
+		if r.Title != "" {
+			if rule.Title, err = regexp.Compile(r.Title); err != nil {
+				return nil, fmt.Errorf("invalid title pattern %q: %w", r.Title, err)
+			}
+		}
+		if rule.Name == "" {
+			rule.Name = r.Pattern
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// checkRules evaluates the rules matching page and publishes every
+// violation. header is nil when no response was received, titles are
+// only checked on parsed pages.
+func (r *run) checkRules(page *storage.Page, header http.Header, parsed bool) {
+	for _, rule := range r.rules {
+		if !rule.Pattern.MatchString(page.URL) {
+			continue
+		}
+		violate := func(check, expected, actual string) {
+			r.tracef("rule %s: %s violated, expected %s, got %s", rule.Name, check, expected, actual)
+			r.bus.Publish(events.Event{Type: events.RuleViolated, URL: page.URL, Violation: &storage.RuleViolation{
+				URL: page.URL, Rule: rule.Name, Check: check, Expected: expected, Actual: actual,
+			}})
+		}
+
+		if rule.Status != 0 && page.StatusCode != rule.Status {
+			actual := strconv.Itoa(page.StatusCode)
+			if page.StatusCode == 0 {
+				actual = "no response (" + string(page.ErrorType) + ")"
+			}
+			violate("status", strconv.Itoa(rule.Status), actual)
+		}
+		if rule.MaxResponseTime > 0 && page.ResponseTime > rule.MaxResponseTime {
+			violate("response_time", "under "+rule.MaxResponseTime.String(), page.ResponseTime.Round(time.Millisecond).String())
+		}
+		if rule.Title != nil && parsed && !rule.Title.MatchString(page.Title) {
+			violate("title", rule.Title.String(), strconv.Quote(page.Title))
+		}
+		if header != nil {
+			for _, name := range rule.Headers {
+				if header.Get(name) == "" {
+					violate("header", name+" present", "missing")
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// upgradeScheme returns the https version of an http URL when it
+// resolves over https, otherwise the URL unchanged. Each URL is probed
+// once per run.
+func (r *run) upgradeScheme(ctx context.Context, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "http" {
+		return rawURL
+	}
+	u.Scheme = "https"
+	if u.Port() == "80" {
+		u.Host = u.Hostname()
+	}
+	secure := u.String()
+
+	r.assetsMu.Lock()
+	ok, probed := r.upgrades[secure]
+	r.assetsMu.Unlock()
+	if !probed {
+		ok = r.probeHTTPS(ctx, secure)
+		r.assetsMu.Lock()
+		r.upgrades[secure] = ok
+		r.assetsMu.Unlock()
+	}
+
+	if ok {
+		return secure
+	}
+	return rawURL
+}
+
+// probeHTTPS reports whether a HEAD request for an https URL succeeds
+// without being redirected back to plain http
+func (r *run) probeHTTPS(ctx context.Context, secureURL string) bool {
+	req, err := http.NewRequestWithContext(r.conns.trace(ctx), http.MethodHead, secureURL, nil)
+	if err != nil {
+		return false
+	}
+	r.setHeaders(req, secureURL, "")
+	r.rateLimiter.Wait(ctx)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	resolves := resp.StatusCode < 400 || resp.StatusCode == http.StatusMethodNotAllowed
+	return resolves && resp.Request.URL.Scheme == "https"
+}
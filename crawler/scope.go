@@ -0,0 +1,90 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ScopeMode controls which hosts are considered part of the crawl
+type ScopeMode string
+
+const (
+	// ScopeHost only crawls the exact host of the start URL
+	ScopeHost ScopeMode = "host"
+	// ScopeDomain crawls every subdomain of the start URL's registrable domain
+	ScopeDomain ScopeMode = "domain"
+	// ScopeList crawls the start host plus a custom list of hosts
+	ScopeList ScopeMode = "list"
+)
+
+// ParseScopeMode validates a scope mode name
+func ParseScopeMode(s string) (ScopeMode, error) {
+	switch mode := ScopeMode(strings.ToLower(s)); mode {
+	case ScopeHost, ScopeDomain, ScopeList:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid scope %q (want host, domain or list)", s)
+	}
+}
+
+// Scope decides whether a URL belongs to the crawl
+type Scope struct {
+	Mode  ScopeMode
+	Hosts []string // used by ScopeList, "*.example.com" matches subdomains
+
+	host   string
+	domain string
+}
+
+// init binds the scope to the start URL
+func (s *Scope) init(startURL string) {
+	if s.Mode == "" {
+		s.Mode = ScopeHost
+	}
+
+	u, err := url.Parse(startURL)
+	if err != nil {
+		return
+	}
+	s.host = asciiHost(u.Hostname())
+
+	// Fall back to the host itself for IPs and unknown suffixes
+	s.domain = s.host
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(s.host); err == nil {
+		s.domain = domain
+	}
+}
+
+// Contains reports whether the URL is in scope
+func (s *Scope) Contains(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	host := asciiHost(u.Hostname())
+	if host == s.host {
+		return true
+	}
+
+	switch s.Mode {
+	case ScopeDomain:
+		return strings.HasSuffix(host, "."+s.domain)
+	case ScopeList:
+		for _, h := range s.Hosts {
+			h = strings.TrimSpace(h)
+			if wildcard := strings.TrimPrefix(h, "*."); wildcard != h {
+				wildcard = asciiHost(wildcard)
+				if host == wildcard || strings.HasSuffix(host, "."+wildcard) {
+					return true
+				}
+			} else if host == asciiHost(h) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package crawler
+
+import (
+	"fmt"
+	"log"
+)
+
+// LogLevel controls how much the crawler logs per page
+type LogLevel string
+
+const (
+	LogInfo  LogLevel = "info"  // every page
+	LogWarn  LogLevel = "warn"  // failed pages and warnings
+	LogError LogLevel = "error" // failed pages only
+)
+
+// logRank orders the levels, higher is quieter
+var logRank = map[LogLevel]int{LogInfo: 0, LogWarn: 1, LogError: 2}
+
+// ParseLogLevel validates a -log-level value, "" means info
+func ParseLogLevel(s string) (LogLevel, error) {
+	if s == "" {
+		return LogInfo, nil
+	}
+	level := LogLevel(s)
+	if _, ok := logRank[level]; !ok {
+		return "", fmt.Errorf("unknown log level %q (want info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// Settings are the crawler settings that can change during a crawl
+// without affecting what was already crawled
+type Settings struct {
+	RateLimit      int      `json:"rate"`             // requests per second
+	MaxURLLength   int      `json:"max_url_length"`   // skip longer URLs (0 disables)
+	MaxQueryParams int      `json:"max_query_params"` // skip URLs with more query parameters (0 disables)
+	LogLevel       LogLevel `json:"log_level"`
+}
+
+// Validate reports settings a crawl cannot run with
+func (s Settings) Validate() error {
+	if s.RateLimit < 1 {
+		return fmt.Errorf("rate must be at least 1, got %d", s.RateLimit)
+	}
+	if s.MaxURLLength < 0 || s.MaxQueryParams < 0 {
+		return fmt.Errorf("URL guards cannot be negative")
+	}
+	_, err := ParseLogLevel(string(s.LogLevel))
+	return err
+}
+
+// Settings returns the current changeable settings, with the rate of
+// the running crawl's limiter, which the keyboard may have changed
+// (thread-safe)
+func (c *Crawler) Settings() Settings {
+	c.settingsMu.RLock()
+	s := c.settings
+	c.settingsMu.RUnlock()
+
+	if limiter := c.Limiter(); limiter != nil {
+		s.RateLimit = limiter.Rate()
+	}
+	return s
+}
+
+// Apply changes the settings of the crawler and of its running crawl,
+// URLs already queued are not re-checked against new guards (thread-safe)
+func (c *Crawler) Apply(s Settings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if s.LogLevel == "" {
+		s.LogLevel = LogInfo
+	}
+	c.settingsMu.Lock()
+	c.settings = s
+	c.settingsMu.Unlock()
+
+	if limiter := c.Limiter(); limiter != nil {
+		limiter.SetRate(s.RateLimit)
+	}
+	return nil
+}
+
+// logf logs a page outcome unless the log level is quieter
+func (c *Crawler) logf(level LogLevel, format string, args ...any) {
+	if logRank[level] >= logRank[c.Settings().LogLevel] {
+		log.Printf(format, args...)
+	}
+}
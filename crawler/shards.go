@@ -0,0 +1,104 @@
+package crawler
+
+import (
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ringReplicas is how many points each shard gets on the hash ring,
+// more points spread hosts more evenly
+const ringReplicas = 64
+
+// shard is one part of the frontier. Every host belongs to exactly one
+// shard and every worker serves exactly one, so a host's URLs are
+// fetched in the order they were found and by a bounded set of workers.
+type shard struct {
+	frontier chan Job
+	spill    *spillQueue // jobs that did not fit in frontier
+}
+
+// newShards splits a frontier of size jobs into n shards
+func newShards(n, size int, spillDir string) []*shard {
+	shards := make([]*shard, n)
+	for i := range shards {
+		shards[i] = &shard{
+			frontier: make(chan Job, max(1, (size+n-1)/n)),
+			spill:    newSpillQueue(spillDir),
+		}
+	}
+	return shards
+}
+
+// hashRing assigns hosts to shards by consistent hashing
+type hashRing struct {
+	points []uint32 // sorted
+	shards []int    // shard of points[i]
+}
+
+func newHashRing(n int) *hashRing {
+	type point struct {
+		hash  uint32
+		shard int
+	}
+	points := make([]point, 0, n*ringReplicas)
+	for s := 0; s < n; s++ {
+		for v := 0; v < ringReplicas; v++ {
+			points = append(points, point{ringHash(strconv.Itoa(s) + "#" + strconv.Itoa(v)), s})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+
+	ring := &hashRing{points: make([]uint32, len(points)), shards: make([]int, len(points))}
+	for i, p := range points {
+		ring.points[i], ring.shards[i] = p.hash, p.shard
+	}
+	return ring
+}
+
+// shard returns the shard owning host, the first point clockwise of it
+func (h *hashRing) shard(host string) int {
+	hash := ringHash(host)
+	i := sort.Search(len(h.points), func(i int) bool { return h.points[i] >= hash })
+	if i == len(h.points) {
+		i = 0
+	}
+	return h.shards[i]
+}
+
+func ringHash(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// shardFor returns the shard of rawURL's host
+func (r *run) shardFor(rawURL string) *shard {
+	if len(r.shards) == 1 {
+		return r.shards[0]
+	}
+	host := ""
+	if u, err := url.Parse(rawURL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+	return r.shards[r.ring.shard(host)]
+}
+
+// workerShard returns the shard worker id serves
+func (r *run) workerShard(id int) *shard {
+	return r.shards[id%len(r.shards)]
+}
+
+// queuedJobs counts the jobs of every shard, in memory, spilled and
+// parked by circuit breakers. waiting is what keeps the crawl from
+// being idle: those jobs plus the ones workers took off a frontier
+// and are still fetching, throttling or queueing the links of.
+func (r *run) queuedJobs() (queued, waiting int) {
+	queued = r.parkedJobs()
+	for _, s := range r.shards {
+		queued += len(s.frontier) + s.spill.len()
+	}
+	return queued, queued + int(r.inFlight.Load())
+}
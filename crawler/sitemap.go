@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	maxSitemaps     = 50       // sitemap files read through sitemap indexes
+	maxSitemapBytes = 50 << 20 // size limit of one (uncompressed) sitemap
+)
+
+// sitemapDoc matches both <urlset> and <sitemapindex> documents
+type sitemapDoc struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// SitemapURL returns the conventional /sitemap.xml location of a site
+func SitemapURL(startURL string) (string, error) {
+	u, err := url.Parse(startURL)
+	if err != nil {
+		return "", err
+	}
+	return u.Scheme + "://" + u.Host + "/sitemap.xml", nil
+}
+
+// Sitemap reads a sitemap, following sitemap indexes, and returns its
+// URLs normalized the same way as crawled URLs
+func (c *Crawler) Sitemap(ctx context.Context, sitemapURL string) ([]string, error) {
+	seen := make(map[string]bool)
+	pending := []string{sitemapURL}
+	var urls []string
+
+	for len(pending) > 0 && len(seen) < maxSitemaps {
+		next := pending[0]
+		pending = pending[1:]
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+
+		doc, err := c.fetchSitemap(ctx, next)
+		if err != nil {
+			if next == sitemapURL {
+				return nil, err
+			}
+			continue // a broken child sitemap shouldn't lose the rest
+		}
+		for _, s := range doc.Sitemaps {
+			pending = append(pending, strings.TrimSpace(s.Loc))
+		}
+		for _, u := range doc.URLs {
+			parsed, err := url.Parse(strings.TrimSpace(u.Loc))
+			if err != nil {
+				continue
+			}
+			if normalized, ok := c.normalizeURL(parsed); ok {
+				urls = append(urls, normalized)
+			}
+		}
+	}
+	return urls, nil
+}
+
+// fetchSitemap downloads and decodes one sitemap file
+func (c *Crawler) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemapDoc, error) {
+	resp, err := c.fetch(ctx, sitemapURL, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer drainClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sitemap %s: status %d", sitemapURL, resp.StatusCode)
+	}
+
+	var body io.Reader = resp.Body
+	if strings.HasSuffix(sitemapURL, ".gz") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	doc := &sitemapDoc{}
+	if err := xml.NewDecoder(io.LimitReader(body, maxSitemapBytes)).Decode(doc); err != nil {
+		return nil, fmt.Errorf("sitemap %s: %w", sitemapURL, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,140 @@
+package crawler
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"gocrawler/events"
+	"gocrawler/storage"
+)
+
+// DefaultThrottleMaxDelay caps the pause between two requests to a host
+// slowed down by the adaptive throttle
+const DefaultThrottleMaxDelay = 10 * time.Second
+
+const (
+	throttleWindow = 20                     // recent response times a host's p95 is taken over
+	throttleEvery  = 10                     // responses between two adjustments of a host's delay
+	throttleStep   = 250 * time.Millisecond // first delay of a host turning slow
+)
+
+// hostThrottle is the adaptive politeness state of one host
+type hostThrottle struct {
+	samples []time.Duration // last throttleWindow response times
+	next    int             // oldest sample, overwritten next
+	count   int             // responses since the last adjustment
+	delay   time.Duration   // pause between two requests
+	nextAt  time.Time       // earliest start of the next request
+}
+
+// throttles slows hosts down while their p95 response time is above
+// threshold, a sign the crawl loads them, and speeds them up again once
+// it fell below half of it (thread-safe)
+type throttles struct {
+	threshold time.Duration // 0 disables
+	maxDelay  time.Duration
+	hosts     map[string]*hostThrottle
+	mu        sync.Mutex
+}
+
+func newThrottles(threshold, maxDelay time.Duration) *throttles {
+	return &throttles{threshold: threshold, maxDelay: maxDelay, hosts: make(map[string]*hostThrottle)}
+}
+
+// p95 returns the 95th percentile of durations
+func p95(durations []time.Duration) time.Duration {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	return sorted[(len(sorted)*95-1)/100]
+}
+
+// throttleWait blocks until the job's host may be sent its next request,
+// reserving the slot after it for the next caller
+func (r *run) throttleWait(ctx context.Context, rawURL string) {
+	t := r.throttles
+	if t == nil || t.threshold <= 0 {
+		return
+	}
+
+	host := breakerHost(rawURL)
+	t.mu.Lock()
+	h := t.hosts[host]
+	if h == nil || h.delay == 0 {
+		t.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	start := now
+	if h.nextAt.After(now) {
+		start = h.nextAt
+	}
+	h.nextAt = start.Add(h.delay)
+	t.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		r.tracef("host %s is throttled, waiting %dms", host, wait.Milliseconds())
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// observeLatency feeds a response time to its host's throttle. Every
+// throttleEvery responses the delay is doubled while the p95 is above
+// the threshold, halved once it is below half of it, and the point is
+// published for the host's latency curve.
+func (r *run) observeLatency(rawURL string, took time.Duration) {
+	t := r.throttles
+	if t == nil || t.threshold <= 0 {
+		return
+	}
+
+	host := breakerHost(rawURL)
+	t.mu.Lock()
+	h := t.hosts[host]
+	if h == nil {
+		h = &hostThrottle{}
+		t.hosts[host] = h
+	}
+	if len(h.samples) < throttleWindow {
+		h.samples = append(h.samples, took)
+	} else {
+		h.samples[h.next] = took
+		h.next = (h.next + 1) % throttleWindow
+	}
+	h.count++
+	if h.count < throttleEvery {
+		t.mu.Unlock()
+		return
+	}
+	h.count = 0
+	latency := p95(h.samples)
+	prev := h.delay
+	switch {
+	case latency > t.threshold:
+		h.delay = min(max(2*h.delay, throttleStep), t.maxDelay)
+	case latency < t.threshold/2 && h.delay > 0:
+		if h.delay /= 2; h.delay < throttleStep {
+			h.delay = 0
+		}
+	}
+	delay := h.delay
+	t.mu.Unlock()
+
+	switch {
+	case delay > prev:
+		log.Printf("🐢 %s p95 at %dms (above %dms), one request every %s", host, latency.Milliseconds(), t.threshold.Milliseconds(), delay)
+	case delay == 0 && prev > 0:
+		log.Printf("🐇 %s p95 back to %dms, throttle lifted", host, latency.Milliseconds())
+	}
+	r.bus.Publish(events.Event{Type: events.LatencySampled, URL: host, Latency: &storage.LatencySample{
+		P95:   float64(latency.Microseconds()) / 1000,
+		Delay: float64(delay.Milliseconds()),
+	}})
+}
@@ -0,0 +1,134 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ClientCert is a client certificate presented to hosts requiring mTLS
+type ClientCert struct {
+	Host        string // hostname or host:port, empty for every host
+	Certificate tls.Certificate
+}
+
+// LoadClientCert parses a "[host=]cert.pem[,key.pem]" flag value and
+// loads the key pair. Without a key file the certificate file must also
+// contain the private key.
+func LoadClientCert(spec string) (ClientCert, error) {
+	var cert ClientCert
+	if host, files, ok := strings.Cut(spec, "="); ok && hostPrefix.MatchString(host) {
+		cert.Host, spec = host, files
+	}
+	certFile, keyFile, ok := strings.Cut(spec, ",")
+	if !ok {
+		keyFile = certFile
+	}
+
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return cert, fmt.Errorf("loading client certificate %s: %w", certFile, err)
+	}
+	cert.Certificate = pair
+	return cert, nil
+}
+
+// LoadCABundle returns the system roots plus the PEM certificates in file
+func LoadCABundle(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// newTransport builds the crawler transport. Hosts with their own client
+// certificate get a separate transport (and connection pool). With a
+// proxy pool, requests rotate over its proxies. Received bytes are
+// counted in meter.
+func newTransport(cfg Config, proxies *ProxyPool, meter *bandwidth) http.RoundTripper {
+	// a custom TLS config or dialer turns HTTP/2 off unless forced
+	base := &http.Transport{
+		ForceAttemptHTTP2:   true,
+		MaxIdleConnsPerHost: cfg.Workers,
+		TLSClientConfig: &tls.Config{
+			RootCAs:            cfg.RootCAs,
+			InsecureSkipVerify: cfg.InsecureTLS,
+		},
+	}
+	base.DialContext = (&net.Dialer{}).DialContext
+	if len(cfg.Resolve) > 0 {
+		base.DialContext = resolvingDialer(cfg.Resolve)
+	}
+	base.DialContext = meter.dialer(base.DialContext)
+
+	hosts := make(map[string]http.RoundTripper)
+	for _, cert := range cfg.ClientCerts {
+		if cert.Host == "" {
+			base.TLSClientConfig.Certificates = []tls.Certificate{cert.Certificate}
+			continue
+		}
+		t := base.Clone()
+		t.TLSClientConfig.Certificates = []tls.Certificate{cert.Certificate}
+		hosts[strings.ToLower(cert.Host)] = t
+	}
+	var transport http.RoundTripper = base
+	if len(hosts) > 0 {
+		transport = &hostTransport{base: base, hosts: hosts}
+	}
+	if proxies != nil {
+		base.Proxy = proxyFor
+		for _, t := range hosts {
+			t.(*http.Transport).Proxy = proxyFor
+		}
+		transport = &proxyTransport{base: transport, pool: proxies}
+	}
+	if len(cfg.Credentials) > 0 {
+		auth := &authTransport{base: transport, hosts: make(map[string]Credentials)}
+		for _, creds := range cfg.Credentials {
+			auth.hosts[strings.ToLower(creds.Host)] = creds
+			if creds.OAuth2 != nil {
+				creds.OAuth2.useTransport(transport)
+			}
+		}
+		transport = auth
+	}
+	if cfg.VCR != VCROff {
+		transport = &vcrTransport{base: transport, mode: cfg.VCR, dir: cfg.VCRDir}
+	}
+	return transport
+}
+
+// hostTransport routes requests to per-host transports
+type hostTransport struct {
+	base  http.RoundTripper
+	hosts map[string]http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt, ok := lookupHost(t.hosts, req); ok {
+		return rt.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// lookupHost finds the entry for a request's host:port, then its hostname
+func lookupHost[T any](hosts map[string]T, req *http.Request) (T, bool) {
+	if v, ok := hosts[strings.ToLower(req.URL.Host)]; ok {
+		return v, true
+	}
+	v, ok := hosts[strings.ToLower(req.URL.Hostname())]
+	return v, ok
+}
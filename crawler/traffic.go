@@ -0,0 +1,72 @@
+package crawler
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// logLine matches the request and status of Common/Combined Log Format lines
+var logLine = regexp.MustCompile(`"([A-Z]+) (\S+) [^"]*" (\d{3})`)
+
+// staticExts are requests that are not pages and never linked with <a>
+var staticExts = map[string]bool{
+	".css": true, ".js": true, ".png": true, ".jpg": true, ".jpeg": true, ".gif": true,
+	".svg": true, ".ico": true, ".webp": true, ".woff": true, ".woff2": true, ".map": true,
+}
+
+// LoadTraffic reads an access log or a plain list of URLs/paths and
+// returns hits per normalized URL. Relative paths resolve against
+// startURL; failed, non-GET and static asset requests are skipped.
+func (c *Crawler) LoadTraffic(filename, startURL string) (map[string]int, error) {
+	base, err := url.Parse(startURL)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hits := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		target := requestTarget(strings.TrimSpace(scanner.Text()))
+		if target == "" {
+			continue
+		}
+		ref, err := url.Parse(target)
+		if err != nil || staticExts[strings.ToLower(path.Ext(ref.Path))] {
+			continue
+		}
+		if normalized, ok := c.normalizeURL(base.ResolveReference(ref)); ok {
+			hits[normalized]++
+		}
+	}
+	return hits, scanner.Err()
+}
+
+// requestTarget extracts the requested URL from a log or list line
+func requestTarget(line string) string {
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	if m := logLine.FindStringSubmatch(line); m != nil {
+		status, _ := strconv.Atoi(m[3])
+		if m[1] != "GET" || status >= 400 {
+			return ""
+		}
+		return m[2]
+	}
+	if strings.HasPrefix(line, "/") || strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+		return strings.Fields(line)[0]
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package crawler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VCRMode selects whether responses are recorded to or replayed from
+// a fixture directory
+type VCRMode string
+
+const (
+	// VCROff sends requests to the network
+	VCROff VCRMode = ""
+	// VCRRecord sends requests to the network and saves every response
+	VCRRecord VCRMode = "record"
+	// VCRReplay answers requests from saved responses only, requests
+	// without a fixture fail
+	VCRReplay VCRMode = "replay"
+)
+
+// ParseVCRMode validates a -vcr value
+func ParseVCRMode(s string) (VCRMode, error) {
+	switch mode := VCRMode(s); mode {
+	case VCROff, VCRRecord, VCRReplay:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown VCR mode %q (want record or replay)", s)
+}
+
+// fixture is the metadata of a recorded response, its body is kept
+// next to it so fixtures can be edited by hand
+type fixture struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Recorded time.Time   `json:"recorded"`
+}
+
+// vcrTransport records or replays responses, one <dir>/<host>/<key>.json
+// and .body pair per method and URL. Failed requests aren't recorded.
+type vcrTransport struct {
+	base http.RoundTripper
+	mode VCRMode
+	dir  string
+}
+
+// fixturePath returns the path of a request's fixture without extension
+func (t *vcrTransport) fixturePath(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	host := strings.NewReplacer(":", "_", "/", "_").Replace(req.URL.Host)
+	return filepath.Join(t.dir, host, hex.EncodeToString(sum[:12]))
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *vcrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := t.fixturePath(req)
+	if t.mode == VCRReplay {
+		return t.replay(req, path)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.save(path, fixture{Method: req.Method, URL: req.URL.String(), Status: resp.StatusCode, Header: resp.Header, Recorded: time.Now()}, body); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", req.URL, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// save writes a fixture, renaming complete files into place so an
+// interrupted recording leaves no partial fixture behind
+func (t *vcrTransport) save(path string, f fixture, body []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	meta, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	for ext, data := range map[string][]byte{".body": body, ".json": meta} {
+		tmp := path + ext + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, path+ext); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay answers a request from its fixture
+func (t *vcrTransport) replay(req *http.Request, path string) (*http.Response, error) {
+	meta, err := os.ReadFile(path + ".json")
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	} else if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(meta, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s.json: %w", path, err)
+	}
+	body, err := os.ReadFile(path + ".body")
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
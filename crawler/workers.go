@@ -0,0 +1,101 @@
+package crawler
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// throughputWindow is the period recent throughput is measured over
+const throughputWindow = time.Minute
+
+// WorkerStatus is what one worker of the running crawl is doing
+type WorkerStatus struct {
+	ID              int    `json:"id"`
+	Shard           int    `json:"shard"`          // of the frontier this worker serves
+	URL             string `json:"url,omitempty"`  // being processed, "" while idle
+	Host            string `json:"host,omitempty"` // of URL
+	BusyMS          int64  `json:"busy_ms"`        // time spent on URL so far
+	Pages           int    `json:"pages"`          // processed since the crawl started
+	PagesLastMinute int    `json:"pages_last_minute"`
+}
+
+// workerState is the bookkeeping behind WorkerStatus
+type workerState struct {
+	url   string
+	since time.Time
+	pages int
+	done  []time.Time // completions within throughputWindow
+}
+
+// workerTable tracks the workers of a run (thread-safe)
+type workerTable struct {
+	states []workerState
+	shards int
+	mu     sync.Mutex
+}
+
+func newWorkerTable(n, shards int) *workerTable {
+	return &workerTable{states: make([]workerState, n), shards: shards}
+}
+
+// busy marks worker id as processing rawURL
+func (t *workerTable) busy(id int, rawURL string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.states[id].url = rawURL
+	t.states[id].since = time.Now()
+}
+
+// idle marks worker id as done with its URL
+func (t *workerTable) idle(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &t.states[id]
+	s.url = ""
+	s.pages++
+	s.done = append(recent(s.done, time.Now()), time.Now())
+}
+
+// recent drops completions older than throughputWindow
+func recent(done []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(done) && now.Sub(done[i]) > throughputWindow {
+		i++
+	}
+	return done[i:]
+}
+
+// snapshot reports every worker
+func (t *workerTable) snapshot() []WorkerStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	list := make([]WorkerStatus, len(t.states))
+	for id := range t.states {
+		s := &t.states[id]
+		s.done = recent(s.done, now)
+		list[id] = WorkerStatus{ID: id, Shard: id % t.shards, Pages: s.pages, PagesLastMinute: len(s.done)}
+		if s.url != "" {
+			list[id].URL = s.url
+			list[id].BusyMS = now.Sub(s.since).Milliseconds()
+			if u, err := url.Parse(s.url); err == nil {
+				list[id].Host = u.Host
+			}
+		}
+	}
+	return list
+}
+
+// Workers reports the workers of the running crawl, or nil between crawls
+func (c *Crawler) Workers() []WorkerStatus {
+	c.limiterMu.Lock()
+	table := c.workerTable
+	c.limiterMu.Unlock()
+
+	if table == nil {
+		return nil
+	}
+	return table.snapshot()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"gocrawler/crawler"
+	"gocrawler/events"
+	"gocrawler/jobs"
+	"gocrawler/storage"
+	"gocrawler/web"
+)
+
+// daemon runs crawls submitted to the job queue
+type daemon struct {
+	base            crawler.Config
+	maxWorkers      int // per-job caps on submitted requests
+	maxRate         int
+	history         *storage.History
+	exportConfig    storage.ExportConfig
+	exportOpts      exportOptions
+	nameTemplate    string
+	checkpointEvery time.Duration
+	checkpointPages int
+	costPerGB       float64
+	retention       storage.Retention // of finished jobs
+}
+
+// config applies a request's overrides and the per-job caps
+func (d *daemon) config(req jobs.Request) (crawler.Config, error) {
+	cfg := d.base
+	if req.Depth > 0 {
+		cfg.MaxDepth = req.Depth
+	}
+	if req.Workers > 0 {
+		cfg.Workers = req.Workers
+	}
+	if req.Rate > 0 {
+		cfg.RateLimit = req.Rate
+	}
+	if req.MaxPages > 0 {
+		cfg.MaxPages = req.MaxPages
+	}
+	if req.Scope != "" {
+		mode, err := crawler.ParseScopeMode(req.Scope)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Scope = crawler.Scope{Mode: mode, Hosts: d.base.Scope.Hosts}
+	}
+	if req.Grep != "" {
+		grep, err := crawler.ParseGrep(req.Grep, false)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Grep = grep
+	}
+	cfg.Keywords = cfg.Keywords || req.Keywords
+	cfg.Articles = cfg.Articles || req.Articles
+
+	cfg.Workers = min(cfg.Workers, d.maxWorkers)
+	cfg.RateLimit = min(cfg.RateLimit, d.maxRate)
+	return cfg, nil
+}
+
+// runJob is the jobs.RunFunc executing one crawl into its own results,
+// exported under <output-dir>/<tenant>/<name>_<job id>_*
+func (d *daemon) runJob(ctx context.Context, job jobs.Job, results *storage.Results) (string, error) {
+	cfg, err := d.config(job.Request)
+	if err != nil {
+		return "", err
+	}
+
+	opts := d.exportOpts
+	opts.grep = cfg.Grep != nil
+	opts.keywords = opts.keywords || job.Request.Keywords
+	opts.articles = opts.articles || job.Request.Articles
+	prefix, err := renderPrefix(d.nameTemplate, job.Request.URL, job.StartedAt)
+	if err != nil {
+		return "", err
+	}
+	opts.prefix = prefix + "_" + job.ID
+	if tenant := filepath.Base(job.Request.Tenant); job.Request.Tenant != "" && tenant != "." && tenant != ".." {
+		opts.dir = filepath.Join(opts.dir, tenant)
+		if err := os.MkdirAll(opts.dir, 0755); err != nil {
+			return "", err
+		}
+	}
+
+	log.Printf("🚀 [Job %s] Starting crawl of %s", job.ID, job.Request.URL)
+	results.SetExportConfig(d.exportConfig)
+	results.SetBandwidthPrice(d.costPerGB)
+	bus := events.NewBus()
+	events.Record(bus, results)
+
+	c := crawler.New(cfg)
+
+	stopCheckpoints := startCheckpoints(ctx, results, opts, d.checkpointEvery, d.checkpointPages)
+
+	c.Crawl(ctx, job.Request.URL, bus)
+	stopCheckpoints()
+
+	finishRun(results, d.history, job.Request.URL, opts)
+	log.Printf("✅ [Job %s] Finished, results in %s", job.ID, opts.path("results.json"))
+	return opts.exports(), nil
+}
+
+// runDaemon serves the dashboard and job API until SIGINT/SIGTERM, then
+// stops running jobs, exports their partial results and shuts down
+func runDaemon(d *daemon, srv *web.Server, jobsFile, profilesFile string, maxJobs int) {
+	manager, err := jobs.NewManager(jobsFile, maxJobs, d.runJob)
+	if err != nil {
+		log.Fatalf("Error loading job queue: %v", err)
+	}
+	if !d.retention.IsZero() {
+		manager.SetRetention(d.retention)
+		if pruned, err := manager.Prune(d.retention); err != nil {
+			log.Printf("Error pruning job queue: %v", err)
+		} else if pruned > 0 {
+			log.Printf("🧹 Pruned %d finished jobs from %s, keeping %s", pruned, jobsFile, d.retention)
+		}
+	}
+	profiles, err := jobs.LoadProfiles(profilesFile)
+	if err != nil {
+		log.Fatalf("Error loading crawl profiles: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go manager.Start(ctx)
+
+	srv.SetJobs(manager)
+	srv.SetProfiles(profiles)
+	go func() {
+		if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Web server error: %v", err)
+		}
+	}()
+	log.Printf("🛰️  Daemon mode: submit crawls with POST /api/jobs {\"url\": \"https://example.com\"} (%d concurrent)", maxJobs)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	sig := <-sigChan
+	log.Printf("🛑 %s received, shutting down (again to force quit)...", sig)
+	go forceQuit(sigChan, func() {}, nil)
+
+	cancel()
+	manager.Wait()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down web server: %v", err)
+	}
+	log.Println("👋 Goodbye!")
+}
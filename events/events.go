@@ -0,0 +1,165 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gocrawler/storage"
+)
+
+// Type identifies what happened during a crawl
+type Type string
+
+const (
+	// PageCrawled is published after a page was fetched and parsed
+	PageCrawled Type = "page_crawled"
+	// PageFailed is published when fetching or parsing a page failed
+	PageFailed Type = "page_failed"
+	// URLDiscovered is published for every in-scope URL linked from a page,
+	// whether or not the depth limit lets it be queued
+	URLDiscovered Type = "url_discovered"
+	// URLSkipped is published when a guard refuses to queue a URL
+	URLSkipped Type = "url_skipped"
+	// Progress is published periodically with the frontier size
+	Progress Type = "progress"
+	// CrawlFinished is published once all workers have stopped
+	CrawlFinished Type = "crawl_finished"
+	// AssetChecked is published after a favicon, manifest or og:image was fetched
+	AssetChecked Type = "asset_checked"
+	// EndpointFound is published for URL-like strings found in JavaScript
+	EndpointFound Type = "endpoint_found"
+	// CertificateSeen is published for the first TLS response of each host,
+	// with the host as URL
+	CertificateSeen Type = "certificate_seen"
+	// BreakerTripped is published when a host's circuit breaker opens,
+	// with the host as URL
+	BreakerTripped Type = "breaker_tripped"
+	// RuleViolated is published for every failed check of a page rule
+	RuleViolated Type = "rule_violated"
+	// LatencySampled is published whenever the adaptive throttle
+	// reconsiders a host's delay, with the host as URL
+	LatencySampled Type = "latency_sampled"
+	// ExternalChecked is published after an out-of-scope link target
+	// was fetched (-check-external)
+	ExternalChecked Type = "external_checked"
+)
+
+// Event carries the data of a crawl event, unused fields are zero
+type Event struct {
+	Type       Type
+	URL        string
+	Depth      int
+	Parent     string                 // URLDiscovered, URLSkipped: page the URL was found on, after redirects
+	Reason     string                 // URLSkipped
+	Page       *storage.Page          // PageCrawled, PageFailed
+	Err        error                  // PageFailed
+	Asset      *storage.Asset         // AssetChecked
+	Endpoint   *storage.Endpoint      // EndpointFound
+	Violation  *storage.RuleViolation // RuleViolated
+	Latency    *storage.LatencySample // LatencySampled
+	External   *storage.ExternalLink  // ExternalChecked
+	Names      []string               // CertificateSeen: DNS names of the leaf certificate
+	Queued     int                    // Progress: jobs waiting in the queue
+	Visited    int                    // Progress: URLs claimed by workers
+	Elapsed    time.Duration          // Progress, CrawlFinished
+	Downloaded map[string]int64       // Progress, CrawlFinished: bytes received per host so far
+	Occurred   time.Time
+}
+
+// Handler receives published events
+type Handler func(Event)
+
+// Bus delivers events to subscribers (thread-safe)
+type Bus struct {
+	subs map[Type][]Handler
+	mu   sync.RWMutex
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[Type][]Handler),
+	}
+}
+
+// Subscribe registers a handler for the given event types
+func (b *Bus) Subscribe(h Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range types {
+		b.subs[t] = append(b.subs[t], h)
+	}
+}
+
+// Publish delivers an event synchronously, in subscription order.
+// Handlers run on the publishing worker, so slow work belongs in a goroutine.
+func (b *Bus) Publish(e Event) {
+	if e.Occurred.IsZero() {
+		e.Occurred = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := b.subs[e.Type]
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(e)
+	}
+}
+
+// Record subscribes results to the events it stores
+func Record(b *Bus, results *storage.Results) {
+	b.Subscribe(func(e Event) {
+		switch e.Type {
+		case PageCrawled:
+			results.AddPage(e.Page, nil)
+		case PageFailed:
+			results.AddPage(e.Page, e.Err)
+		case Progress:
+			results.SetProgress(e.Queued, e.Visited, e.Elapsed)
+			results.SetDownloaded(e.Downloaded)
+		case CrawlFinished:
+			results.SetDuration(e.Elapsed)
+			results.SetDownloaded(e.Downloaded)
+		case AssetChecked:
+			results.AddAsset(e.Asset)
+		case URLSkipped:
+			results.AddSkipped(&storage.Skipped{URL: e.URL, Reason: e.Reason, Source: e.Parent})
+		case EndpointFound:
+			results.AddEndpoint(e.Endpoint)
+		case CertificateSeen:
+			results.AddCertNames(e.URL, e.Names)
+		case BreakerTripped:
+			results.AddBreakerTrip(e.URL)
+		case RuleViolated:
+			results.AddViolation(e.Violation)
+		case LatencySampled:
+			sample := *e.Latency
+			sample.At = e.Occurred
+			results.AddLatencySample(e.URL, sample)
+		case ExternalChecked:
+			results.AddExternal(e.External)
+		}
+	}, PageCrawled, PageFailed, Progress, CrawlFinished, AssetChecked, URLSkipped, EndpointFound, CertificateSeen, BreakerTripped, RuleViolated, LatencySampled, ExternalChecked)
+}
+
+// Snapshot saves the text of crawled pages in store and records in
+// results the pages whose text changed since their last snapshot
+func Snapshot(b *Bus, store *storage.SnapshotStore, results *storage.Results) {
+	b.Subscribe(func(e Event) {
+		// 304s carry no new text, their last snapshot still holds
+		if e.Page.NotModified {
+			return
+		}
+		change, err := store.Save(e.Page.URL, e.Page.Text)
+		if err != nil {
+			log.Printf("Error saving text snapshot of %s: %v", e.Page.URL, err)
+			return
+		}
+		if change != nil {
+			results.AddTextChange(change)
+		}
+	}, PageCrawled)
+}
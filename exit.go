@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gocrawler/storage"
+)
+
+// Process exit codes, log.Fatal's 1 covers invalid flags and setup errors
+const (
+	exitOK      = 0
+	exitErrors  = 2 // crawl completed but too many pages failed
+	exitAborted = 3 // crawl stopped by a signal or the q key, init by closed input
+)
+
+// exitCode picks the exit status of a finished crawl, threshold is the
+// share of failed pages, in percent, that is still tolerated (negative
+// tolerates any)
+func exitCode(stats storage.Stats, aborted bool, threshold float64) int {
+	if aborted {
+		return exitAborted
+	}
+	if threshold >= 0 && stats.TotalPages > 0 && float64(stats.FailCount)/float64(stats.TotalPages)*100 > threshold {
+		return exitErrors
+	}
+	return exitOK
+}
+
+// forceQuit exits immediately on the next signal, so a second Ctrl+C
+// skips a slow shutdown or export. It returns once stop is closed.
+func forceQuit(sigChan <-chan os.Signal, restoreTerminal func(), stop <-chan struct{}) {
+	select {
+	case <-sigChan:
+		restoreTerminal()
+		fmt.Println("\n⛔ Forced exit")
+		os.Exit(exitAborted)
+	case <-stop:
+	}
+}
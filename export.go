@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gocrawler/report"
+	"gocrawler/search"
+	"gocrawler/storage"
+)
+
+// exportOptions controls which reports are written and where
+type exportOptions struct {
+	top        int
+	alternates bool
+	grep       bool
+	assets     bool
+	ogImages   bool
+	sitemap    bool
+	traffic    bool
+	endpoints  bool
+	recon      bool
+	subdomains bool
+	snapshots  bool
+	articles   bool
+	keywords   bool
+	sitemapXML bool
+	rules      bool
+	migration  bool
+	dir        string // output directory
+	prefix     string // rendered filename template
+	ext        string // compression extension appended to every file
+}
+
+// path builds the export filename for a report, e.g. "links.csv"
+func (o exportOptions) path(report string) string {
+	return filepath.Join(o.dir, o.prefix+"_"+report+o.ext)
+}
+
+// plainPath is path without the compression extension, for files read
+// by other tools such as the JUnit report
+func (o exportOptions) plainPath(report string) string {
+	return filepath.Join(o.dir, o.prefix+"_"+report)
+}
+
+// exports is the directory and name prefix of the files, recorded so
+// that retention removes them. It is absolute, pruning may run from
+// another working directory.
+func (o exportOptions) exports() string {
+	prefix, err := filepath.Abs(filepath.Join(o.dir, o.prefix))
+	if err != nil {
+		return filepath.Join(o.dir, o.prefix)
+	}
+	return prefix
+}
+
+// nameData is available to -name templates
+type nameData struct {
+	Host      string // seed host, ports replaced by "_"
+	Timestamp string // run start as 20060102-150405
+	Date      string // run start as 2006-01-02
+}
+
+// renderPrefix renders the filename template for this run, which must
+// name files inside -output-dir
+func renderPrefix(tmpl, startURL string, started time.Time) (string, error) {
+	t, err := template.New("name").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	data := nameData{
+		Timestamp: started.Format("20060102-150405"),
+		Date:      started.Format("2006-01-02"),
+	}
+	if u, err := url.Parse(startURL); err == nil {
+		data.Host = strings.ReplaceAll(u.Host, ":", "_")
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	prefix := sb.String()
+	if strings.ContainsAny(prefix, `/\`) || prefix == "." || prefix == ".." {
+		return "", fmt.Errorf("%q is not a file name, use -output-dir for directories", prefix)
+	}
+	return prefix, nil
+}
+
+// exportResults writes every report, logging failures
+func exportResults(results *storage.Results, opts exportOptions) {
+	if err := results.ExportJSON(opts.path("results.json")); err != nil {
+		log.Printf("Error exporting JSON: %v", err)
+	}
+	if err := results.ExportCSV(opts.path("results.csv")); err != nil {
+		log.Printf("Error exporting CSV: %v", err)
+	}
+	if err := results.ExportLinksCSV(opts.path("links.csv")); err != nil {
+		log.Printf("Error exporting links CSV: %v", err)
+	}
+	if err := results.ExportTopCSV(opts.path("slowest.csv"), results.Slowest(opts.top)); err != nil {
+		log.Printf("Error exporting slowest pages CSV: %v", err)
+	}
+	if err := results.ExportTopCSV(opts.path("largest.csv"), results.Largest(opts.top)); err != nil {
+		log.Printf("Error exporting largest pages CSV: %v", err)
+	}
+	if err := results.ExportAnchorsCSV(opts.path("anchors.csv")); err != nil {
+		log.Printf("Error exporting anchors CSV: %v", err)
+	}
+	if err := results.ExportEmbedsCSV(opts.path("embeds.csv")); err != nil {
+		log.Printf("Error exporting embeds CSV: %v", err)
+	}
+	if err := results.ExportChainsCSV(opts.path("redirects.csv")); err != nil {
+		log.Printf("Error exporting redirects CSV: %v", err)
+	}
+	if err := results.ExportSkippedCSV(opts.path("skipped.csv")); err != nil {
+		log.Printf("Error exporting skipped URLs CSV: %v", err)
+	}
+	if err := results.ExportHTTPLinksCSV(opts.path("http_links.csv")); err != nil {
+		log.Printf("Error exporting http links CSV: %v", err)
+	}
+	if err := results.ExportBrokenLinksCSV(opts.path("broken_links.csv")); err != nil {
+		log.Printf("Error exporting broken links CSV: %v", err)
+	}
+	if err := results.ExportDuplicatesCSV(opts.path("duplicates.csv")); err != nil {
+		log.Printf("Error exporting duplicates CSV: %v", err)
+	}
+	if opts.alternates {
+		if err := results.ExportAlternatesCSV(opts.path("alternates.csv")); err != nil {
+			log.Printf("Error exporting alternates CSV: %v", err)
+		}
+	}
+	if opts.assets {
+		if err := results.ExportAssetsCSV(opts.path("assets.csv")); err != nil {
+			log.Printf("Error exporting assets CSV: %v", err)
+		}
+	}
+	if opts.ogImages {
+		if err := results.ExportOGImagesCSV(opts.path("og_images.csv")); err != nil {
+			log.Printf("Error exporting og:image CSV: %v", err)
+		}
+	}
+	if opts.sitemap {
+		if err := results.ExportSitemapGapsCSV(opts.path("sitemap_gaps.csv")); err != nil {
+			log.Printf("Error exporting sitemap gaps CSV: %v", err)
+		}
+	}
+	if opts.traffic {
+		if err := results.ExportTrafficOrphansCSV(opts.path("traffic_orphans.csv")); err != nil {
+			log.Printf("Error exporting traffic orphans CSV: %v", err)
+		}
+	}
+	if opts.endpoints {
+		if err := results.ExportEndpointsCSV(opts.path("endpoints.csv")); err != nil {
+			log.Printf("Error exporting endpoints CSV: %v", err)
+		}
+	}
+	if opts.recon {
+		if err := results.ExportReconCSV(opts.path("recon.csv")); err != nil {
+			log.Printf("Error exporting recon CSV: %v", err)
+		}
+	}
+	if opts.subdomains {
+		if err := results.ExportSubdomainsCSV(opts.path("subdomains.csv")); err != nil {
+			log.Printf("Error exporting subdomains CSV: %v", err)
+		}
+	}
+	if opts.snapshots {
+		if err := results.ExportTextChangesCSV(opts.path("text_changes.csv")); err != nil {
+			log.Printf("Error exporting text changes CSV: %v", err)
+		}
+	}
+	if opts.articles {
+		if err := results.ExportArticlesJSON(opts.path("articles.jsonl")); err != nil {
+			log.Printf("Error exporting articles: %v", err)
+		}
+	}
+	if opts.keywords {
+		if err := results.ExportKeywordsCSV(opts.path("keywords.csv"), opts.top); err != nil {
+			log.Printf("Error exporting keywords CSV: %v", err)
+		}
+	}
+	if opts.sitemapXML {
+		if n, err := results.ExportSitemapXML(opts.path("sitemap.xml")); err != nil {
+			log.Printf("Error exporting sitemap: %v", err)
+		} else if n == storage.MaxSitemapURLs {
+			log.Printf("⚠️  Sitemap truncated to %d URLs", n)
+		}
+	}
+	if opts.rules {
+		if err := results.ExportRulesCSV(opts.path("rules.csv")); err != nil {
+			log.Printf("Error exporting rules CSV: %v", err)
+		}
+	}
+	if opts.migration {
+		if err := results.ExportMigrationCSV(opts.path("migration.csv")); err != nil {
+			log.Printf("Error exporting migration CSV: %v", err)
+		}
+	}
+	if opts.grep {
+		if err := results.ExportMatchesCSV(opts.path("matches.csv")); err != nil {
+			log.Printf("Error exporting matches CSV: %v", err)
+		}
+	}
+}
+
+// writeReport renders the HTML audit report of a crawl
+func writeReport(results *storage.Results, opts exportOptions, tmpl *htmltemplate.Template, startURL string) {
+	if err := report.Write(opts.plainPath("report.html"), tmpl, report.NewData(results, startURL, opts.top)); err != nil {
+		log.Printf("Error writing HTML report: %v", err)
+	}
+}
+
+// exportSearch writes the search documents of a crawl and pushes them
+// when an engine URL is set
+func exportSearch(results *storage.Results, opts exportOptions, fields []search.Field, cfg search.Config) {
+	docs := search.Documents(results.GetPages(), fields)
+	path := opts.plainPath("search.jsonl")
+	if err := search.WriteJSONL(path, docs); err != nil {
+		log.Printf("Error writing search documents: %v", err)
+		return
+	}
+	log.Printf("🔎 %d search documents written to %s", len(docs), path)
+
+	if cfg.URL == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if err := search.Push(ctx, cfg, docs); err != nil {
+		log.Printf("Error pushing search documents to %s: %v", cfg.Engine, err)
+		return
+	}
+	log.Printf("🔎 %d documents pushed to %s index %s", len(docs), cfg.Engine, cfg.Index)
+}
+
+// pushAlgolia pushes the page records of a crawl to Algolia and deletes
+// the records of the same site from earlier runs
+func pushAlgolia(results *storage.Results, startURL string, started time.Time, cfg search.AlgoliaConfig) {
+	site := hostOf(startURL)
+	run := started.UTC().Format("20060102T150405Z")
+	records := search.AlgoliaRecords(results.GetPages(), site, run)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	deleted, err := search.PushAlgolia(ctx, cfg, records, site, run)
+	if err != nil {
+		log.Printf("Error pushing records to Algolia: %v", err)
+		return
+	}
+	log.Printf("🔎 %d records pushed to Algolia index %s, %d stale records deleted", len(records), cfg.Index, deleted)
+}
+
+// finishRun records a finished crawl in the history and exports it
+func finishRun(results *storage.Results, history *storage.History, startURL string, opts exportOptions) {
+	if history != nil {
+		run := storage.NewRunSummary(startURL, results.GetStats())
+		run.Exports = opts.exports()
+		if err := history.Add(run); err != nil {
+			log.Printf("Error saving run history: %v", err)
+		}
+	}
+	exportResults(results, opts)
+}
+
+// startCheckpoints runs checkpoint in the background until the returned
+// stop is called. stop waits for an export in progress, so a partial
+// one can't replace the final exports written after it.
+func startCheckpoints(ctx context.Context, results *storage.Results, opts exportOptions, interval time.Duration, pages int) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		checkpoint(ctx, results, opts, interval, pages)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// checkpoint writes partial exports every interval and/or every n pages
+// until ctx is cancelled, so a crash mid-crawl keeps most of the data
+func checkpoint(ctx context.Context, results *storage.Results, opts exportOptions, interval time.Duration, pages int) {
+	if interval <= 0 && pages <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	last := time.Now()
+	lastCount := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count := results.Count()
+			due := (interval > 0 && time.Since(last) >= interval) ||
+				(pages > 0 && count-lastCount >= pages)
+			if !due || count == lastCount {
+				continue
+			}
+
+			exportResults(results, opts)
+			log.Printf("💾 Checkpoint written (%d pages)", count)
+			last, lastCount = time.Now(), count
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package github publishes crawl audits to GitHub as commit statuses
+// and pull request comments
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the API of github.com, GitHub Enterprise servers
+// use https://<host>/api/v3
+const DefaultAPIURL = "https://api.github.com"
+
+// Commit status states
+const (
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// Config selects the repository and credentials
+type Config struct {
+	Token  string // personal access token or GITHUB_TOKEN with statuses/pull-requests write access
+	Repo   string // owner/name
+	APIURL string // defaults to DefaultAPIURL
+}
+
+// Client calls the GitHub REST API
+type Client struct {
+	token  string
+	repo   string
+	apiURL string
+	client *http.Client
+}
+
+// New creates a client for one repository
+func New(cfg Config) (*Client, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("missing GitHub token")
+	}
+	if owner, name, ok := strings.Cut(cfg.Repo, "/"); !ok || owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid GitHub repository %q (want owner/name)", cfg.Repo)
+	}
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{
+		token:  cfg.Token,
+		repo:   cfg.Repo,
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Status is a commit status shown next to the commit and on its pull requests
+type Status struct {
+	State       string `json:"state"`
+	Description string `json:"description"` // GitHub truncates beyond 140 characters
+	Context     string `json:"context"`
+	TargetURL   string `json:"target_url,omitempty"`
+}
+
+// SetStatus sets the status of a commit
+func (c *Client) SetStatus(ctx context.Context, sha string, status Status) error {
+	if len(status.Description) > 140 {
+		status.Description = status.Description[:137] + "..."
+	}
+	return c.post(ctx, fmt.Sprintf("/repos/%s/statuses/%s", c.repo, sha), status)
+}
+
+// Comment posts a comment on a pull request
+func (c *Client) Comment(ctx context.Context, pr int, body string) error {
+	return c.post(ctx, fmt.Sprintf("/repos/%s/issues/%d/comments", c.repo, pr), map[string]string{"body": body})
+}
+
+// post sends v as JSON to an API path
+func (c *Client) post(ctx context.Context, path string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("POST %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
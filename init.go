@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultConfigFile is written by the init wizard and read with -config
+const defaultConfigFile = "gocrawler.conf"
+
+// wizard asks questions on in and echoes prompts to out
+type wizard struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask prompts for a value, returning def for an empty answer and
+// io.EOF once the input is closed (Ctrl+D)
+func (w *wizard) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	if !w.in.Scan() {
+		fmt.Fprintln(w.out)
+		if err := w.in.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	if answer := strings.TrimSpace(w.in.Text()); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose asks until the answer is one of options
+func (w *wizard) choose(question string, options []string, def string) (string, error) {
+	for {
+		answer, err := w.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), def)
+		if err != nil {
+			return "", err
+		}
+		answer = strings.ToLower(answer)
+		for _, option := range options {
+			if answer == option {
+				return answer, nil
+			}
+		}
+		fmt.Fprintf(w.out, "  please answer one of %s\n", strings.Join(options, ", "))
+	}
+}
+
+// number asks until the answer is a positive integer
+func (w *wizard) number(question string, def int) (int, error) {
+	for {
+		answer, err := w.ask(question, strconv.Itoa(def))
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n > 0 {
+			return n, nil
+		}
+		fmt.Fprintln(w.out, "  please enter a positive number")
+	}
+}
+
+// seed asks until the answer is an absolute http(s) URL
+func (w *wizard) seed() (string, error) {
+	for {
+		answer, err := w.ask("Seed URL to start crawling from", "")
+		if err != nil {
+			return "", err
+		}
+		if !strings.Contains(answer, "://") && answer != "" {
+			answer = "https://" + answer
+		}
+		if u, err := url.Parse(answer); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+			return answer, nil
+		}
+		fmt.Fprintln(w.out, "  please enter an http or https URL, e.g. https://example.com")
+	}
+}
+
+// configWriter collects commented settings of a config file
+type configWriter struct {
+	b strings.Builder
+}
+
+// comment adds a comment line, or a blank line for ""
+func (c *configWriter) comment(text string) {
+	if text == "" {
+		c.b.WriteString("\n")
+		return
+	}
+	c.b.WriteString("# " + text + "\n")
+}
+
+// set adds a setting, quoting values that would not survive trimming
+func (c *configWriter) set(name, value string) {
+	if value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(&c.b, "%s = %s\n", name, value)
+}
+
+// initCommand is the first argument selecting the config wizard:
+//
+//	gocrawler init [file]
+const initCommand = "init"
+
+// runInit is the init subcommand: it asks for the seed URL, scope,
+// politeness and authentication, then writes a commented config file
+// for -config. The file name is the optional argument.
+func runInit(args []string) int {
+	file := defaultConfigFile
+	if len(args) > 0 {
+		file = args[0]
+	}
+	w := &wizard{in: bufio.NewScanner(os.Stdin), out: os.Stdout}
+
+	fmt.Printf("🧙 This writes a crawl configuration to %s, press Enter to keep [defaults].\n\n", file)
+	config, secret, err := w.config(file)
+	if errors.Is(err, errKeepConfig) {
+		return exitOK
+	}
+	if errors.Is(err, io.EOF) {
+		fmt.Println("init cancelled")
+		return exitAborted
+	}
+	if err != nil {
+		log.Fatalf("Error reading answers: %v", err)
+	}
+
+	// credentials make the file as sensitive as a password
+	mode := os.FileMode(0644)
+	if secret {
+		mode = 0600
+	}
+	if err := os.WriteFile(file, []byte(config), mode); err != nil {
+		log.Fatalf("Error writing %s: %v", file, err)
+	}
+	// WriteFile keeps the mode of an overwritten file
+	if err := os.Chmod(file, mode); err != nil {
+		log.Fatalf("Error writing %s: %v", file, err)
+	}
+	fmt.Printf("\n✅ Configuration written to %s\n", file)
+	if secret {
+		fmt.Println("   It contains credentials, keep it out of version control.")
+	}
+	fmt.Printf("   Start crawling with: gocrawler -config %s\n", file)
+	return exitOK
+}
+
+// errKeepConfig is returned by config when the existing file is kept
+var errKeepConfig = errors.New("config file kept")
+
+// config asks the wizard's questions and returns the config file for
+// them, and whether it holds credentials. Closed input returns io.EOF.
+func (w *wizard) config(file string) (config string, secret bool, err error) {
+	if _, err := os.Stat(file); err == nil {
+		overwrite, err := w.choose(file+" exists, overwrite it?", []string{"y", "n"}, "n")
+		if err != nil {
+			return "", false, err
+		}
+		if overwrite != "y" {
+			return "", false, errKeepConfig
+		}
+	}
+
+	var c configWriter
+	c.comment("gocrawler configuration, written by `gocrawler init`")
+	c.comment("Run it with: gocrawler -config " + file)
+	c.comment("Every line is name = value for the command-line flag of that name,")
+	c.comment("flags given on the command line override this file.")
+	c.comment("")
+
+	seed, err := w.seed()
+	if err != nil {
+		return "", false, err
+	}
+	c.comment("Where the crawl starts")
+	c.set("url", seed)
+	c.comment("")
+
+	scope, err := w.choose("Scope: the seed's host only, its whole domain with subdomains, or a list of hosts", []string{"host", "domain", "list"}, "host")
+	if err != nil {
+		return "", false, err
+	}
+	c.comment("Which links are followed: host (the seed's host), domain (with subdomains)")
+	c.comment("or list (the seed's host and scope-hosts)")
+	c.set("scope", scope)
+	if scope == "list" {
+		hosts, err := w.ask("Extra hosts, comma-separated (*.example.com allowed)", "")
+		if err != nil {
+			return "", false, err
+		}
+		c.comment("Extra hosts for scope = list")
+		c.set("scope-hosts", hosts)
+	}
+	depth, err := w.number("Maximum link depth from the seed", 2)
+	if err != nil {
+		return "", false, err
+	}
+	c.set("depth", strconv.Itoa(depth))
+	c.comment("")
+
+	rate, err := w.number("Requests per second", 2)
+	if err != nil {
+		return "", false, err
+	}
+	workers, err := w.number("Concurrent connections", 2)
+	if err != nil {
+		return "", false, err
+	}
+	c.comment("Politeness: keep these low on sites you don't operate")
+	c.set("rate", strconv.Itoa(rate))
+	c.set("workers", strconv.Itoa(workers))
+	c.comment("")
+
+	auth, err := w.choose("Authentication", []string{"none", "basic", "bearer", "oauth2"}, "none")
+	if err != nil {
+		return "", false, err
+	}
+	switch auth {
+	case "basic":
+		c.comment("Basic auth sent to the seed's host, as user:password")
+		if err := w.setAnswer(&c, "basic-auth", "User and password as user:password"); err != nil {
+			return "", false, err
+		}
+		secret = true
+	case "bearer":
+		c.comment("Bearer token sent to the seed's host")
+		if err := w.setAnswer(&c, "bearer-token", "Token"); err != nil {
+			return "", false, err
+		}
+		secret = true
+	case "oauth2":
+		c.comment("OAuth2 client credentials, tokens are fetched and refreshed automatically")
+		for _, q := range [][2]string{
+			{"oauth2-token-url", "Token endpoint URL"},
+			{"oauth2-client-id", "Client ID"},
+			{"oauth2-client-secret", "Client secret"},
+		} {
+			if err := w.setAnswer(&c, q[0], q[1]); err != nil {
+				return "", false, err
+			}
+		}
+		scopes, err := w.ask("Scopes, comma-separated (optional)", "")
+		if err != nil {
+			return "", false, err
+		}
+		if scopes != "" {
+			c.set("oauth2-scopes", scopes)
+		}
+		secret = true
+	}
+	if secret {
+		c.comment("")
+	}
+
+	outputDir, err := w.ask("Directory for exported files", ".")
+	if err != nil {
+		return "", false, err
+	}
+	c.comment("Exported files go here")
+	c.set("output-dir", outputDir)
+	return c.b.String(), secret, nil
+}
+
+// setAnswer asks question and sets name to the answer
+func (w *wizard) setAnswer(c *configWriter, name, question string) error {
+	answer, err := w.ask(question, "")
+	if err != nil {
+		return err
+	}
+	c.set(name, answer)
+	return nil
+}
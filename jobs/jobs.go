@@ -0,0 +1,446 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"gocrawler/storage"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	Queued    Status = "queued"
+	Running   Status = "running"
+	Done      Status = "done"
+	Failed    Status = "failed"
+	Cancelled Status = "cancelled"
+)
+
+// Request describes a crawl to run, zero values use the daemon defaults
+type Request struct {
+	URL            string `json:"url"`
+	Tenant         string `json:"tenant,omitempty"`
+	Priority       int    `json:"priority,omitempty"` // higher runs first
+	Depth          int    `json:"depth,omitempty"`
+	Workers        int    `json:"workers,omitempty"`
+	Rate           int    `json:"rate,omitempty"`
+	Scope          string `json:"scope,omitempty"`
+	MaxPages       int    `json:"max_pages,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	Grep           string `json:"grep,omitempty"` // regular expression searched in page bodies
+	Keywords       bool   `json:"keywords,omitempty"`
+	Articles       bool   `json:"articles,omitempty"`
+}
+
+// Validate reports requests that cannot run
+func (r Request) Validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", r.URL)
+	}
+	if _, err := regexp.Compile(r.Grep); err != nil {
+		return fmt.Errorf("invalid grep pattern: %w", err)
+	}
+	return nil
+}
+
+// Job is a queued, running or finished crawl
+type Job struct {
+	ID          string    `json:"id"`
+	Request     Request   `json:"request"`
+	Status      Status    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	FinishedAt  time.Time `json:"finished_at,omitzero"`
+	Exports     string    `json:"exports,omitempty"` // directory and name prefix of the export files, removed with the job
+}
+
+// RunFunc executes a job, storing pages in results, until ctx is done.
+// It returns the directory and name prefix of the job's export files.
+type RunFunc func(ctx context.Context, job Job, results *storage.Results) (exports string, err error)
+
+// Manager persists the job queue and runs jobs with bounded concurrency
+type Manager struct {
+	path          string
+	maxConcurrent int
+	run           RunFunc
+
+	jobs      []*Job
+	results   map[string]*storage.Results // namespaces of jobs run by this process
+	cancels   map[string]context.CancelFunc
+	latest    string
+	running   int
+	retention storage.Retention // applied whenever a job finishes
+	mu        sync.Mutex
+
+	ctx  context.Context
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager loads the queue file. Jobs left running by a previous
+// process are queued again.
+func NewManager(path string, maxConcurrent int, run RunFunc) (*Manager, error) {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	m := &Manager{
+		path:          path,
+		maxConcurrent: maxConcurrent,
+		run:           run,
+		jobs:          make([]*Job, 0),
+		results:       make(map[string]*storage.Results),
+		cancels:       make(map[string]context.CancelFunc),
+		wake:          make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m.jobs); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for _, job := range m.jobs {
+		if job.Status == Running {
+			job.Status = Queued
+			job.StartedAt = time.Time{}
+		}
+	}
+	return m, nil
+}
+
+// Start dispatches queued jobs until ctx is cancelled. Jobs interrupted
+// by the cancellation are queued again for the next start.
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	m.signal()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.wake:
+			m.dispatch(ctx)
+		}
+	}
+}
+
+// SetRetention sets the retention applied to finished jobs whenever one
+// finishes
+func (m *Manager) SetRetention(retention storage.Retention) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = retention
+}
+
+// Prune forgets the finished jobs out of retention along with their
+// results and export files, returning how many were dropped. Queued and running jobs are
+// always kept.
+func (m *Manager) Prune(retention storage.Retention) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pruned := m.prune(retention)
+	if pruned == 0 {
+		return 0, nil
+	}
+	return pruned, m.save()
+}
+
+// prune drops the finished jobs out of retention and removes their
+// export files, callers hold m.mu
+func (m *Manager) prune(retention storage.Retention) int {
+	finished := make([]*Job, 0)
+	for _, job := range m.jobs {
+		if job.Status != Queued && job.Status != Running {
+			finished = append(finished, job)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.After(finished[j].FinishedAt)
+	})
+
+	now := time.Now()
+	drop := make(map[*Job]bool)
+	for rank, job := range finished {
+		if !retention.Keeps(rank, job.FinishedAt, now) {
+			drop[job] = true
+		}
+	}
+	if len(drop) == 0 {
+		return 0
+	}
+
+	kept := make([]*Job, 0, len(m.jobs)-len(drop))
+	keptExports := make([]string, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if !drop[job] {
+			kept = append(kept, job)
+			if job.Exports != "" {
+				keptExports = append(keptExports, job.Exports)
+			}
+			continue
+		}
+		delete(m.results, job.ID)
+		if m.latest == job.ID {
+			m.latest = ""
+		}
+	}
+	m.jobs = kept
+	for job := range drop {
+		if job.Exports == "" {
+			continue
+		}
+		if err := storage.RemoveExports(job.Exports, keptExports); err != nil {
+			log.Printf("Error removing exports of job %s: %v", job.ID, err)
+		}
+	}
+	return len(drop)
+}
+
+// Wait blocks until all running jobs have returned
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Submit validates and queues a crawl request
+func (m *Manager) Submit(req Request) (Job, error) {
+	if err := req.Validate(); err != nil {
+		return Job{}, err
+	}
+
+	job := &Job{
+		ID:          newID(),
+		Request:     req,
+		Status:      Queued,
+		SubmittedAt: time.Now(),
+	}
+
+	m.mu.Lock()
+	m.jobs = append(m.jobs, job)
+	err := m.save()
+	m.mu.Unlock()
+
+	m.signal()
+	return *job, err
+}
+
+// Cancel removes a queued job or stops a running one
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job := m.find(id)
+	if job == nil {
+		return fmt.Errorf("job %s not found", id)
+	}
+
+	switch job.Status {
+	case Queued:
+		job.Status = Cancelled
+		job.FinishedAt = time.Now()
+		return m.save()
+	case Running:
+		m.cancels[id]()
+		return nil
+	default:
+		return fmt.Errorf("job %s already %s", id, job.Status)
+	}
+}
+
+// List returns all jobs, optionally limited to one tenant
+func (m *Manager) List(tenant string) []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if tenant == "" || job.Request.Tenant == tenant {
+			list = append(list, *job)
+		}
+	}
+	return list
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job := m.find(id); job != nil {
+		return *job, true
+	}
+	return Job{}, false
+}
+
+// Results returns the results namespace of a job run by this process
+func (m *Manager) Results(id string) (*storage.Results, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results, ok := m.results[id]
+	return results, ok
+}
+
+// Latest returns the results of the most recently started job, or nil
+func (m *Manager) Latest() *storage.Results {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.results[m.latest]
+}
+
+// dispatch starts queued jobs while there are free slots
+func (m *Manager) dispatch(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for m.running < m.maxConcurrent {
+		job := m.next()
+		if job == nil {
+			return
+		}
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		if t := job.Request.TimeoutSeconds; t > 0 {
+			// derived from the cancelable context, both are released
+			// when the job ends
+			cancelJob := cancel
+			timeoutCtx, stop := context.WithTimeout(jobCtx, time.Duration(t)*time.Second)
+			jobCtx, cancel = timeoutCtx, func() { stop(); cancelJob() }
+		}
+
+		job.Status = Running
+		job.StartedAt = time.Now()
+		results := storage.NewResults()
+		m.results[job.ID] = results
+		m.cancels[job.ID] = cancel
+		m.latest = job.ID
+		m.running++
+		if err := m.save(); err != nil {
+			log.Printf("Error saving job queue: %v", err)
+		}
+
+		m.wg.Add(1)
+		go m.execute(jobCtx, cancel, *job, results)
+	}
+}
+
+// execute runs one job and records how it ended
+func (m *Manager) execute(ctx context.Context, cancel context.CancelFunc, job Job, results *storage.Results) {
+	defer m.wg.Done()
+	defer cancel()
+
+	exports, err := m.run(ctx, job, results)
+
+	m.mu.Lock()
+	j := m.find(job.ID)
+	j.FinishedAt = time.Now()
+	j.Exports = exports
+	switch {
+	case m.ctx.Err() != nil:
+		j.Status = Queued
+		j.StartedAt = time.Time{}
+		j.FinishedAt = time.Time{}
+	case err != nil:
+		j.Status = Failed
+		j.Error = err.Error()
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		j.Status = Done
+		j.Error = "timeout reached, results are partial"
+	case ctx.Err() != nil:
+		j.Status = Cancelled
+	default:
+		j.Status = Done
+	}
+	delete(m.cancels, job.ID)
+	m.running--
+	m.prune(m.retention)
+	if err := m.save(); err != nil {
+		log.Printf("Error saving job queue: %v", err)
+	}
+	m.mu.Unlock()
+
+	m.signal()
+}
+
+// next picks the queued job with the highest priority, oldest first.
+// Callers hold m.mu.
+func (m *Manager) next() *Job {
+	queued := make([]*Job, 0)
+	for _, job := range m.jobs {
+		if job.Status == Queued {
+			queued = append(queued, job)
+		}
+	}
+	if len(queued) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(queued, func(i, j int) bool {
+		return queued[i].Request.Priority > queued[j].Request.Priority
+	})
+	return queued[0]
+}
+
+// find looks a job up by ID, callers hold m.mu
+func (m *Manager) find(id string) *Job {
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// save writes the queue file atomically, a crash mid-write keeps the
+// previous queue. Callers hold m.mu.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFile(m.path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// signal wakes the dispatcher without blocking
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// newID returns a short random job ID
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// profileName restricts names to what fits in a URL path segment
+var profileName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Profile is a crawl request saved under a name to be run again
+type Profile struct {
+	Name    string    `json:"name"`
+	Request Request   `json:"request"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// Profiles persists named crawl requests in a JSON file (thread-safe)
+type Profiles struct {
+	path     string
+	profiles map[string]Profile
+	mu       sync.Mutex
+}
+
+// LoadProfiles reads the profiles file, a missing file has no profiles
+func LoadProfiles(path string) (*Profiles, error) {
+	p := &Profiles{path: path, profiles: make(map[string]Profile)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Profile
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, profile := range list {
+		p.profiles[profile.Name] = profile
+	}
+	return p, nil
+}
+
+// Save validates a request and stores it under name, replacing any
+// profile of that name
+func (p *Profiles) Save(name string, req Request) (Profile, error) {
+	if !profileName.MatchString(name) {
+		return Profile{}, fmt.Errorf("invalid profile name %q (letters, digits, '.', '_' and '-')", name)
+	}
+	if err := req.Validate(); err != nil {
+		return Profile{}, err
+	}
+
+	profile := Profile{Name: name, Request: req, SavedAt: time.Now()}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.profiles[name] = profile
+	return profile, p.save()
+}
+
+// Get returns a profile by name
+func (p *Profiles) Get(name string) (Profile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	profile, ok := p.profiles[name]
+	return profile, ok
+}
+
+// List returns all profiles sorted by name
+func (p *Profiles) List() []Profile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sorted()
+}
+
+// Delete removes a profile
+func (p *Profiles) Delete(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.profiles[name]; !ok {
+		return fmt.Errorf("profile %s not found", name)
+	}
+	delete(p.profiles, name)
+	return p.save()
+}
+
+// sorted lists the profiles by name, callers hold p.mu
+func (p *Profiles) sorted() []Profile {
+	list := make([]Profile, 0, len(p.profiles))
+	for _, profile := range p.profiles {
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// save writes the profiles file, callers hold p.mu
+func (p *Profiles) save() error {
+	if p.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(p.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.path, data, 0644)
+}
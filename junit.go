@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gocrawler/storage"
+)
+
+// junitSuites is the root of a JUnit XML report, as read by Jenkins
+// and GitLab
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     float64      `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     float64     `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// add appends a test case, counting it and its failure
+func (s *junitSuite) add(c junitCase) {
+	s.Cases = append(s.Cases, c)
+	s.Tests++
+	s.Time += c.Time
+	if c.Failure != nil {
+		s.Failures++
+	}
+}
+
+// writeJUnit writes a JUnit report of a -ci run: every crawled page is
+// a test case failing when the page did, and every threshold check is
+// one more case
+func writeJUnit(filename string, results *storage.Results, checks []ciCheck) error {
+	pages := junitSuite{Name: "pages"}
+	for _, page := range results.GetPages() {
+		c := junitCase{Name: page.URL, Classname: "pages", Time: page.ResponseTime.Seconds()}
+		if !page.Success {
+			var text strings.Builder
+			fmt.Fprintf(&text, "%s\n", page.Error)
+			for _, e := range results.Inlinks(page.URL) {
+				fmt.Fprintf(&text, "linked from %s\n", e.Source)
+			}
+			c.Failure = &junitFailure{Message: page.Error, Type: string(page.ErrorType), Text: text.String()}
+		}
+		pages.add(c)
+	}
+
+	thresholds := junitSuite{Name: "thresholds"}
+	for _, check := range checks {
+		c := junitCase{Name: check.name, Classname: "thresholds"}
+		if check.failure != "" {
+			message, _, _ := strings.Cut(check.failure, "\n")
+			c.Failure = &junitFailure{Message: message, Type: "threshold", Text: check.failure}
+		}
+		thresholds.add(c)
+	}
+
+	report := junitSuites{
+		Name:   "gocrawler",
+		Time:   results.GetStats().Duration.Round(time.Millisecond).Seconds(),
+		Suites: []junitSuite{pages, thresholds},
+	}
+	for _, s := range report.Suites {
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append([]byte(xml.Header), append(out, '\n')...), 0644)
+}
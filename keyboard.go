@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"gocrawler/crawler"
+	"gocrawler/storage"
+)
+
+// watchKeyboard reads single keypresses from an interactive terminal to
+// control a running crawl: p pauses/resumes, + and - change the rate,
+// s prints the statistics and q stops. It returns a channel closed on q
+// and a function restoring the terminal. Without a terminal it does nothing.
+func watchKeyboard(c *crawler.Crawler, results *storage.Results) (<-chan struct{}, func()) {
+	quit := make(chan struct{})
+	restore, err := cbreak(int(os.Stdin.Fd()))
+	if err != nil {
+		return quit, func() {}
+	}
+	fmt.Println("⌨️  Keys: p pause/resume, + / - rate, s stats, q stop")
+
+	go func() {
+		in := bufio.NewReader(os.Stdin)
+		for {
+			key, err := in.ReadByte()
+			if err != nil {
+				return
+			}
+			limiter := c.Limiter()
+			if limiter == nil {
+				continue
+			}
+
+			switch key {
+			case 'p', 'P':
+				if limiter.Paused() {
+					limiter.Resume()
+					fmt.Println("▶️  Resumed")
+				} else {
+					limiter.Pause()
+					fmt.Println("⏸️  Paused, press p to resume")
+				}
+			case '+', '=':
+				limiter.SetRate(limiter.Rate() + rateStep(limiter.Rate()))
+				fmt.Printf("⏫ Rate: %d req/sec\n", limiter.Rate())
+			case '-', '_':
+				limiter.SetRate(limiter.Rate() - rateStep(limiter.Rate()))
+				fmt.Printf("⏬ Rate: %d req/sec\n", limiter.Rate())
+			case 's', 'S':
+				printStats(results)
+			case 'q', 'Q':
+				close(quit)
+				return
+			}
+		}
+	}()
+	return quit, restore
+}
+
+// rateStep is how much one keypress changes the rate, about a quarter
+func rateStep(rate int) int {
+	return max(1, rate/4)
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import "errors"
+
+// cbreak is not supported on this platform, keyboard controls are off
+func cbreak(fd int) (func(), error) {
+	return nil, errors.New("keyboard controls are not supported on this platform")
+}
@@ -2,26 +2,559 @@ package main
 
 import (
 	"context"
+	"crypto/x509"
 	"flag"
 	"fmt"
+	"html/template"
 	"log"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"gocrawler/crawler"
+	"gocrawler/events"
+	"gocrawler/github"
+	"gocrawler/notify"
+	"gocrawler/parser"
+	"gocrawler/report"
+	"gocrawler/search"
 	"gocrawler/storage"
+	"gocrawler/testsite"
+	"gocrawler/tracing"
 	"gocrawler/web"
 )
 
 func main() {
+	command := subcommand()
+	if command == initCommand {
+		os.Exit(runInit(os.Args[1:]))
+	}
+	replayMode := command == replayCommand
+	benchMode := command == benchCommand
+	retryMode := command == retryCommand
+
 	// Parse command-line flags
 	startURL := flag.String("url", "https://golang.org", "Starting URL to crawl")
 	maxDepth := flag.Int("depth", 2, "Maximum crawl depth")
 	workers := flag.Int("workers", 10, "Number of concurrent workers")
 	rateLimit := flag.Int("rate", 10, "Requests per second limit")
+	delay := flag.Duration("delay", 0, "Pause of each worker before every request, randomized between half and 1.5 times it (0 disables)")
+	frontierSize := flag.Int("frontier-size", crawler.DefaultFrontierSize, "URLs queued in memory, more are spilled to a file and reloaded as the queue drains")
+	shards := flag.Int("shards", 1, "Split the frontier into N host shards, each served by its own workers (with -workers equal to -shards every host is fetched by one worker at a time, in discovery order)")
+	breakerFailures := flag.Int("breaker-failures", 0, "Pause a host after this many consecutive connection failures or 5xx responses (0 disables)")
+	costPerGB := flag.Float64("cost-per-gb", 0, "Price of a GB downloaded, to estimate the bandwidth cost of the crawl on metered connections (0 hides it)")
+	throttleP95 := flag.Duration("throttle-p95", 0, "Slow a host down while its p95 response time is above this, e.g. 800ms (0 disables)")
+	throttleMaxDelay := flag.Duration("throttle-max-delay", crawler.DefaultThrottleMaxDelay, "Longest pause between two requests to a host slowed down by -throttle-p95")
+	breakerCooldown := flag.Duration("breaker-cooldown", crawler.DefaultBreakerCooldown, "How long a host paused by -breaker-failures is left alone before it is retried")
+	vcrModeName := flag.String("vcr", "", "Record every response to -vcr-dir (record) or answer requests only from it (replay), for reproducible and offline crawls")
+	vcrDir := flag.String("vcr-dir", "fixtures", "Directory of the -vcr fixtures, one .json and .body file per request")
+	spillDir := flag.String("spill-dir", "", "Directory of the frontier spill file (default the system temp dir)")
 	webPort := flag.Int("port", 8080, "Web dashboard port")
+	topCount := flag.Int("top", 20, "Number of pages in the slowest/largest reports")
+	checkpointEvery := flag.Duration("checkpoint-interval", 5*time.Minute, "Write partial exports this often during the crawl (0 disables)")
+	checkpointPages := flag.Int("checkpoint-pages", 0, "Also write partial exports every N pages (0 disables)")
+	outputDir := flag.String("output-dir", ".", "Directory for exported files")
+	nameTemplate := flag.String("name", "crawl_{{.Host}}_{{.Timestamp}}", "Export filename prefix template with {{.Host}}, {{.Timestamp}} and {{.Date}}; the default keeps every run's files, a fixed name such as crawl overwrites them")
+	exportOrder := flag.String("order", "crawl", "Order of exported pages: crawl or url (stable across runs)")
+	schemaRow := flag.Bool("schema-row", false, "Prefix CSV exports with a #schema,<name>,<version> row")
+	csvDelimiter := flag.String("csv-delimiter", "comma", "CSV export field separator: comma, semicolon (for Excel in locales with a decimal comma) or tab")
+	csvQuoteAll := flag.Bool("csv-quote-all", false, "Quote every CSV export field, not only those containing separators, quotes or newlines")
+	csvBOM := flag.Bool("csv-bom", false, "Start CSV exports with a UTF-8 byte order mark so Excel reads non-Latin titles and URLs correctly")
+	compress := flag.String("compress", "none", "Compress exported files: none, gzip or zstd")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "Export OpenTelemetry traces over OTLP/HTTP to host:port (empty disables)")
+	otlpInsecure := flag.Bool("otlp-insecure", true, "Use plain HTTP for the OTLP endpoint")
+	historyFile := flag.String("history", "crawl_history.json", "File keeping summaries of previous runs (empty disables)")
+	retainRuns := flag.Int("retain-runs", 0, "Keep only the last N runs in -history, and the last N finished jobs in daemon mode, deleting the export files of older ones (0 keeps all)")
+	retainDays := flag.Int("retain-days", 0, "Keep only runs, finished jobs, their export files and -snapshots of the last N days (0 keeps all)")
+	scopeMode := flag.String("scope", "host", "Crawl scope: host (exact host), domain (include subdomains) or list")
+	scopeHosts := flag.String("scope-hosts", "", "Comma-separated extra hosts for -scope list (*.example.com allowed)")
+	maxPagination := flag.Int("max-pagination", 0, "Pages followed per rel=next series beyond the depth limit (0 disables)")
+	crawlAlternates := flag.Bool("crawl-alternates", false, "Also crawl AMP/mobile alternates and compare them with the canonical page")
+	daemonMode := flag.Bool("daemon", false, "Only run the web/API server and wait for crawls submitted via POST /api/jobs (or /api/crawl)")
+	jobsFile := flag.String("jobs-file", "crawl_jobs.json", "Daemon mode: file persisting the job queue")
+	profilesFile := flag.String("profiles-file", "crawl_profiles.json", "Daemon mode: file keeping the crawl profiles saved from the dashboard or /api/profiles")
+	maxJobs := flag.Int("max-jobs", 1, "Daemon mode: number of jobs crawled concurrently")
+	jobMaxWorkers := flag.Int("job-max-workers", 50, "Daemon mode: upper limit on workers a job may request")
+	jobMaxRate := flag.Int("job-max-rate", 50, "Daemon mode: upper limit on req/sec a job may request")
+	benchPages := flag.Int("bench-pages", 1000, "bench: pages of the synthetic site")
+	benchLinks := flag.Int("bench-links", 10, "bench: links per synthetic page")
+	benchLatency := flag.Duration("bench-latency", 20*time.Millisecond, "bench: latency added to every synthetic response")
+	previousRun := flag.String("previous", "", "Previous run's results.json: its pages are requested with If-Modified-Since and 304s reuse the stored data")
+	grepPattern := flag.String("grep", "", "Search page bodies for this regular expression and write a matches report")
+	grepLiteral := flag.Bool("grep-literal", false, "Treat -grep as a literal string instead of a regular expression")
+	storeBodies := flag.Bool("store-bodies", false, "Keep the raw HTML of pages (up to 1 MB each) for the dashboard's page preview")
+	checkAssets := flag.Bool("check-assets", false, "Verify that declared favicons and web app manifests resolve and write an assets report")
+	checkExternal := flag.Bool("check-external", false, "Fetch out-of-scope link targets once each and add the broken ones to the broken links report")
+	wayback := flag.Bool("wayback", false, "Look broken external links up in the Wayback Machine and report their archived copy (implies -check-external)")
+	checkOGImages := flag.Bool("check-og-images", false, "Verify og:images resolve and meet the minimum size, and report pages with missing/broken ones")
+	ogMinWidth := flag.Int("og-min-width", 200, "Minimum og:image width in pixels")
+	ogMinHeight := flag.Int("og-min-height", 200, "Minimum og:image height in pixels")
+	sitemapURL := flag.String("sitemap", "", "Sitemap to compare with the crawl, \"auto\" for /sitemap.xml of the start URL (empty disables)")
+	trafficLog := flag.String("traffic", "", "Access log (Common/Combined format) or URL list to find pages with traffic but no internal links")
+	crawlIframes := flag.Bool("crawl-iframes", false, "Also crawl in-scope iframe pages")
+	fragmentRoutes := flag.String("fragment-routes", "", "Comma-separated URL fragment prefixes treated as distinct SPA pages, e.g. #!/,#/ (hashbangs are fetched via _escaped_fragment_)")
+	scanJS := flag.Bool("scan-js", false, "Scan inline and same-origin scripts for URLs and API endpoints (reported, not crawled)")
+	recon := flag.Bool("recon", false, "Security reconnaissance: report subdomains, directory listings, interesting files and comment URLs (implies -scan-js)")
+	subdomains := flag.Bool("subdomains", false, "List subdomains seen in TLS certificates and links as candidate seeds (also enabled by -recon)")
+	maxURLLength := flag.Int("max-url-length", 0, "Skip URLs longer than this, a common crawler trap symptom (0 disables)")
+	languages := flag.String("languages", "", "Only crawl these languages, comma-separated (e.g. en,de): /xx/ path segments and declared languages outside it aren't followed")
+	maxQueryParams := flag.Int("max-query-params", 0, "Skip URLs with more query parameters than this (0 disables)")
+	upgradeHTTPS := flag.Bool("upgrade-https", false, "Crawl the https version of in-scope http links when it resolves")
+	followRefresh := flag.Bool("follow-meta-refresh", false, "Crawl meta refresh (and Refresh header) targets like redirects")
+	proxyList := flag.String("proxies", "", "Comma-separated proxy pool (http://, https:// or socks5://); failed requests retry through another proxy")
+	ignoreRobots := flag.Bool("ignore-robots", false, "Follow links of nofollow pages anyway (directives are still reported)")
+	profileName := flag.String("profile", "", "Politeness preset: polite (2 workers, 1 req/s, 2s delay), normal (10, 10, no delay) or aggressive (50, 100, no delay, ignores nofollow); explicit flags win")
+	ciMode := flag.Bool("ci", false, "CI smoke test: no dashboard, exit when done with status 2 if a -ci-max-* threshold or -error-threshold is breached")
+	ciMaxBroken := flag.Int("ci-max-broken", 0, "-ci: maximum internal links to failed pages (-1 disables)")
+	ciMax5xx := flag.Float64("ci-max-5xx", 1, "-ci: maximum percentage of pages answering 5xx (-1 disables)")
+	ciMaxMissingTitles := flag.Int("ci-max-missing-titles", 0, "-ci: maximum pages without a title (-1 disables)")
+	ciMaxViolations := flag.Int("ci-max-rule-violations", 0, "-ci: maximum -rules violations (-1 disables)")
+	ciMaxMigration := flag.Int("ci-max-migration-failures", 0, "-ci: maximum -migration-map URLs not redirecting in one hop to their new URL (-1 disables)")
+	summaryFile := flag.String("summary-json", "", "Write the final statistics, thresholds and exit status as JSON to this file, or as the last line of stdout for -")
+	junitFile := flag.String("junit", "", "-ci: JUnit XML report path (default <name>_junit.xml in -output-dir, never compressed)")
+	htmlReport := flag.Bool("html-report", false, "Write an HTML audit report (<name>_report.html in -output-dir, never compressed)")
+	reportTemplate := flag.String("report-template", "", "Go html/template file to render the HTML audit report with instead of the built-in one, implies -html-report (see the report package for its data)")
+	baselineFile := flag.String("baseline", "", "results.json of a baseline crawl (e.g. the base branch) to report new broken links and regressions against")
+	githubRepo := flag.String("github-repo", "", "GitHub repository (owner/name) for -github-status/-github-pr (default $GITHUB_REPOSITORY); the token is read from $GITHUB_TOKEN")
+	githubStatus := flag.Bool("github-status", false, "Set a commit status with the crawl audit on -github-sha")
+	githubSHA := flag.String("github-sha", "", "Commit for -github-status (default $GITHUB_SHA)")
+	githubPR := flag.Int("github-pr", 0, "Pull request number to comment the crawl audit on (0 disables)")
+	githubAPI := flag.String("github-api", "", "GitHub API URL (default $GITHUB_API_URL or "+github.DefaultAPIURL+")")
+	snapshotDir := flag.String("snapshots", "", "Directory keeping the visible text of every page per run, for text diffs between runs (empty disables)")
+	snapshotRetention := flag.Int("snapshot-retention", 10, "Text snapshots kept per URL in -snapshots (0 keeps all)")
+	articles := flag.Bool("articles", false, "Extract the main content of pages (title, author, date, text) readability-style and export it as JSON Lines")
+	keywords := flag.Bool("keywords", false, "Count keywords and capitalized entities in page text, per page and site-wide (top -top of each)")
+	searchEngine := flag.String("search-engine", "", "Write search documents for meilisearch or typesense (empty disables); the API key is read from $SEARCH_API_KEY")
+	searchFields := flag.String("search-fields", search.DefaultFields, "Search document fields as name=page field (id, url, title, description, content, text, headings, author, published, keywords, depth, status_code, crawled_at)")
+	searchURL := flag.String("search-url", "", "Push search documents to this engine URL, e.g. http://localhost:7700 (empty only writes the file)")
+	searchIndex := flag.String("search-index", "pages", "MeiliSearch index or Typesense collection to push to")
+	algoliaApp := flag.String("algolia-app", "", "Algolia application ID to push page records to (with -algolia-index); the admin key is read from $ALGOLIA_API_KEY")
+	algoliaIndex := flag.String("algolia-index", "", "Algolia index for page records; records of this site from earlier runs are deleted")
+	algoliaHost := flag.String("algolia-host", "", "Algolia API host (default https://<app>.algolia.net)")
+	writeSitemap := flag.Bool("write-sitemap", false, "Generate sitemap.xml from the indexable pages (no noindex, redirect, alternate or foreign canonical)")
+	sitemapPing := flag.String("sitemap-ping", "", "Comma-separated endpoints to GET with ?sitemap=<public URL> after the crawl (requires -write-sitemap)")
+	sitemapPublic := flag.String("sitemap-public-url", "", "URL the generated sitemap is published at, for -sitemap-ping (default /sitemap.xml of the start URL)")
+	indexNowKey := flag.String("indexnow-key", "", "Write an IndexNow submission with this key for the new and changed pages (requires -snapshots)")
+	indexNowKeyLocation := flag.String("indexnow-key-location", "", "URL of the IndexNow key file when not at /<key>.txt")
+	userAgent := flag.String("user-agent", "gocrawler/1.0", "Product token of the User-Agent, sent as \"<token> (+<bot info URL>)\" with -bot-info-url")
+	botInfoURL := flag.String("bot-info-url", "", "Public URL of the dashboard's /bot-info page (or your own) named in the User-Agent, which is only the -user-agent token without it")
+	botContact := flag.String("bot-contact", "", "E-mail or URL shown on /bot-info for site owners to reach you")
+	botInfoTemplate := flag.String("bot-info-template", "", "html/template file replacing the /bot-info page, executed with .UserAgent, .Contact, .Seeds, .Workers, .RateLimit and .Started")
+	errorThreshold := flag.Float64("error-threshold", -1, "Exit with status 2 when more than this percentage of pages failed (-1 disables)")
+	paramPolicies := flag.String("params", "", "Query parameter policies, e.g. utm_*:drop,sid:drop,page:page=10")
+	var basicAuth, bearerTokens stringList
+	flag.Var(&basicAuth, "basic-auth", "Preemptive basic auth as [host=]user:pass (repeatable; default host is the start URL's)")
+	flag.Var(&bearerTokens, "bearer-token", "Bearer token as [host=]token (repeatable; default host is the start URL's)")
+	var clientCerts stringList
+	flag.Var(&clientCerts, "client-cert", "mTLS client certificate as [host=]cert.pem[,key.pem] (repeatable; without host it is offered to every host)")
+	var resolveSpecs stringList
+	dashboardDir := flag.String("dashboard-dir", "", "Directory of dashboard files (index.html, graph.html, static/dashboard.css, ...) used instead of the built-in ones, missing files fall back to them")
+	apiRate := flag.Int("api-rate", web.DefaultAPIRate, "API requests per second allowed per dashboard client, with bursts of twice as many (0 disables)")
+	var apiTokens stringList
+	flag.Var(&apiTokens, "api-token", "Require this token for the dashboard and API, as [role=]token with role read (dashboard, stats, pages, exports) or admin (also jobs, profiles, settings, pruning; the default) (repeatable)")
+	var webhookSpecs stringList
+	flag.Var(&webhookSpecs, "notify", "Post a crawl summary to a chat webhook when done, as service[@severity]=url with service slack, discord or teams and the minimum severity info (default), warning or error (repeatable)")
+	flag.Var(&resolveSpecs, "resolve", "Connect to addr for host:port, as host:port:addr like curl (repeatable), e.g. to crawl staging under production hostnames")
+	caBundle := flag.String("ca-bundle", "", "PEM file of extra CA certificates to trust")
+	insecureTLS := flag.Bool("insecure-skip-verify", false, "INSECURE: skip TLS certificate verification (self-signed staging sites); affected pages are flagged in the results")
+	logLevelName := flag.String("log-level", "info", "Per-page logging: info (every page), warn (failures and warnings) or error (failures only)")
+	rulesFile := flag.String("rules", "", "JSON file of per-URL-pattern page assertions checked during the crawl, written to a rules report and failing -ci: [{\"pattern\": \"regexp\", \"status\": 200, \"title\": \"regexp\", \"max_response_ms\": 800, \"headers\": [\"Name\"]}]")
+	headersConfig := flag.String("headers-config", "", "JSON file of per-URL-pattern header overrides: [{\"pattern\": \"regexp\", \"headers\": {\"Name\": \"value\"}}]")
+	oauthTokenURL := flag.String("oauth2-token-url", "", "OAuth2 client-credentials token endpoint; tokens are refreshed automatically")
+	oauthClientID := flag.String("oauth2-client-id", "", "OAuth2 client ID")
+	oauthSecret := flag.String("oauth2-client-secret", "", "OAuth2 client secret")
+	oauthScopes := flag.String("oauth2-scopes", "", "Comma-separated OAuth2 scopes")
+	oauthHosts := flag.String("oauth2-hosts", "", "Comma-separated hosts sent the OAuth2 token (default: the start URL's host)")
+	stripSelectors := flag.String("strip-selectors", "", "Comma-separated regions left out of page text, so rotating footers or dates don't count as -snapshots changes: tag, #id, .class, tag#id or tag.class")
+	stripBoilerplate := flag.Bool("strip-boilerplate", false, "Also leave site headers, footers, navigation and sidebars out of page text (detected from HTML5 landmarks, roles and id/class names)")
+	seedsFile := flag.String("seeds", "", "Crawl exactly the URLs of an earlier results.json or results.csv, without following links (e.g. to verify known URLs after a migration)")
+	migrationMap := flag.String("migration-map", "", "CSV of old URL,expected new URL pairs: fetch the old URLs only and verify each redirects to its new URL in one hop, written to a migration report")
+	configFile := flag.String("config", "", "Read settings from a file of name = value lines, as written by `gocrawler init`; command-line flags win")
 	flag.Parse()
+	explicit := explicitFlags()
+	if *configFile != "" {
+		if err := loadConfig(*configFile, explicit); err != nil {
+			log.Fatalf("Error reading config: %v", err)
+		}
+	}
+
+	if err := applyProfile(*profileName, workers, rateLimit, delay, ignoreRobots); err != nil {
+		log.Fatal(err)
+	}
+	mode, err := crawler.ParseScopeMode(*scopeMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logLevel, err := crawler.ParseLogLevel(*logLevelName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	vcrMode, err := crawler.ParseVCRMode(*vcrModeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	resolve, err := crawler.ParseResolve(resolveSpecs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var webhooks []notify.Webhook
+	for _, spec := range webhookSpecs {
+		hook, err := notify.ParseWebhook(spec)
+		if err != nil {
+			log.Fatalf("Invalid -notify: %v", err)
+		}
+		webhooks = append(webhooks, hook)
+	}
+	strip, err := parser.ParseSelectors(*stripSelectors)
+	if err != nil {
+		log.Fatalf("Invalid -strip-selectors: %v", err)
+	}
+	var seeds []string
+	var migration []storage.MigrationRule
+	seedSource := *seedsFile
+	if *seedsFile != "" && *migrationMap != "" {
+		log.Fatal("-seeds and -migration-map both choose the URLs to fetch, use one")
+	}
+	if *seedsFile != "" {
+		if seeds, err = storage.LoadSeedURLs(*seedsFile); err != nil {
+			log.Fatalf("Error reading seeds: %v", err)
+		}
+	}
+	if *migrationMap != "" {
+		if migration, err = storage.LoadMigrationMap(*migrationMap); err != nil {
+			log.Fatalf("Error reading migration map: %v", err)
+		}
+		for _, rule := range migration {
+			seeds = append(seeds, rule.Old)
+		}
+		seedSource = *migrationMap
+	}
+	if seedSource != "" {
+		if len(seeds) == 0 {
+			log.Fatalf("No URLs in %s", seedSource)
+		}
+		// the first seed names the run unless -url is set
+		urlSet := false
+		flag.Visit(func(f *flag.Flag) { urlSet = urlSet || f.Name == "url" })
+		if !urlSet {
+			*startURL = seeds[0]
+		}
+	}
+	scope := crawler.Scope{Mode: mode}
+	if *scopeHosts != "" {
+		scope.Hosts = strings.Split(*scopeHosts, ",")
+	}
+	params, err := crawler.ParseParamPolicies(*paramPolicies)
+	if err != nil {
+		log.Fatal(err)
+	}
+	grep, err := crawler.ParseGrep(*grepPattern, *grepLiteral)
+	if err != nil {
+		log.Fatalf("Invalid -grep pattern: %v", err)
+	}
+	compressExt, err := storage.CompressionExt(*compress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	runStarted := time.Now()
+	prefix, err := renderPrefix(*nameTemplate, *startURL, runStarted)
+	if err != nil {
+		log.Fatalf("Invalid -name template: %v", err)
+	}
+	var reportTmpl *template.Template
+	if *htmlReport || *reportTemplate != "" {
+		if reportTmpl, err = report.Load(*reportTemplate); err != nil {
+			log.Fatalf("Invalid -report-template: %v", err)
+		}
+	}
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("Error creating output directory: %v", err)
+	}
+	credentials, err := parseCredentials(*startURL, basicAuth, bearerTokens)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *oauthTokenURL != "" {
+		oauth := &crawler.OAuth2{
+			TokenURL:     *oauthTokenURL,
+			ClientID:     *oauthClientID,
+			ClientSecret: *oauthSecret,
+		}
+		if *oauthScopes != "" {
+			oauth.Scopes = strings.Split(*oauthScopes, ",")
+		}
+		hosts := []string{hostOf(*startURL)}
+		if *oauthHosts != "" {
+			hosts = strings.Split(*oauthHosts, ",")
+		}
+		for _, host := range hosts {
+			credentials = append(credentials, crawler.Credentials{Host: host, OAuth2: oauth})
+		}
+	}
+	var certs []crawler.ClientCert
+	for _, spec := range clientCerts {
+		cert, err := crawler.LoadClientCert(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		certs = append(certs, cert)
+	}
+	var rootCAs *x509.CertPool
+	if *caBundle != "" {
+		if rootCAs, err = crawler.LoadCABundle(*caBundle); err != nil {
+			log.Fatalf("Error loading CA bundle: %v", err)
+		}
+	}
+	if *insecureTLS {
+		log.Printf("⚠️  TLS certificate verification is DISABLED (-insecure-skip-verify)")
+	}
+	var headers []crawler.HeaderRule
+	if *headersConfig != "" {
+		if headers, err = crawler.LoadHeaderRules(*headersConfig); err != nil {
+			log.Fatalf("Error loading header rules: %v", err)
+		}
+	}
+	var rules []crawler.Rule
+	if *rulesFile != "" {
+		if rules, err = crawler.LoadRules(*rulesFile); err != nil {
+			log.Fatalf("Error loading page rules: %v", err)
+		}
+	}
+	proxies, err := crawler.ParseProxies(*proxyList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var fields []search.Field
+	if *searchEngine != "" {
+		if _, err := search.ParseEngine(*searchEngine); err != nil {
+			log.Fatal(err)
+		}
+		if fields, err = search.ParseFields(*searchFields); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	algolia := *algoliaApp != "" || *algoliaIndex != ""
+	if algolia && (*algoliaApp == "" || *algoliaIndex == "" || os.Getenv("ALGOLIA_API_KEY") == "") {
+		log.Fatal("Algolia export needs -algolia-app, -algolia-index and $ALGOLIA_API_KEY")
+	}
+
+	var baseline *storage.Baseline
+	if *baselineFile != "" {
+		if baseline, err = storage.LoadBaseline(*baselineFile); err != nil {
+			log.Fatalf("Error loading baseline: %v", err)
+		}
+	}
+	var gh *github.Client
+	if *githubStatus || *githubPR > 0 {
+		if gh, err = github.New(github.Config{
+			Token:  os.Getenv("GITHUB_TOKEN"),
+			Repo:   orEnv(*githubRepo, "GITHUB_REPOSITORY"),
+			APIURL: orEnv(*githubAPI, "GITHUB_API_URL"),
+		}); err != nil {
+			log.Fatal(err)
+		}
+		if *githubStatus && orEnv(*githubSHA, "GITHUB_SHA") == "" {
+			log.Fatal("-github-status needs -github-sha or $GITHUB_SHA")
+		}
+	}
+
+	var previous map[string]*storage.Page
+	if *previousRun != "" {
+		if previous, err = storage.LoadPages(*previousRun); err != nil {
+			log.Fatalf("Error loading previous run: %v", err)
+		}
+		log.Printf("Loaded %d pages from previous run %s", len(previous), *previousRun)
+	}
+
+	// the dashboard is rarely reachable from the crawled sites, so only
+	// a page the operator published is named in the User-Agent
+	agent := *userAgent
+	if *botInfoURL != "" {
+		agent = fmt.Sprintf("%s (+%s)", *userAgent, *botInfoURL)
+	}
+
+	cfg := crawler.Config{
+		Workers:          *workers,
+		RateLimit:        *rateLimit,
+		Delay:            *delay,
+		MaxDepth:         *maxDepth,
+		Scope:            scope,
+		Params:           params,
+		MaxPagination:    *maxPagination,
+		CrawlAlternates:  *crawlAlternates,
+		Previous:         previous,
+		Grep:             grep,
+		CheckAssets:      *checkAssets,
+		CheckOGImages:    *checkOGImages,
+		OGMinWidth:       *ogMinWidth,
+		OGMinHeight:      *ogMinHeight,
+		CrawlIframes:     *crawlIframes,
+		FragmentRoutes:   crawler.ParseFragmentRoutes(*fragmentRoutes),
+		ScanJS:           *scanJS || *recon,
+		Recon:            *recon,
+		Credentials:      credentials,
+		ClientCerts:      certs,
+		RootCAs:          rootCAs,
+		InsecureTLS:      *insecureTLS,
+		Headers:          headers,
+		Rules:            rules,
+		MaxURLLength:     *maxURLLength,
+		MaxQueryParams:   *maxQueryParams,
+		UpgradeHTTPS:     *upgradeHTTPS,
+		FollowRefresh:    *followRefresh,
+		Proxies:          proxies,
+		IgnoreRobots:     *ignoreRobots,
+		KeepText:         *snapshotDir != "" || *searchEngine != "" || algolia,
+		StoreBodies:      *storeBodies,
+		Articles:         *articles || *searchEngine != "" || algolia,
+		Keywords:         *keywords || search.Uses(fields, "keywords"),
+		UserAgent:        agent,
+		LogLevel:         logLevel,
+		FrontierSize:     *frontierSize,
+		Shards:           *shards,
+		BreakerFailures:  *breakerFailures,
+		BreakerCooldown:  *breakerCooldown,
+		ThrottleP95:      *throttleP95,
+		CheckExternal:    *checkExternal,
+		Wayback:          *wayback,
+		ThrottleMaxDelay: *throttleMaxDelay,
+		SpillDir:         *spillDir,
+		Languages:        strings.Split(*languages, ","),
+		VCR:              vcrMode,
+		VCRDir:           *vcrDir,
+		Resolve:          resolve,
+		Parse:            parser.Options{Strip: strip, AutoStrip: *stripBoilerplate},
+	}
+	if replayMode {
+		os.Exit(replay(cfg, *startURL))
+	}
+	if retryMode {
+		os.Exit(retryFailed(cfg))
+	}
+	if benchMode {
+		os.Exit(bench(cfg, testsite.Config{Pages: *benchPages, Links: *benchLinks, Latency: *benchLatency}))
+	}
+	exportOpts := exportOptions{
+		top:        *topCount,
+		alternates: *crawlAlternates,
+		grep:       grep != nil,
+		assets:     *checkAssets,
+		ogImages:   *checkOGImages,
+		sitemap:    *sitemapURL != "",
+		traffic:    *trafficLog != "",
+		endpoints:  *scanJS || *recon,
+		recon:      *recon,
+		subdomains: *subdomains || *recon,
+		snapshots:  *snapshotDir != "",
+		articles:   *articles,
+		keywords:   *keywords,
+		sitemapXML: *writeSitemap,
+		rules:      rules != nil,
+		migration:  migration != nil,
+		dir:        *outputDir,
+		prefix:     prefix,
+		ext:        compressExt,
+	}
+
+	// os.Exit skips defers, so the exit path flushes traces explicitly
+	flushTraces := func() {}
+	if *otlpEndpoint != "" {
+		shutdown, err := tracing.Setup(context.Background(), *otlpEndpoint, *otlpInsecure)
+		if err != nil {
+			log.Fatalf("Error setting up tracing: %v", err)
+		}
+		flushTraces = func() { shutdown(context.Background()) }
+		defer flushTraces()
+	}
+
+	// Create results storage
+	results := storage.NewResults()
+	order, err := storage.ParseOrder(*exportOrder)
+	if err != nil {
+		log.Fatal(err)
+	}
+	delimiter, err := storage.ParseDelimiter(*csvDelimiter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	exportConfig := storage.ExportConfig{Order: order, SchemaRow: *schemaRow, BOM: *csvBOM, Delimiter: delimiter, QuoteAll: *csvQuoteAll}
+	results.SetExportConfig(exportConfig)
+	results.SetBandwidthPrice(*costPerGB)
+
+	var history *storage.History
+	if *historyFile != "" {
+		if history, err = storage.LoadHistory(*historyFile); err != nil {
+			log.Fatalf("Error loading run history: %v", err)
+		}
+	}
+	retention, err := storage.NewRetention(*retainRuns, *retainDays)
+	if err != nil {
+		log.Fatalf("Invalid retention: %v", err)
+	}
+	if history != nil && !retention.IsZero() {
+		history.SetRetention(retention)
+		if pruned, err := history.Prune(retention); err != nil {
+			log.Printf("Error pruning run history: %v", err)
+		} else if pruned > 0 {
+			log.Printf("🧹 Pruned %d runs from %s, keeping %s", pruned, *historyFile, retention)
+		}
+	}
+
+	srv := web.NewServer(*webPort, results, history)
+	if *dashboardDir != "" {
+		if err := srv.SetDashboardDir(*dashboardDir); err != nil {
+			log.Fatalf("Error loading dashboard files: %v", err)
+		}
+	}
+	srv.SetRetention(retention)
+	tokens := make([]web.APIToken, 0, len(apiTokens))
+	for _, spec := range apiTokens {
+		token, err := web.ParseAPIToken(spec)
+		if err != nil {
+			log.Fatalf("Invalid -api-token: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+	srv.SetTokens(tokens)
+	srv.SetAPIRate(*apiRate)
+	info := web.BotInfo{UserAgent: agent, Contact: *botContact, Workers: *workers, RateLimit: *rateLimit, Started: runStarted}
+	if !*daemonMode {
+		info.Seeds = []string{*startURL}
+	}
+	if err := srv.SetBotInfo(info, *botInfoTemplate); err != nil {
+		log.Fatalf("Error loading bot info template: %v", err)
+	}
+
+	if *sitemapPing != "" && !*writeSitemap {
+		log.Fatal("-sitemap-ping requires -write-sitemap")
+	}
+	if *indexNowKey != "" && *snapshotDir == "" {
+		log.Fatal("-indexnow-key requires -snapshots to detect changed pages")
+	}
+
+	if *daemonMode {
+		if *snapshotDir != "" {
+			log.Fatal("-snapshots is not supported with -daemon")
+		}
+		runDaemon(&daemon{
+			base:            cfg,
+			maxWorkers:      *jobMaxWorkers,
+			maxRate:         *jobMaxRate,
+			history:         history,
+			exportConfig:    exportConfig,
+			exportOpts:      exportOpts,
+			nameTemplate:    *nameTemplate,
+			checkpointEvery: *checkpointEvery,
+			checkpointPages: *checkpointPages,
+			costPerGB:       *costPerGB,
+			retention:       retention,
+		}, srv, *jobsFile, *profilesFile, *maxJobs)
+		return
+	}
 
 	fmt.Printf(`
 ╔═══════════════════════════════════════════════════════════╗
@@ -32,30 +565,66 @@ func main() {
 Configuration:
   • Start URL:     %s
   • Max Depth:     %d
+  • Scope:         %s
   • Workers:       %d (concurrent goroutines)
   • Rate Limit:    %d req/sec
   • Dashboard:     http://localhost:%d
 
 Press Ctrl+C to stop crawling...
 
-`, *startURL, *maxDepth, *workers, *rateLimit, *webPort)
-
-	// Create results storage
-	results := storage.NewResults()
+`, *startURL, *maxDepth, mode, *workers, *rateLimit, *webPort)
 
 	// Create crawler with context for cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	c := crawler.New(*workers, *rateLimit, *maxDepth, results)
+	// Storage, and any future consumer, subscribes to crawl events
+	bus := events.NewBus()
+	events.Record(bus, results)
 
-	// Start web dashboard in goroutine
-	srv := web.NewServer(*webPort, results)
-	go func() {
-		if err := srv.Start(); err != nil {
-			log.Printf("Web server error: %v", err)
+	var snapshots *storage.SnapshotStore
+	if *snapshotDir != "" {
+		if snapshots, err = storage.OpenSnapshots(*snapshotDir, *snapshotRetention, time.Now()); err != nil {
+			log.Fatalf("Error opening text snapshots: %v", err)
 		}
-	}()
+		if retention.MaxAge > 0 {
+			if pruned := snapshots.PruneBefore(time.Now().Add(-retention.MaxAge)); pruned > 0 {
+				log.Printf("🧹 Pruned %d text snapshots older than %d days", pruned, *retainDays)
+			}
+		}
+		events.Snapshot(bus, snapshots, results)
+		srv.SetSnapshots(snapshots)
+	}
+
+	c := crawler.New(cfg)
+	srv.SetCrawler(c)
+	if *configFile != "" {
+		go watchConfig(ctx, *configFile, explicit, c)
+	}
+
+	if *sitemapURL != "" {
+		loadSitemap(ctx, c, results, *sitemapURL, *startURL)
+	}
+	if *trafficLog != "" {
+		hits, err := c.LoadTraffic(*trafficLog, *startURL)
+		if err != nil {
+			log.Fatalf("Error importing traffic: %v", err)
+		}
+		results.SetTraffic(hits)
+		log.Printf("📈 Imported traffic for %d URLs from %s", len(hits), *trafficLog)
+	}
+	if migration != nil {
+		results.SetMigrationMap(migration)
+	}
+
+	// Start web dashboard in goroutine, CI runs have no one to look at it
+	if !*ciMode {
+		go func() {
+			if err := srv.Start(); err != nil {
+				log.Printf("Web server error: %v", err)
+			}
+		}()
+	}
 
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -64,44 +633,358 @@ Press Ctrl+C to stop crawling...
 	// Start crawling in goroutine
 	done := make(chan bool)
 	go func() {
-		c.Crawl(ctx, *startURL)
+		if seeds != nil {
+			log.Printf("🌱 Fetching the %d URLs of %s, links are not followed", len(seeds), seedSource)
+			jobs := make([]crawler.Job, len(seeds))
+			for i, seed := range seeds {
+				jobs[i] = crawler.Job{URL: seed}
+			}
+			c.Refetch(ctx, *startURL, jobs, bus)
+		} else {
+			c.Crawl(ctx, *startURL, bus)
+		}
 		done <- true
 	}()
 
-	// Wait for completion or interruption
+	stopCheckpoints := startCheckpoints(ctx, results, exportOpts, *checkpointEvery, *checkpointPages)
+
+	var quit <-chan struct{}
+	restoreTerminal := func() {}
+	if !*ciMode {
+		quit, restoreTerminal = watchKeyboard(c, results)
+	}
+
+	// Wait for completion or interruption, once stopping another
+	// Ctrl+C exits without waiting for the crawler or the exports
+	aborted := false
+	stopForceQuit := make(chan struct{})
 	select {
 	case <-sigChan:
-		fmt.Println("\n\n🛑 Interrupt received, stopping crawler...")
+		fmt.Println("\n\n🛑 Interrupt received, stopping crawler (Ctrl+C again to force quit)...")
+		aborted = true
+		go forceQuit(sigChan, restoreTerminal, stopForceQuit)
 		cancel()
 		<-done // Wait for crawler to finish
+	case <-quit:
+		fmt.Println("\n\n🛑 Stop requested, stopping crawler (Ctrl+C to force quit)...")
+		aborted = true
+		go forceQuit(sigChan, restoreTerminal, stopForceQuit)
+		cancel()
+		<-done
 	case <-done:
 		fmt.Println("\n\n✅ Crawling completed!")
 	}
+	restoreTerminal()
+
+	stopCheckpoints()
 
 	// Print final statistics
 	printStats(results)
+	printProxyStats(c.ProxyStats())
+	printConnStats(c.ConnStats())
 
-	// Export results
-	if err := results.ExportJSON("crawl_results.json"); err != nil {
-		log.Printf("Error exporting JSON: %v", err)
+	// Save history and export results
+	finishRun(results, history, *startURL, exportOpts)
+	if reportTmpl != nil {
+		writeReport(results, exportOpts, reportTmpl, *startURL)
+	}
+	if *searchEngine != "" {
+		exportSearch(results, exportOpts, fields, search.Config{
+			Engine: *searchEngine,
+			URL:    *searchURL,
+			Index:  *searchIndex,
+			APIKey: os.Getenv("SEARCH_API_KEY"),
+		})
 	}
-	if err := results.ExportCSV("crawl_results.csv"); err != nil {
-		log.Printf("Error exporting CSV: %v", err)
+	if algolia {
+		pushAlgolia(results, *startURL, runStarted, search.AlgoliaConfig{
+			AppID:  *algoliaApp,
+			APIKey: os.Getenv("ALGOLIA_API_KEY"),
+			Index:  *algoliaIndex,
+			Host:   *algoliaHost,
+		})
 	}
-	if err := results.ExportLinksCSV("crawl_links.csv"); err != nil {
-		log.Printf("Error exporting links CSV: %v", err)
+	if *sitemapPing != "" || *indexNowKey != "" {
+		var endpoints []string
+		if *sitemapPing != "" {
+			endpoints = strings.Split(*sitemapPing, ",")
+		}
+		publishSitemap(results, snapshots, exportOpts, *startURL, *sitemapPublic, endpoints, *indexNowKey, *indexNowKeyLocation)
+	}
+	if snapshots != nil {
+		if err := snapshots.Close(); err != nil {
+			log.Printf("Error saving text snapshots: %v", err)
+		}
 	}
 
 	fmt.Println("\n📊 Results exported:")
-	fmt.Println("   • crawl_results.json - All page data")
-	fmt.Println("   • crawl_results.csv - Page summary")
-	fmt.Println("   • crawl_links.csv - All links found (easier to read)")
+	fmt.Printf("   • %s - All page data\n", exportOpts.path("results.json"))
+	fmt.Printf("   • %s - Page summary\n", exportOpts.path("results.csv"))
+	fmt.Printf("   • %s - All links found (easier to read)\n", exportOpts.path("links.csv"))
+	fmt.Printf("   • %s / %s - Top offenders\n", exportOpts.path("slowest.csv"), exportOpts.path("largest.csv"))
+	fmt.Printf("   • %s - Anchor texts per linked URL\n", exportOpts.path("anchors.csv"))
+	fmt.Printf("   • %s - Embedded iframes and media\n", exportOpts.path("embeds.csv"))
+	fmt.Printf("   • %s - Redirecting links, redirect loops/long chains, meta refreshes and canonical chains\n", exportOpts.path("redirects.csv"))
+	fmt.Printf("   • %s - URLs skipped by the length/parameter guards\n", exportOpts.path("skipped.csv"))
+	fmt.Printf("   • %s - Links to http versions of https pages\n", exportOpts.path("http_links.csv"))
+	fmt.Printf("   • %s - Internal links to failed pages (and to failed external targets with -check-external)\n", exportOpts.path("broken_links.csv"))
+	fmt.Printf("   • %s - URLs with the same content as an earlier page\n", exportOpts.path("duplicates.csv"))
+	if *crawlAlternates {
+		fmt.Printf("   • %s - AMP/mobile parity checks\n", exportOpts.path("alternates.csv"))
+	}
+	if *checkAssets {
+		fmt.Printf("   • %s - Favicon/manifest audit per site\n", exportOpts.path("assets.csv"))
+	}
+	if *checkOGImages {
+		fmt.Printf("   • %s - Missing/broken social preview images\n", exportOpts.path("og_images.csv"))
+	}
+	if *sitemapURL != "" {
+		fmt.Printf("   • %s - Sitemap orphans and pages missing from the sitemap\n", exportOpts.path("sitemap_gaps.csv"))
+	}
+	if *trafficLog != "" {
+		fmt.Printf("   • %s - Pages with traffic but no internal links\n", exportOpts.path("traffic_orphans.csv"))
+	}
+	if *subdomains || *recon {
+		fmt.Printf("   • %s - Candidate subdomain seeds\n", exportOpts.path("subdomains.csv"))
+	}
+	if *recon {
+		fmt.Printf("   • %s - Reconnaissance findings\n", exportOpts.path("recon.csv"))
+	}
+	if *scanJS || *recon {
+		fmt.Printf("   • %s - URLs and endpoints found in JavaScript\n", exportOpts.path("endpoints.csv"))
+	}
+	if *writeSitemap {
+		fmt.Printf("   • %s - Sitemap of the indexable pages\n", exportOpts.path("sitemap.xml"))
+	}
+	if rules != nil {
+		fmt.Printf("   • %s - Page rule violations (%d)\n", exportOpts.path("rules.csv"), len(results.Violations()))
+	}
+	if migration != nil {
+		fmt.Printf("   • %s - Migration redirect checks\n", exportOpts.path("migration.csv"))
+		printMigration(results.MigrationChecks())
+	}
+	if snapshots != nil {
+		fmt.Printf("   • %s - Pages whose text changed since the last run (snapshots in %s)\n", exportOpts.path("text_changes.csv"), *snapshotDir)
+	}
+	if *articles {
+		fmt.Printf("   • %s - Extracted articles, one JSON object per line\n", exportOpts.path("articles.jsonl"))
+	}
+	if *keywords {
+		fmt.Printf("   • %s - Keywords and entities, site-wide then per page\n", exportOpts.path("keywords.csv"))
+	}
+	if reportTmpl != nil {
+		fmt.Printf("   • %s - HTML audit report\n", exportOpts.plainPath("report.html"))
+	}
+	if grep != nil {
+		fmt.Printf("   • %s - Pattern matches (%d pages)\n", exportOpts.path("matches.csv"), results.GetStats().GrepMatches)
+	}
+	code := exitCode(results.GetStats(), aborted, *errorThreshold)
+	if code == exitErrors {
+		fmt.Printf("\n⚠️  More than %.1f%% of pages failed, exiting with status %d\n", *errorThreshold, code)
+	}
+
+	var checks []ciCheck
+	var thresholds *ciThresholds
+	if *ciMode {
+		maxViolations := *ciMaxViolations
+		if rules == nil {
+			maxViolations = -1
+		}
+		maxMigration := *ciMaxMigration
+		if migration == nil {
+			maxMigration = -1
+		}
+		thresholds = &ciThresholds{
+			maxBroken:        *ciMaxBroken,
+			max5xx:           *ciMax5xx,
+			maxMissingTitles: *ciMaxMissingTitles,
+			maxViolations:    maxViolations,
+			maxMigration:     maxMigration,
+			maxFailed:        *errorThreshold,
+		}
+		checks = ciChecks(results, *thresholds)
+		if printCIResult(checks) && code == exitOK {
+			code = exitErrors
+		}
+	}
+
+	a := audit{startURL: *startURL, stats: results.GetStats(), broken: results.BrokenLinks(), checks: checks, code: code}
+	if baseline != nil {
+		comparison := results.Compare(baseline)
+		a.comparison = &comparison
+		fmt.Printf("\n🔍 Versus baseline: %d new broken links, %d fixed, %d regressions\n",
+			len(comparison.NewBroken), comparison.FixedBroken, len(comparison.Regressions))
+	}
+	if gh != nil {
+		sha := ""
+		if *githubStatus {
+			sha = orEnv(*githubSHA, "GITHUB_SHA")
+		}
+		publishAudit(gh, orEnv(*githubRepo, "GITHUB_REPOSITORY"), sha, *githubPR, a)
+	}
+	if len(webhooks) > 0 {
+		notifyAudit(webhooks, a)
+	}
+
+	// written last, so that on stdout it is the final line
+	summarize := func() {
+		if *summaryFile == "" {
+			return
+		}
+		if err := writeSummary(*summaryFile, newSummary(a, *errorThreshold, thresholds)); err != nil {
+			log.Printf("Error writing the JSON summary: %v", err)
+		} else if *summaryFile != "-" {
+			fmt.Printf("📋 JSON summary written to %s\n", *summaryFile)
+		}
+	}
+
+	if *ciMode {
+		junitPath := *junitFile
+		if junitPath == "" {
+			junitPath = exportOpts.plainPath("junit.xml")
+		}
+		if err := writeJUnit(junitPath, results, checks); err != nil {
+			log.Printf("Error writing JUnit report: %v", err)
+		} else {
+			fmt.Printf("🧪 JUnit report written to %s\n", junitPath)
+		}
+		summarize()
+		flushTraces()
+		os.Exit(code)
+	}
+
+	// a summary on stdout waits for the dashboard to close, it has to
+	// be the last line of the output
+	if *summaryFile != "-" {
+		summarize()
+	}
 	fmt.Println("🌐 Dashboard available at http://localhost:8080")
 	fmt.Println("\nPress Ctrl+C again to exit dashboard...")
 
 	// Keep dashboard running
+	close(stopForceQuit)
 	<-sigChan
 	fmt.Println("\n👋 Goodbye!")
+	flushTraces()
+	if *summaryFile == "-" {
+		summarize()
+	}
+	os.Exit(code)
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// orEnv returns value, or the environment variable name if value is empty
+func orEnv(value, name string) string {
+	if value == "" {
+		return os.Getenv(name)
+	}
+	return value
+}
+
+// subcommand returns the subcommand given as first argument, "" for a
+// plain crawl, and removes it from the arguments left to flag.Parse
+func subcommand() string {
+	if len(os.Args) < 2 {
+		return ""
+	}
+	switch command := os.Args[1]; command {
+	case initCommand, replayCommand, benchCommand, retryCommand:
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		return command
+	}
+	return ""
+}
+
+// hostOf returns the host of a URL, or "" if it does not parse
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// parseCredentials builds per-host credentials from -basic-auth and
+// -bearer-token values
+func parseCredentials(startURL string, basic, bearer []string) ([]crawler.Credentials, error) {
+	var credentials []crawler.Credentials
+	for i, specs := range [][]string{basic, bearer} {
+		for _, spec := range specs {
+			creds, err := crawler.ParseCredentials(spec, hostOf(startURL), i == 1)
+			if err != nil {
+				return nil, err
+			}
+			credentials = append(credentials, creds)
+		}
+	}
+	return credentials, nil
+}
+
+// loadSitemap reads the sitemap to compare with the crawl, a missing
+// sitemap is logged and leaves the gap report empty
+func loadSitemap(ctx context.Context, c *crawler.Crawler, results *storage.Results, sitemapURL, startURL string) {
+	if sitemapURL == "auto" {
+		var err error
+		if sitemapURL, err = crawler.SitemapURL(startURL); err != nil {
+			log.Printf("Error locating sitemap: %v", err)
+			return
+		}
+	}
+
+	urls, err := c.Sitemap(ctx, sitemapURL)
+	if err != nil {
+		log.Printf("Error reading sitemap: %v", err)
+		return
+	}
+	results.SetSitemap(urls)
+	log.Printf("🗺️  Sitemap %s lists %d URLs", sitemapURL, len(urls))
+}
+
+// printProxyStats lists the success rate of each proxy of the pool
+func printProxyStats(stats []crawler.ProxyStat) {
+	if len(stats) == 0 {
+		return
+	}
+	fmt.Println("🌐 Proxies:")
+	for _, s := range stats {
+		status := ""
+		if s.Evicted {
+			status = " (evicted)"
+		}
+		fmt.Printf("   • %s: %.0f%% of %d requests succeeded%s\n", s.URL, s.SuccessRate()*100, s.Successes+s.Failures, status)
+	}
+}
+
+// printMigration summarizes the -migration-map checks by verdict
+func printMigration(checks []storage.MigrationCheck) {
+	counts := make(map[string]int)
+	for _, c := range checks {
+		counts[c.Verdict]++
+	}
+	fmt.Printf("\n🧭 Migration: %d of %d old URLs redirect to their new URL in one hop\n", counts[storage.MigrationOK], len(checks))
+	for _, verdict := range []string{storage.MigrationChain, storage.MigrationWrongTarget, storage.MigrationNoRedirect, storage.MigrationTargetError, storage.MigrationFailed} {
+		if counts[verdict] > 0 {
+			fmt.Printf("   • %s: %d\n", verdict, counts[verdict])
+		}
+	}
+}
+
+// printConnStats shows how well keep-alive worked: a low reuse rate with
+// few hosts hints at bodies left unread or servers closing connections
+func printConnStats(s crawler.ConnStats) {
+	if s.Requests == 0 {
+		return
+	}
+	fmt.Printf("🔌 Connections: %d requests, %.0f%% on reused keep-alive connections\n", s.Requests, s.ReuseRate()*100)
 }
 
 func printStats(results *storage.Results) {
@@ -118,7 +1001,39 @@ func printStats(results *storage.Results) {
 ✅ Successful:        %d
 ❌ Failed:            %d
 ⚡ Crawl Duration:    %s
+🚫 Noindex/Nofollow:  %d / %d
+♻️  Not Modified:      %d
 
 `, stats.TotalPages, stats.UniqueLinks, stats.AvgResponseTime,
-		stats.SuccessCount, stats.FailCount, stats.Duration)
+		stats.SuccessCount, stats.FailCount, stats.Duration, stats.NoIndex, stats.NoFollow, stats.NotModified)
+	if stats.BreakerTrips > 0 {
+		fmt.Printf("🔌 Breaker Trips:     %d (hosts paused after consecutive failures)\n\n", stats.BreakerTrips)
+	}
+	if stats.Duplicates > 0 {
+		fmt.Printf("👯 Duplicates:        %d (same content as an earlier URL, links not followed)\n\n", stats.Duplicates)
+	}
+	if stats.Downloaded > 0 {
+		fmt.Printf("📦 Downloaded:        %s", formatBytes(stats.Downloaded))
+		if cost := stats.BandwidthCost; cost >= 0.01 {
+			fmt.Printf(" (est. cost %.2f)", cost)
+		} else if cost > 0 {
+			fmt.Print(" (est. cost < 0.01)")
+		}
+		fmt.Print("\n\n")
+	}
+}
+
+// formatBytes renders a byte count with a decimal unit, as metered
+// connections are billed
+func formatBytes(n int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	v, i := float64(n), 0
+	for v >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
 }
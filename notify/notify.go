@@ -0,0 +1,237 @@
+// Package notify posts crawl summaries to Slack, Discord and Microsoft
+// Teams incoming webhooks
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Services
+const (
+	Slack   = "slack"
+	Discord = "discord"
+	Teams   = "teams"
+)
+
+// Severity grades a finished crawl, webhooks are only posted runs at or
+// above their minimum severity
+type Severity int
+
+const (
+	SeverityInfo    Severity = iota // clean run
+	SeverityWarning                 // failed pages or broken links
+	SeverityError                   // failed thresholds or aborted
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity parses info, warning or error
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q (want info, warning or error)", name)
+}
+
+// Webhook is one notification target
+type Webhook struct {
+	Service     string // Slack, Discord or Teams
+	URL         string
+	MinSeverity Severity
+}
+
+// ParseWebhook parses a "service[@severity]=url" flag value, such as
+// "slack=https://hooks.slack.com/services/…" or "teams@error=https://…".
+// Without a severity every run is posted.
+func ParseWebhook(spec string) (Webhook, error) {
+	var hook Webhook
+	target, url, ok := strings.Cut(spec, "=")
+	if !ok || url == "" {
+		return hook, fmt.Errorf("invalid webhook %q (want service[@severity]=url)", spec)
+	}
+	service, severity, hasSeverity := strings.Cut(strings.ToLower(target), "@")
+	switch service {
+	case Slack, Discord, Teams:
+	default:
+		return hook, fmt.Errorf("unknown webhook service %q (want slack, discord or teams)", service)
+	}
+	hook.Service, hook.URL = service, url
+	if hasSeverity {
+		minimum, err := ParseSeverity(severity)
+		if err != nil {
+			return hook, err
+		}
+		hook.MinSeverity = minimum
+	}
+	return hook, nil
+}
+
+// Summary is what a notification says about a finished crawl
+type Summary struct {
+	StartURL  string
+	Severity  Severity
+	Pages     int
+	Failed    int
+	Broken    int // broken internal links
+	NewBroken int // versus the baseline, -1 without one
+	Duration  time.Duration
+	Problems  []string // failed checks and examples of broken links, one line each
+}
+
+// headline is the one-line result of the crawl
+func (s Summary) headline() string {
+	icon := map[Severity]string{SeverityInfo: "✅", SeverityWarning: "⚠️", SeverityError: "❌"}[s.Severity]
+	return fmt.Sprintf("%s Crawl of %s finished", icon, s.StartURL)
+}
+
+// facts are the figures of the crawl as name/value pairs
+func (s Summary) facts() [][2]string {
+	facts := [][2]string{
+		{"Pages", fmt.Sprint(s.Pages)},
+		{"Errors", fmt.Sprint(s.Failed)},
+		{"Broken links", fmt.Sprint(s.Broken)},
+	}
+	if s.NewBroken >= 0 {
+		facts = append(facts, [2]string{"New broken links", fmt.Sprint(s.NewBroken)})
+	}
+	return append(facts, [2]string{"Duration", s.Duration.Round(time.Second).String()})
+}
+
+// Send posts s to hook unless it is below the hook's minimum severity
+func Send(ctx context.Context, hook Webhook, s Summary) (sent bool, err error) {
+	if s.Severity < hook.MinSeverity {
+		return false, nil
+	}
+	var payload any
+	switch hook.Service {
+	case Slack:
+		payload = slackPayload(s)
+	case Discord:
+		payload = discordPayload(s)
+	case Teams:
+		payload = teamsPayload(s)
+	default:
+		return false, fmt.Errorf("unknown webhook service %q", hook.Service)
+	}
+	return true, post(ctx, hook.URL, payload)
+}
+
+// slackEscaper escapes the characters Slack reserves for links and mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackPayload is a message with the facts in one mrkdwn section
+func slackPayload(s Summary) any {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*%s*\n", s.headline())
+	for _, f := range s.facts() {
+		fmt.Fprintf(&text, "%s: *%s*   ", f[0], f[1])
+	}
+	for _, p := range s.Problems {
+		fmt.Fprintf(&text, "\n• %s", p)
+	}
+	return map[string]any{
+		"text": slackEscaper.Replace(s.headline()),
+		"blocks": []any{
+			map[string]any{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": truncate(slackEscaper.Replace(text.String()), 3000)}},
+		},
+	}
+}
+
+// discordColors are the embed side colors per severity
+var discordColors = map[Severity]int{SeverityInfo: 0x48bb78, SeverityWarning: 0xed8936, SeverityError: 0xf56565}
+
+// discordPayload is an embed with the facts as inline fields
+func discordPayload(s Summary) any {
+	var fields []map[string]any
+	for _, f := range s.facts() {
+		fields = append(fields, map[string]any{"name": f[0], "value": f[1], "inline": true})
+	}
+	embed := map[string]any{
+		"title":  s.headline(),
+		"url":    s.StartURL,
+		"color":  discordColors[s.Severity],
+		"fields": fields,
+	}
+	if len(s.Problems) > 0 {
+		// descriptions are capped at 4096 characters
+		embed["description"] = truncate("• "+strings.Join(s.Problems, "\n• "), 4096)
+	}
+	return map[string]any{"embeds": []any{embed}}
+}
+
+// teamsPayload is an Adaptive Card, accepted by Workflows webhooks and
+// the older Office 365 connectors alike
+func teamsPayload(s Summary) any {
+	var facts []map[string]string
+	for _, f := range s.facts() {
+		facts = append(facts, map[string]string{"title": f[0], "value": f[1]})
+	}
+	body := []any{
+		map[string]any{"type": "TextBlock", "text": s.headline(), "weight": "Bolder", "size": "Medium", "wrap": true},
+		map[string]any{"type": "FactSet", "facts": facts},
+	}
+	if len(s.Problems) > 0 {
+		body = append(body, map[string]any{"type": "TextBlock", "text": "- " + strings.Join(s.Problems, "\n- "), "wrap": true})
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{map[string]any{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// truncate cuts s to at most n runes, marking the cut
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
+
+// post sends payload as JSON to a webhook URL
+func post(ctx context.Context, url string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Article is the main content of a page with its metadata, extracted
+// readability-style
+type Article struct {
+	Title     string
+	Author    string
+	Published string // as written in the page, usually ISO 8601
+	Text      string // one line per paragraph
+	Words     int
+}
+
+var (
+	// positiveNames hint at content in class and id attributes
+	positiveNames = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|text|blog|story`)
+	// negativeNames hint at page chrome
+	negativeNames = regexp.MustCompile(`(?i)comment|footer|sidebar|nav|menu|share|social|related|promo|banner|sponsor|advert|cookie|popup|widget`)
+	// titleSuffix is the site name usually appended to <title>
+	titleSuffix = regexp.MustCompile(`\s+[|\-–—»·]\s+[^|\-–—»·]+$`)
+)
+
+// chromeElements are never part of the main content
+var chromeElements = map[string]bool{
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"button": true, "select": true, "textarea": true,
+}
+
+// extractArticle finds the element holding the main content: paragraphs
+// score their parent and grandparent by length and commas, scores are
+// weighted by class/id names and the share of link text
+func extractArticle(doc *html.Node, info *PageInfo) *Article {
+	article := &Article{}
+	readMetadata(doc, article)
+	if article.Title == "" {
+		article.Title = strings.TrimSpace(titleSuffix.ReplaceAllString(info.Title, ""))
+	}
+
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node // in document order, so ties pick the first
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if hiddenElements[n.Data] || chromeElements[n.Data] || unlikely(n) {
+				return
+			}
+			if n.Data == "p" || n.Data == "pre" || n.Data == "td" || n.Data == "blockquote" {
+				candidates = scoreParagraph(n, scores, candidates)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var top *html.Node
+	best := 0.0
+	for _, n := range candidates {
+		if score := scores[n] * (1 - linkDensity(n)); score > best {
+			top, best = n, score
+		}
+	}
+	if top == nil {
+		return article
+	}
+
+	article.Text = visibleText(top, Options{})
+	article.Words = len(strings.Fields(article.Text))
+	return article
+}
+
+// scoreParagraph adds the score of a paragraph to its parent and
+// grandparent, returning candidates with the newly scored ones appended
+func scoreParagraph(p *html.Node, scores map[*html.Node]float64, candidates []*html.Node) []*html.Node {
+	text := strings.Join(strings.Fields(textOf(p)), " ")
+	if len(text) < 25 {
+		return candidates
+	}
+	score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+
+	parent := p.Parent
+	for level := 0; parent != nil && parent.Type == html.ElementNode && level < 2; level++ {
+		if _, ok := scores[parent]; !ok {
+			scores[parent] = classWeight(parent)
+			candidates = append(candidates, parent)
+		}
+		if level == 0 {
+			scores[parent] += score
+		} else {
+			scores[parent] += score / 2
+		}
+		parent = parent.Parent
+	}
+	return candidates
+}
+
+// classWeight scores an element by its class and id names
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	if n.Data == "article" || n.Data == "main" {
+		weight += 25
+	}
+	for _, name := range []string{getAttr(n, "class"), getAttr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeNames.MatchString(name) {
+			weight -= 25
+		}
+		if positiveNames.MatchString(name) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// unlikely reports elements whose names or roles mark them as chrome
+func unlikely(n *html.Node) bool {
+	switch getAttr(n, "role") {
+	case "navigation", "banner", "contentinfo", "complementary", "dialog":
+		return true
+	}
+	if n.Data == "body" || n.Data == "article" || n.Data == "main" {
+		return false
+	}
+	names := getAttr(n, "class") + " " + getAttr(n, "id")
+	return negativeNames.MatchString(names) && !positiveNames.MatchString(names)
+}
+
+// linkDensity is the share of an element's text inside links
+func linkDensity(n *html.Node) float64 {
+	total := len(strings.Join(strings.Fields(textOf(n)), " "))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			linked += len(strings.Join(strings.Fields(textOf(n)), " "))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return float64(linked) / float64(total)
+}
+
+// textOf concatenates the visible text nodes below n
+func textOf(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && hiddenElements[n.Data] {
+			return
+		}
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// readMetadata fills the title, author and publication date from meta
+// tags, schema.org microdata, rel=author links and <time> elements,
+// the first value found wins
+func readMetadata(doc *html.Node, article *Article) {
+	set := func(field *string, value string) {
+		if value = strings.Join(strings.Fields(value), " "); *field == "" && value != "" {
+			*field = value
+		}
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "meta":
+				content := getAttr(n, "content")
+				switch strings.ToLower(getAttr(n, "property") + getAttr(n, "name")) {
+				case "og:title", "twitter:title":
+					set(&article.Title, content)
+				case "author", "article:author", "dc.creator":
+					set(&article.Author, content)
+				case "article:published_time", "dc.date", "date", "pubdate":
+					set(&article.Published, content)
+				}
+			case "time":
+				if datetime := getAttr(n, "datetime"); datetime != "" {
+					set(&article.Published, datetime)
+				}
+			case "a":
+				if strings.Contains(strings.ToLower(getAttr(n, "rel")), "author") {
+					set(&article.Author, textOf(n))
+				}
+			}
+			switch getAttr(n, "itemprop") {
+			case "author":
+				set(&article.Author, textOf(n))
+			case "datePublished":
+				set(&article.Published, getAttr(n, "content")+getAttr(n, "datetime"))
+			case "headline":
+				set(&article.Title, textOf(n))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector matches elements by tag, id and class, e.g. "footer",
+// ".cookie-banner", "#updated" or "div.promo"
+type Selector struct {
+	Tag   string
+	ID    string
+	Class string
+}
+
+var selectorPattern = regexp.MustCompile(`^([a-z][a-z0-9-]*)?(?:#([\w-]+)|\.([\w-]+))?$`)
+
+// ParseSelectors parses a comma-separated selector list
+func ParseSelectors(s string) ([]Selector, error) {
+	var selectors []Selector
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		m := selectorPattern.FindStringSubmatch(strings.ToLower(part))
+		if m == nil || m[0] == "" {
+			return nil, fmt.Errorf("unsupported selector %q (want tag, #id, .class, tag#id or tag.class)", part)
+		}
+		selectors = append(selectors, Selector{Tag: m[1], ID: m[2], Class: m[3]})
+	}
+	return selectors, nil
+}
+
+// matches reports whether element n matches the selector
+func (s Selector) matches(n *html.Node) bool {
+	if s.Tag != "" && n.Data != s.Tag {
+		return false
+	}
+	if s.ID != "" && strings.ToLower(getAttr(n, "id")) != s.ID {
+		return false
+	}
+	if s.Class != "" {
+		for _, class := range strings.Fields(strings.ToLower(getAttr(n, "class"))) {
+			if class == s.Class {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Options changes what Parse extracts
+type Options struct {
+	Strip       []Selector // regions left out of Text
+	AutoStrip   bool       // also leave out site headers, footers, navigation and sidebars
+	RobotsAgent string     // crawler name whose <meta name> directives are read like meta robots
+}
+
+// boilerplate returns whether an element is left out of the visible
+// text. Automatic detection only strips landmarks outside the main
+// content, so an article's own header stays.
+func (o Options) boilerplate(n *html.Node, inContent bool) bool {
+	for _, s := range o.Strip {
+		if s.matches(n) {
+			return true
+		}
+	}
+	if !o.AutoStrip || inContent {
+		return false
+	}
+	switch n.Data {
+	case "header", "footer", "nav", "aside":
+		return true
+	}
+	if position, ok := landmarkRoles[strings.ToLower(getAttr(n, "role"))]; ok {
+		return position != PositionContent
+	}
+	position := namedPosition(getAttr(n, "id") + " " + getAttr(n, "class"))
+	return position != "" && position != PositionContent
+}
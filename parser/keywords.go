@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"gocrawler/storage"
+)
+
+// stopwords are frequent English words that carry no topic
+var stopwords = toSet(`a about above after again against all also am an and any are as at be
+because been before being below between both but by can could did do does doing down during
+each few for from further had has have having he her here hers herself him himself his how i
+if in into is it its itself just let me more most my myself no nor not now of off on once only
+or other our ours ourselves out over own same she should so some such than that the their
+theirs them themselves then there these they this those through to too under until up very was
+we were what when where which while who whom why will with would you your yours yourself
+yourselves get got like may might must one two us use used using via will within without yet`)
+
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// Keywords counts the words of text ignoring case, stopwords, numbers
+// and words shorter than 3 letters, returning the n most frequent
+func Keywords(text string, n int) []storage.Term {
+	counts := make(map[string]int)
+	for _, word := range words(text) {
+		w := strings.ToLower(word)
+		if len([]rune(w)) < 3 || stopwords[w] || !hasLetter(w) {
+			continue
+		}
+		counts[w]++
+	}
+	return top(counts, n)
+}
+
+// Entities finds runs of capitalized words such as "Ada Lovelace" or
+// "New York", returning the n most frequent. A single capitalized word
+// opening a sentence or line is only counted when it also appears
+// elsewhere, since capitalization there says nothing.
+func Entities(text string, n int) []storage.Term {
+	counts := make(map[string]int)
+	openers := make(map[string]int) // single words only seen opening sentences
+
+	for _, line := range strings.Split(text, "\n") {
+		for _, sentence := range sentences(line) {
+			tokens := words(sentence)
+			for i := 0; i < len(tokens); {
+				if !capitalized(tokens[i]) || stopwords[strings.ToLower(tokens[i])] {
+					i++
+					continue
+				}
+				j := i + 1
+				for j < len(tokens) && capitalized(tokens[j]) {
+					j++
+				}
+				entity := strings.Join(tokens[i:j], " ")
+				if i == 0 && j == 1 {
+					openers[entity]++
+				} else {
+					counts[entity]++
+				}
+				i = j
+			}
+		}
+	}
+	for entity, n := range openers {
+		if counts[entity] > 0 {
+			counts[entity] += n
+		}
+	}
+	return top(counts, n)
+}
+
+// words splits text into words of letters, digits and inner
+// apostrophes or hyphens
+func words(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if w := strings.Trim(f, "'-"); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// sentences splits a line at sentence punctuation
+func sentences(line string) []string {
+	return strings.FieldsFunc(line, func(r rune) bool {
+		return r == '.' || r == '!' || r == '?' || r == ':' || r == ';'
+	})
+}
+
+func capitalized(word string) bool {
+	for _, r := range word {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+func hasLetter(word string) bool {
+	return strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
+// top returns the n most frequent terms, ties alphabetically
+func top(counts map[string]int, n int) []storage.Term {
+	terms := make([]storage.Term, 0, len(counts))
+	for term, count := range counts {
+		terms = append(terms, storage.Term{Term: term, Count: count})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	if n > 0 && len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
@@ -5,17 +5,65 @@ import (
 	"strings"
 
 	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
 )
 
+// Link positions, from the closest landmark around the link
+const (
+	PositionNav     = "nav"
+	PositionHeader  = "header"
+	PositionFooter  = "footer"
+	PositionSidebar = "sidebar"
+	PositionContent = "content" // main content, or no recognizable landmark
+)
+
+// Link is one <a href> occurrence on a page
+type Link struct {
+	Href     string // as written in the page
+	Text     string // anchor text, or the alt text of a linked image
+	Position string // Position* constant
+	Rel      string // rel tokens such as "nofollow sponsored", lowercased
+}
+
+// Embed is an iframe or media resource embedded in a page
+type Embed struct {
+	Kind string // iframe, video, audio, embed or object
+	Src  string
+}
+
 // PageInfo contains extracted information from a page
 type PageInfo struct {
 	Title       string
 	Description string
 	Links       []string
+	Anchors     []Link   // every link occurrence, Links holds the unique hrefs
+	Next        string   // rel="next" pagination link
+	Prev        string   // rel="prev" pagination link
+	AMP         string   // rel="amphtml" version of the page
+	Mobile      string   // rel="alternate" with a media query (separate mobile URL)
+	Robots      string   // meta robots and Options.RobotsAgent directives, comma-separated
+	Icons       []string // rel="icon", "apple-touch-icon", ... favicons
+	Manifest    string   // rel="manifest" web app manifest
+	OGImage     string   // first og:image
+	Canonical   string   // rel="canonical" URL
+	Refresh     string   // meta refresh target, as written
+	Embeds      []Embed  // iframes, video/audio sources, <embed> and <object>
+	Scripts     []string // external <script src> URLs
+	Inline      []string // inline <script> contents
+	Comments    []string // HTML comments
+	Text        string   // visible body text, one line per block element
+	Headings    []string // h1-h6 texts in document order
+	Lang        string   // <html lang> declared language, as written
+	Article     *Article // main content and metadata
 }
 
 // Parse extracts information from HTML content
 func Parse(body io.Reader, baseURL string) (*PageInfo, error) {
+	return ParseWith(body, baseURL, Options{})
+}
+
+// ParseWith is Parse with extraction options
+func ParseWith(body io.Reader, baseURL string, opts Options) (*PageInfo, error) {
 	doc, err := html.Parse(body)
 	if err != nil {
 		return nil, err
@@ -28,11 +76,20 @@ func Parse(body io.Reader, baseURL string) (*PageInfo, error) {
 	// Traverse DOM and extract data
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if n.Type == html.CommentNode {
+			info.Comments = append(info.Comments, n.Data)
+		}
 		if n.Type == html.ElementNode {
 			switch n.Data {
+			case "html":
+				info.Lang = strings.TrimSpace(getAttr(n, "lang"))
 			case "title":
 				if n.FirstChild != nil {
-					info.Title = strings.TrimSpace(n.FirstChild.Data)
+					info.Title = cleanText(n.FirstChild.Data)
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				if text := cleanText(strings.Join(strings.Fields(textOf(n)), " ")); text != "" {
+					info.Headings = append(info.Headings, text)
 				}
 			case "meta":
 				// Extract meta description
@@ -46,15 +103,58 @@ func Parse(body io.Reader, baseURL string) (*PageInfo, error) {
 					}
 				}
 				if name == "description" {
-					info.Description = content
+					info.Description = cleanText(content)
+				}
+				if prop := getAttr(n, "property"); (prop == "og:image" || prop == "og:image:url") && info.OGImage == "" {
+					info.OGImage = strings.TrimSpace(content)
+				}
+				if strings.EqualFold(getAttr(n, "http-equiv"), "refresh") && info.Refresh == "" {
+					info.Refresh = RefreshURL(content)
+				}
+				if metaName := getAttr(n, "name"); strings.EqualFold(metaName, "robots") ||
+					(opts.RobotsAgent != "" && strings.EqualFold(metaName, opts.RobotsAgent)) {
+					if info.Robots != "" {
+						info.Robots += ","
+					}
+					info.Robots += getAttr(n, "content")
+				}
+			case "link":
+				// Pagination and alternate version hints in <head>
+				rel, href := getAttr(n, "rel"), strings.TrimSpace(getAttr(n, "href"))
+				info.setPagination(rel, href)
+				info.setAlternate(rel, getAttr(n, "media"), href)
+				info.setIcon(rel, href)
+				if strings.EqualFold(strings.TrimSpace(rel), "canonical") && href != "" && info.Canonical == "" {
+					info.Canonical = href
+				}
+			case "script":
+				if src := strings.TrimSpace(getAttr(n, "src")); src != "" {
+					info.Scripts = append(info.Scripts, src)
+				} else if n.FirstChild != nil && strings.TrimSpace(n.FirstChild.Data) != "" {
+					info.Inline = append(info.Inline, n.FirstChild.Data)
+				}
+			case "iframe", "video", "audio", "embed":
+				info.addEmbed(n.Data, getAttr(n, "src"))
+			case "object":
+				info.addEmbed(n.Data, getAttr(n, "data"))
+			case "source":
+				if n.Parent != nil && (n.Parent.Data == "video" || n.Parent.Data == "audio") {
+					info.addEmbed(n.Parent.Data, getAttr(n, "src"))
 				}
 			case "a":
 				// Extract links
 				for _, attr := range n.Attr {
 					if attr.Key == "href" {
 						href := strings.TrimSpace(attr.Val)
-						if href != "" && !strings.HasPrefix(href, "#") && !strings.HasPrefix(href, "javascript:") {
+						if href != "" && (!strings.HasPrefix(href, "#") || isRouteHref(href)) && !strings.HasPrefix(href, "javascript:") {
 							info.Links = append(info.Links, href)
+							info.Anchors = append(info.Anchors, Link{
+								Href:     href,
+								Text:     anchorText(n),
+								Position: linkPosition(n),
+								Rel:      strings.Join(strings.Fields(strings.ToLower(getAttr(n, "rel"))), " "),
+							})
+							info.setPagination(getAttr(n, "rel"), href)
 						}
 					}
 				}
@@ -71,10 +171,211 @@ func Parse(body io.Reader, baseURL string) (*PageInfo, error) {
 
 	// Remove duplicate links
 	info.Links = uniqueStrings(info.Links)
+	info.Text = visibleText(doc, opts)
+	info.Article = extractArticle(doc, info)
 
 	return info, nil
 }
 
+// setPagination records rel=next/prev targets, first occurrence wins
+func (info *PageInfo) setPagination(rel, href string) {
+	if href == "" {
+		return
+	}
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		switch token {
+		case "next":
+			if info.Next == "" {
+				info.Next = href
+			}
+		case "prev", "previous":
+			if info.Prev == "" {
+				info.Prev = href
+			}
+		}
+	}
+}
+
+// setAlternate records AMP and mobile alternate versions of the page.
+// hreflang alternates have no media query and are ignored here.
+func (info *PageInfo) setAlternate(rel, media, href string) {
+	if href == "" {
+		return
+	}
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		switch {
+		case token == "amphtml" && info.AMP == "":
+			info.AMP = href
+		case token == "alternate" && media != "" && info.Mobile == "":
+			info.Mobile = href
+		}
+	}
+}
+
+// addEmbed records an embedded resource, skipping empty and inline sources
+func (info *PageInfo) addEmbed(kind, src string) {
+	src = strings.TrimSpace(src)
+	if src == "" || strings.HasPrefix(src, "data:") || strings.HasPrefix(src, "javascript:") || src == "about:blank" {
+		return
+	}
+	info.Embeds = append(info.Embeds, Embed{Kind: kind, Src: src})
+}
+
+// setIcon records favicons and the web app manifest
+func (info *PageInfo) setIcon(rel, href string) {
+	if href == "" {
+		return
+	}
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		switch {
+		case token == "manifest" && info.Manifest == "":
+			info.Manifest = href
+		case token == "icon" || token == "apple-touch-icon" || token == "mask-icon":
+			info.Icons = append(info.Icons, href)
+			return
+		}
+	}
+}
+
+// anchorText returns the visible text of a link with whitespace
+// collapsed, falling back to the alt text of images inside it
+func anchorText(n *html.Node) string {
+	var text, alt []string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			text = append(text, n.Data)
+		case n.Type == html.ElementNode && n.Data == "img":
+			alt = append(alt, getAttr(n, "alt"))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	if s := strings.Join(strings.Fields(strings.Join(text, " ")), " "); s != "" {
+		return s
+	}
+	return strings.Join(strings.Fields(strings.Join(alt, " ")), " ")
+}
+
+// landmarkRoles maps ARIA roles to link positions
+var landmarkRoles = map[string]string{
+	"navigation":    PositionNav,
+	"banner":        PositionHeader,
+	"contentinfo":   PositionFooter,
+	"complementary": PositionSidebar,
+	"main":          PositionContent,
+}
+
+// linkPosition classifies a link by its closest landmark ancestor:
+// HTML5 sectioning elements, ARIA roles, then common id/class names
+func linkPosition(n *html.Node) string {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type != html.ElementNode {
+			continue
+		}
+		switch p.Data {
+		case "nav":
+			return PositionNav
+		case "header":
+			return PositionHeader
+		case "footer":
+			return PositionFooter
+		case "aside":
+			return PositionSidebar
+		case "main", "article":
+			return PositionContent
+		}
+		if position, ok := landmarkRoles[strings.ToLower(getAttr(p, "role"))]; ok {
+			return position
+		}
+		if position := namedPosition(getAttr(p, "id") + " " + getAttr(p, "class")); position != "" {
+			return position
+		}
+	}
+	return PositionContent
+}
+
+// namedPositions maps words of id/class names to link positions
+var namedPositions = map[string]string{
+	"nav":         PositionNav,
+	"navbar":      PositionNav,
+	"navigation":  PositionNav,
+	"menu":        PositionNav,
+	"breadcrumb":  PositionNav,
+	"breadcrumbs": PositionNav,
+	"header":      PositionHeader,
+	"masthead":    PositionHeader,
+	"footer":      PositionFooter,
+	"sidebar":     PositionSidebar,
+	"content":     PositionContent,
+	"main":        PositionContent,
+}
+
+// namedPosition guesses a position from id/class names like "main-nav",
+// boilerplate words win over "main"/"content"
+func namedPosition(names string) string {
+	words := strings.FieldsFunc(strings.ToLower(names), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	})
+	found := ""
+	for _, word := range words {
+		position, ok := namedPositions[word]
+		if ok && position != PositionContent {
+			return position
+		}
+		if ok {
+			found = position
+		}
+	}
+	return found
+}
+
+// isRouteHref reports whether an in-page href looks like an SPA route
+// ("#!/page", "#/page") rather than an anchor on the same page
+func isRouteHref(href string) bool {
+	return strings.HasPrefix(href, "#!") || strings.HasPrefix(href, "#/")
+}
+
+// RefreshURL extracts the target of a meta refresh or Refresh header
+// value such as "0; url='/next'", or "" when it only reloads the page
+func RefreshURL(content string) string {
+	_, target, ok := strings.Cut(content, ";")
+	if !ok {
+		_, target, ok = strings.Cut(content, ",")
+	}
+	if !ok {
+		return ""
+	}
+	target = strings.TrimSpace(target)
+	if len(target) >= 4 && strings.EqualFold(target[:3], "url") {
+		if rest := strings.TrimSpace(target[3:]); strings.HasPrefix(rest, "=") {
+			target = strings.TrimSpace(rest[1:])
+		}
+	}
+	return strings.Trim(target, `'"`)
+}
+
+// cleanText trims extracted text and composes it to NFC, so that titles
+// typed with combining characters (e + U+0301) compare, search and export
+// the same as precomposed ones (é)
+func cleanText(s string) string {
+	return norm.NFC.String(strings.TrimSpace(s))
+}
+
+// getAttr returns the value of an attribute or "" if missing
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
 // uniqueStrings removes duplicates from string slice
 func uniqueStrings(slice []string) []string {
 	seen := make(map[string]bool)
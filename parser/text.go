@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// hiddenElements never contribute visible text
+var hiddenElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true,
+	"template": true, "svg": true, "iframe": true, "object": true,
+}
+
+// blockElements start a new line of text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "td": true, "th": true, "tr": true,
+	"ul": true,
+}
+
+// visibleText returns the text of the document body, one line per
+// block element with whitespace collapsed, so line diffs between runs
+// follow the page structure. Boilerplate regions of opts are left out.
+func visibleText(doc *html.Node, opts Options) string {
+	var lines []string
+	var line strings.Builder
+	flush := func() {
+		if s := strings.Join(strings.Fields(line.String()), " "); s != "" {
+			lines = append(lines, s)
+		}
+		line.Reset()
+	}
+
+	var walk func(*html.Node, bool)
+	walk = func(n *html.Node, inContent bool) {
+		if n.Type == html.ElementNode && hiddenElements[n.Data] {
+			return
+		}
+		if n.Type == html.ElementNode && opts.boilerplate(n, inContent) {
+			flush()
+			return
+		}
+		inContent = inContent || n.Type == html.ElementNode && (n.Data == "main" || n.Data == "article")
+		if n.Type == html.TextNode {
+			line.WriteString(n.Data)
+			line.WriteByte(' ')
+		}
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inContent)
+		}
+		if block {
+			flush()
+		}
+	}
+	walk(doc, false)
+	flush()
+
+	return strings.Join(lines, "\n")
+}
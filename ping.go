@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"gocrawler/storage"
+)
+
+// publicSitemapURL is where search engines fetch the generated sitemap,
+// by default /sitemap.xml on the start URL's origin
+func publicSitemapURL(publicURL, startURL string) (string, error) {
+	if publicURL != "" {
+		return publicURL, nil
+	}
+	u, err := url.Parse(startURL)
+	if err != nil {
+		return "", err
+	}
+	return u.Scheme + "://" + u.Host + "/sitemap.xml", nil
+}
+
+// pingSitemap tells every endpoint about the sitemap with a GET request
+// adding ?sitemap=<url>, failures are logged and don't stop the others
+func pingSitemap(ctx context.Context, endpoints []string, sitemapURL string) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			log.Printf("Error pinging %s: %v", endpoint, err)
+			continue
+		}
+		q := u.Query()
+		q.Set("sitemap", sitemapURL)
+		u.RawQuery = q.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			log.Printf("Error pinging %s: %v", endpoint, err)
+			continue
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Printf("Error pinging %s: %v", endpoint, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("Error pinging %s: %s", endpoint, resp.Status)
+			continue
+		}
+		log.Printf("📣 Sitemap %s pinged to %s", sitemapURL, u.Host)
+	}
+}
+
+// indexNowSubmission is the JSON body of an IndexNow bulk submission
+type indexNowSubmission struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation,omitempty"`
+	URLList     []string `json:"urlList"`
+}
+
+// changedURLs lists the indexable pages that are new or whose text
+// changed since their last snapshot; pages search engines may not index
+// are left out
+func changedURLs(results *storage.Results, snapshots *storage.SnapshotStore) []string {
+	indexable := make(map[string]bool)
+	for _, u := range results.IndexableURLs() {
+		indexable[u] = true
+	}
+
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(u string) {
+		if indexable[u] && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	for _, change := range results.TextChanges() {
+		add(change.URL)
+	}
+	for _, u := range snapshots.Added() {
+		add(u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// writeIndexNow writes an IndexNow submission for the changed URLs of a
+// run, ready to be POSTed to https://api.indexnow.org/indexnow
+func writeIndexNow(filename, startURL, key, keyLocation string, urls []string) error {
+	submission := indexNowSubmission{
+		Host:        hostOf(startURL),
+		Key:         key,
+		KeyLocation: keyLocation,
+		URLList:     urls,
+	}
+	if submission.URLList == nil {
+		submission.URLList = []string{}
+	}
+	data, err := json.MarshalIndent(submission, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// publishSitemap pings the sitemap endpoints and writes the IndexNow
+// submission after a crawl
+func publishSitemap(results *storage.Results, snapshots *storage.SnapshotStore, opts exportOptions, startURL, publicURL string, endpoints []string, indexNowKey, keyLocation string) {
+	if len(endpoints) > 0 {
+		sitemapURL, err := publicSitemapURL(publicURL, startURL)
+		if err != nil {
+			log.Printf("Error building sitemap URL: %v", err)
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			pingSitemap(ctx, endpoints, sitemapURL)
+			cancel()
+		}
+	}
+	if indexNowKey != "" {
+		urls := changedURLs(results, snapshots)
+		path := opts.plainPath("indexnow.json")
+		if err := writeIndexNow(path, startURL, indexNowKey, keyLocation, urls); err != nil {
+			log.Printf("Error writing IndexNow submission: %v", err)
+			return
+		}
+		fmt.Printf("📣 IndexNow submission with %d changed URLs written to %s\n", len(urls), path)
+	}
+}
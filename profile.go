@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// profile bundles politeness settings for -profile
+type profile struct {
+	workers      int
+	rate         int
+	delay        time.Duration
+	ignoreRobots bool
+}
+
+// profiles are the -profile presets. "normal" matches the flag defaults.
+var profiles = map[string]profile{
+	"polite":     {workers: 2, rate: 1, delay: 2 * time.Second},
+	"normal":     {workers: 10, rate: 10},
+	"aggressive": {workers: 50, rate: 100, ignoreRobots: true},
+}
+
+// applyProfile overwrites the settings of a preset, except those given
+// explicitly on the command line
+func applyProfile(name string, workers, rate *int, delay *time.Duration, ignoreRobots *bool) error {
+	if name == "" {
+		return nil
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown -profile %q (want polite, normal or aggressive)", name)
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["workers"] {
+		*workers = p.workers
+	}
+	if !set["rate"] {
+		*rate = p.rate
+	}
+	if !set["delay"] {
+		*delay = p.delay
+	}
+	if !set["ignore-robots"] {
+		*ignoreRobots = p.ignoreRobots
+	}
+	return nil
+}
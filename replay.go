@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gocrawler/crawler"
+)
+
+// replayCommand is the first argument selecting the replay subcommand:
+//
+//	gocrawler replay [flags] <url>
+//
+// It takes the crawl flags, so a URL is replayed with the same scope,
+// parameter policies, headers and extraction settings as a crawl.
+const replayCommand = "replay"
+
+// replay runs the URL given after the flags through the crawl pipeline,
+// explaining every decision, then prints the page as results.json would
+// hold it. The scope is bound to -url when set, to the URL itself
+// otherwise. It returns the exit code.
+func replay(cfg crawler.Config, startURL string) int {
+	if flag.NArg() != 1 {
+		log.Fatal("usage: gocrawler replay [flags] <url>")
+	}
+	target := flag.Arg(0)
+	urlSet := false
+	flag.Visit(func(f *flag.Flag) { urlSet = urlSet || f.Name == "url" })
+	if !urlSet {
+		startURL = target
+	}
+
+	page := crawler.New(cfg).Replay(context.Background(), startURL, target, 0, os.Stdout)
+	if page == nil {
+		return exitErrors
+	}
+	fmt.Println("\n📄 Stored page:")
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(page)
+	if !page.Success {
+		return exitErrors
+	}
+	return exitOK
+}
@@ -0,0 +1,109 @@
+// Package report renders the HTML audit report of a crawl with Go's
+// html/template. The built-in template (default.html) can be replaced
+// with -report-template to brand reports; custom templates are executed
+// with a Data and can use the functions in Funcs.
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gocrawler/storage"
+)
+
+//go:embed default.html
+var defaultTemplate string
+
+// Data is what report templates are executed with. Pages and the other
+// page lists hold *storage.Page values: .URL, .Title, .Description,
+// .StatusCode, .Success, .Error, .ErrorType, .Depth, .ResponseTime,
+// .Size, .Links, .Anchors, .Redirects, .Canonical, .Language, .NoIndex,
+// .DuplicateOf and the other exported fields of results.json.
+type Data struct {
+	StartURL   string
+	Generated  time.Time
+	Stats      storage.Stats            // .TotalPages, .SuccessCount, .FailCount, .StatusCodes, .ErrorTypes, .Depths, ...
+	Hosts      []storage.HostStats      // .Host, .Pages, .Errors, .AvgResponseTime, .Bytes, ...
+	Pages      []*storage.Page          // every page in crawl order
+	Failed     []*storage.Page          // pages that failed, in crawl order
+	Slowest    []*storage.Page          // the -top slowest successful pages
+	Broken     []storage.BrokenLink     // .Source, .Target, .Text, .StatusCode, .ErrorType, .External, .ArchiveURL
+	Duplicates []storage.DuplicateGroup // .Canonical, .Aliases
+	Missing    []string                 // URLs of successful pages without a title
+}
+
+// NewData collects the report data of a finished crawl
+func NewData(results *storage.Results, startURL string, top int) Data {
+	data := Data{
+		StartURL:   startURL,
+		Generated:  time.Now(),
+		Stats:      results.GetStats(),
+		Hosts:      results.HostStats(),
+		Pages:      results.GetPages(),
+		Slowest:    results.Slowest(top),
+		Broken:     results.BrokenLinks(),
+		Duplicates: results.DuplicateGroups(),
+		Missing:    results.MissingTitles(),
+	}
+	for _, page := range data.Pages {
+		if !page.Success {
+			data.Failed = append(data.Failed, page)
+		}
+	}
+	return data
+}
+
+// Funcs are available to report templates, and to the dashboard's
+// page list template
+var Funcs = template.FuncMap{
+	// ms formats a duration as milliseconds: {{ms .ResponseTime}}
+	"ms": func(d time.Duration) string {
+		return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+	},
+	// bytes formats a byte count with decimal units: {{bytes .Size}}
+	"bytes": func(n int64) string {
+		const units = "kMGT"
+		if n < 1000 {
+			return fmt.Sprintf("%d B", n)
+		}
+		v, i := float64(n)/1000, 0
+		for v >= 1000 && i < len(units)-1 {
+			v /= 1000
+			i++
+		}
+		return fmt.Sprintf("%.1f %cB", v, units[i])
+	},
+	// percent formats part of total: {{percent .Stats.SuccessCount .Stats.TotalPages}}
+	"percent": func(part, total int) string {
+		if total == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.0f%%", float64(part)*100/float64(total))
+	},
+	// join concatenates strings: {{join .Aliases ", "}}
+	"join": strings.Join,
+	// displayURL shows IDN hosts and UTF-8 paths readably: {{displayURL .URL}}
+	"displayURL": DisplayURL,
+}
+
+// Load parses the report template in file, or the built-in one when file
+// is empty
+func Load(file string) (*template.Template, error) {
+	if file == "" {
+		return template.New("report").Funcs(Funcs).Parse(defaultTemplate)
+	}
+	return template.New(filepath.Base(file)).Funcs(Funcs).ParseFiles(file)
+}
+
+// Write renders the report into filename, only replacing an existing
+// report once it rendered completely
+func Write(filename string, tmpl *template.Template, data Data) error {
+	return storage.WriteFile(filename, func(w io.Writer) error {
+		return tmpl.Execute(w, data)
+	})
+}
@@ -0,0 +1,98 @@
+package report
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// DisplayURL is rawURL the way browsers show it in their address bar:
+// punycode hosts (xn--) in their Unicode form and percent-encoded UTF-8
+// in paths and queries decoded. Escaped ASCII such as %2F or %20 is kept
+// so the displayed URL still reads unambiguously. It is meant for display
+// only, links must keep using the raw URL.
+func DisplayURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return decodeUTF8Escapes(rawURL)
+	}
+	if !strings.Contains(u.Host, "xn--") {
+		return decodeUTF8Escapes(rawURL)
+	}
+	if host, err := idna.Display.ToUnicode(u.Hostname()); err == nil {
+		if port := u.Port(); port != "" {
+			host += ":" + port
+		}
+		u.Host = host
+	}
+	return decodeUTF8Escapes(u.String())
+}
+
+// decodeUTF8Escapes decodes the runs of %XX escapes of s that spell
+// non-ASCII UTF-8 characters, leaving every other escape untouched.
+// Invisible characters stay escaped like browsers keep them: decoded,
+// bidi controls (U+202E) or zero-width spaces would make a crawled URL
+// display as a different one.
+func decodeUTF8Escapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		var run []byte
+		j := i
+		for j+2 < len(s) && s[j] == '%' {
+			c, ok := unhex(s[j+1], s[j+2])
+			if !ok || c < utf8.RuneSelf {
+				break
+			}
+			run = append(run, c)
+			j += 3
+		}
+		if len(run) > 0 && utf8.Valid(run) {
+			for len(run) > 0 {
+				r, size := utf8.DecodeRune(run)
+				if spoofable(r) {
+					b.WriteString(s[i : i+3*size])
+				} else {
+					b.WriteRune(r)
+				}
+				run = run[size:]
+				i += 3 * size
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// spoofable reports characters that don't show as themselves: format
+// characters (bidi controls U+200E, U+202A–U+202E, U+2066–U+2069,
+// zero-width U+200B–U+200D, U+FEFF, ...), controls and spaces
+func spoofable(r rune) bool {
+	return unicode.In(r, unicode.Cf, unicode.Cc, unicode.Z)
+}
+
+// unhex decodes the two hex digits of a %XX escape
+func unhex(hi, lo byte) (byte, bool) {
+	h, ok1 := hexDigit(hi)
+	l, ok2 := hexDigit(lo)
+	return h<<4 | l, ok1 && ok2
+}
+
+func hexDigit(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"gocrawler/crawler"
+	"gocrawler/events"
+	"gocrawler/storage"
+)
+
+// retryCommand is the first argument selecting the retry subcommand:
+//
+//	gocrawler retry-failed [flags] <results.json>
+//
+// It takes the crawl flags, so failed pages are fetched again with the
+// same headers, credentials and extraction settings as a crawl.
+const retryCommand = "retry-failed"
+
+// retryFailed fetches the failed pages of a stored run again, without
+// following their links, and replaces them in its results.json. It
+// returns exitErrors while pages keep failing.
+func retryFailed(cfg crawler.Config) int {
+	if flag.NArg() != 1 {
+		log.Fatal("usage: gocrawler retry-failed [flags] <results.json>")
+	}
+	file := flag.Arg(0)
+	failed, startURL, err := storage.FailedPages(file)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", file, err)
+	}
+	if len(failed) == 0 {
+		fmt.Printf("✅ No failed pages in %s\n", file)
+		return exitOK
+	}
+
+	jobs := make([]crawler.Job, len(failed))
+	for i, page := range failed {
+		jobs[i] = crawler.Job{URL: page.URL, Depth: page.Depth, Parent: page.Parent, AlternateOf: page.AlternateOf}
+	}
+	fmt.Printf("🔁 Retrying %d failed pages of %s\n", len(failed), file)
+
+	results := storage.NewResults()
+	bus := events.NewBus()
+	events.Record(bus, results)
+	crawler.New(cfg).Refetch(context.Background(), startURL, jobs, bus)
+
+	fresh := results.GetPages()
+	stillFailing := 0
+	for _, page := range fresh {
+		if !page.Success {
+			stillFailing++
+		}
+	}
+	if err := storage.MergePages(file, fresh); err != nil {
+		log.Fatalf("Error updating %s: %v", file, err)
+	}
+	fmt.Printf("\n✅ %d of %d pages recovered, %s updated\n", len(fresh)-stillFailing, len(failed), file)
+	if stillFailing > 0 {
+		fmt.Printf("❌ %d pages still fail\n", stillFailing)
+		return exitErrors
+	}
+	return exitOK
+}
@@ -0,0 +1,270 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gocrawler/storage"
+)
+
+// maxChunk bounds the content of one Algolia record, well below the
+// 10 KB record limit of the smaller plans once the other fields are added
+const maxChunk = 6000
+
+// AlgoliaConfig selects the Algolia application and index
+type AlgoliaConfig struct {
+	AppID  string
+	APIKey string // admin key, or a key with addObject, deleteObject and browse ACLs
+	Index  string
+	Host   string // defaults to https://<AppID>.algolia.net
+}
+
+// AlgoliaRecord is one chunk of a page. Every record carries the site
+// and run it came from, so records of earlier runs can be deleted.
+type AlgoliaRecord struct {
+	ObjectID    string   `json:"objectID"`
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Headings    []string `json:"headings,omitempty"`
+	Content     string   `json:"content"`
+	Chunk       int      `json:"chunk"`
+	Site        string   `json:"site"`
+	CrawlRun    string   `json:"crawl_run"`
+}
+
+// AlgoliaRecords splits the content of every successful page into
+// records of at most maxChunk bytes, cut at line boundaries
+func AlgoliaRecords(pages []*storage.Page, site, run string) []AlgoliaRecord {
+	var records []AlgoliaRecord
+	for _, page := range pages {
+		if !page.Success {
+			continue
+		}
+		for i, chunk := range chunks(content(page), maxChunk) {
+			records = append(records, AlgoliaRecord{
+				ObjectID:    fmt.Sprintf("%s-%d", documentID(page.URL), i),
+				URL:         page.URL,
+				Title:       page.Title,
+				Description: page.Description,
+				Headings:    page.Headings,
+				Content:     chunk,
+				Chunk:       i,
+				Site:        site,
+				CrawlRun:    run,
+			})
+		}
+	}
+	return records
+}
+
+// chunks cuts text at line boundaries into pieces of at most size
+// bytes, lines longer than size are cut at a space. Empty text still
+// gives one chunk so the page is searchable by title.
+func chunks(text string, size int) []string {
+	var pieces []string
+	var current strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		for len(line) > size {
+			cut := strings.LastIndexByte(line[:size], ' ')
+			if cut <= 0 {
+				cut = size
+			}
+			if current.Len() > 0 {
+				pieces = append(pieces, current.String())
+				current.Reset()
+			}
+			pieces = append(pieces, line[:cut])
+			line = strings.TrimLeft(line[cut:], " ")
+		}
+		if current.Len() > 0 && current.Len()+1+len(line) > size {
+			pieces = append(pieces, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 || len(pieces) == 0 {
+		pieces = append(pieces, current.String())
+	}
+	return pieces
+}
+
+// taskPoll is how often PushAlgolia checks whether its batches were
+// applied to the index
+const taskPoll = time.Second
+
+// PushAlgolia saves the records in batches, then deletes the records
+// of the same site left by earlier runs, returning how many it deleted.
+// Algolia applies batches asynchronously, so the index is only browsed
+// for stale records once every batch is published: until then records
+// pushed again by this run could still carry the previous crawl_run.
+func PushAlgolia(ctx context.Context, cfg AlgoliaConfig, records []AlgoliaRecord, site, run string) (int, error) {
+	c := &algolia{cfg: cfg, client: &http.Client{Timeout: time.Minute}}
+	if c.cfg.Host == "" {
+		c.cfg.Host = "https://" + cfg.AppID + ".algolia.net"
+	}
+
+	requests := make([]algoliaRequest, len(records))
+	for i, record := range records {
+		requests[i] = algoliaRequest{Action: "updateObject", Body: record}
+	}
+	tasks, err := c.batch(ctx, requests)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.wait(ctx, tasks); err != nil {
+		return 0, err
+	}
+
+	stale, err := c.staleObjects(ctx, site, run)
+	if err != nil {
+		return 0, fmt.Errorf("listing stale records: %w", err)
+	}
+	requests = make([]algoliaRequest, len(stale))
+	for i, id := range stale {
+		requests[i] = algoliaRequest{Action: "deleteObject", Body: map[string]string{"objectID": id}}
+	}
+	if _, err := c.batch(ctx, requests); err != nil {
+		return 0, err
+	}
+	return len(stale), nil
+}
+
+type algolia struct {
+	cfg    AlgoliaConfig
+	client *http.Client
+}
+
+type algoliaRequest struct {
+	Action string `json:"action"`
+	Body   any    `json:"body"`
+}
+
+// batch sends write requests batchSize at a time, returning the task
+// ids Algolia applies them under
+func (c *algolia) batch(ctx context.Context, requests []algoliaRequest) ([]int64, error) {
+	var tasks []int64
+	for start := 0; start < len(requests); start += batchSize {
+		body := map[string]any{"requests": requests[start:min(start+batchSize, len(requests))]}
+		var resp struct {
+			TaskID int64 `json:"taskID"`
+		}
+		if err := c.do(ctx, http.MethodPost, "/batch", body, &resp); err != nil {
+			return nil, fmt.Errorf("batch at record %d: %w", start, err)
+		}
+		tasks = append(tasks, resp.TaskID)
+	}
+	return tasks, nil
+}
+
+// wait polls the tasks until Algolia reports them published
+func (c *algolia) wait(ctx context.Context, tasks []int64) error {
+	for _, task := range tasks {
+		for {
+			var status struct {
+				Status string `json:"status"`
+			}
+			if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/task/%d", task), nil, &status); err != nil {
+				return fmt.Errorf("waiting for task %d: %w", task, err)
+			}
+			if status.Status == "published" {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(taskPoll):
+			}
+		}
+	}
+	return nil
+}
+
+// staleObjects browses the index for records of site from other runs
+func (c *algolia) staleObjects(ctx context.Context, site, run string) ([]string, error) {
+	var stale []string
+	params := url.Values{"attributesToRetrieve": {`["site","crawl_run"]`}, "hitsPerPage": {"1000"}}.Encode()
+	cursor := ""
+	for {
+		body := map[string]string{"params": params}
+		if cursor != "" {
+			body = map[string]string{"cursor": cursor}
+		}
+		var page struct {
+			Hits []struct {
+				ObjectID string `json:"objectID"`
+				Site     string `json:"site"`
+				CrawlRun string `json:"crawl_run"`
+			} `json:"hits"`
+			Cursor string `json:"cursor"`
+		}
+		if err := c.do(ctx, http.MethodPost, "/browse", body, &page); err != nil {
+			return nil, err
+		}
+		for _, hit := range page.Hits {
+			if hit.Site == site && hit.CrawlRun != run {
+				stale = append(stale, hit.ObjectID)
+			}
+		}
+		if page.Cursor == "" {
+			return stale, nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+// do sends a request to an index endpoint, with body as JSON unless it
+// is nil, and decodes the answer into out
+func (c *algolia) do(ctx context.Context, method, path string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	endpoint := strings.TrimSuffix(c.cfg.Host, "/") + "/1/indexes/" + url.PathEscape(c.cfg.Index) + path
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Algolia-Application-Id", c.cfg.AppID)
+	req.Header.Set("X-Algolia-API-Key", c.cfg.APIKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return &statusError{
+			request: method + " " + path,
+			code:    resp.StatusCode,
+			status:  resp.Status,
+			body:    strings.TrimSpace(string(data[:min(len(data), 512)])),
+		}
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
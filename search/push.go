@@ -0,0 +1,184 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// batchSize is the number of documents sent per request
+const batchSize = 1000
+
+// Config selects the engine and index documents are pushed to
+type Config struct {
+	Engine string // Meilisearch or Typesense
+	URL    string // e.g. http://localhost:7700 or http://localhost:8108
+	Index  string // index (MeiliSearch) or collection (Typesense)
+	APIKey string
+}
+
+// ParseEngine validates an engine name
+func ParseEngine(engine string) (string, error) {
+	switch engine {
+	case Meilisearch, Typesense:
+		return engine, nil
+	default:
+		return "", fmt.Errorf("invalid search engine %q (want meilisearch or typesense)", engine)
+	}
+}
+
+// Push sends documents to the engine in batches, replacing documents
+// with the same id
+func Push(ctx context.Context, cfg Config, docs []Document) error {
+	c := &pusher{cfg: cfg, base: strings.TrimSuffix(cfg.URL, "/"), client: &http.Client{Timeout: time.Minute}}
+	if cfg.Engine == Typesense {
+		if err := c.ensureCollection(ctx); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(docs); start += batchSize {
+		batch := docs[start:min(start+batchSize, len(docs))]
+		var err error
+		switch cfg.Engine {
+		case Meilisearch:
+			err = c.meilisearch(ctx, batch)
+		case Typesense:
+			err = c.typesense(ctx, batch)
+		}
+		if err != nil {
+			return fmt.Errorf("batch at document %d: %w", start, err)
+		}
+	}
+	return nil
+}
+
+type pusher struct {
+	cfg    Config
+	base   string
+	client *http.Client
+}
+
+// meilisearch adds documents, indexing runs asynchronously on the server
+func (c *pusher) meilisearch(ctx context.Context, docs []Document) error {
+	body, err := json.Marshal(docs)
+	if err != nil {
+		return err
+	}
+	path := "/indexes/" + url.PathEscape(c.cfg.Index) + "/documents?primaryKey=id"
+	_, err = c.do(ctx, http.MethodPost, path, "application/json", body)
+	return err
+}
+
+// typesense upserts documents with the JSON Lines import endpoint,
+// which reports failures per document
+func (c *pusher) typesense(ctx context.Context, docs []Document) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+	}
+	path := "/collections/" + url.PathEscape(c.cfg.Index) + "/documents/import?action=upsert"
+	resp, err := c.do(ctx, http.MethodPost, path, "text/plain", body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	failed, firstErr := 0, ""
+	scanner := bufio.NewScanner(bytes.NewReader(resp))
+	for scanner.Scan() {
+		var result struct {
+			Success bool   `json:"success"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &result) == nil && !result.Success {
+			if failed == 0 {
+				firstErr = result.Error
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d documents rejected, first: %s", failed, len(docs), firstErr)
+	}
+	return nil
+}
+
+// ensureCollection creates the Typesense collection with an automatic
+// schema unless it exists
+func (c *pusher) ensureCollection(ctx context.Context) error {
+	path := "/collections/" + url.PathEscape(c.cfg.Index)
+	_, err := c.do(ctx, http.MethodGet, path, "", nil)
+	var status *statusError
+	if !errors.As(err, &status) || status.code != http.StatusNotFound {
+		return err
+	}
+
+	schema, _ := json.Marshal(map[string]any{
+		"name":   c.cfg.Index,
+		"fields": []map[string]string{{"name": ".*", "type": "auto"}},
+	})
+	_, err = c.do(ctx, http.MethodPost, "/collections", "application/json", schema)
+	return err
+}
+
+// statusError is an API answer outside 2xx
+type statusError struct {
+	request string
+	code    int
+	status  string
+	body    string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.request, e.status, e.body)
+}
+
+// do sends a request with the engine's authentication header
+func (c *pusher) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.cfg.APIKey != "" {
+		switch c.cfg.Engine {
+		case Meilisearch:
+			req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+		case Typesense:
+			req.Header.Set("X-TYPESENSE-API-KEY", c.cfg.APIKey)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, &statusError{
+			request: method + " " + path,
+			code:    resp.StatusCode,
+			status:  resp.Status,
+			body:    strings.TrimSpace(string(data[:min(len(data), 512)])),
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,159 @@
+// Package search turns crawled pages into documents for site-search
+// engines, written as JSON Lines and optionally pushed to MeiliSearch
+// or Typesense
+package search
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gocrawler/storage"
+)
+
+// Engines
+const (
+	Meilisearch = "meilisearch"
+	Typesense   = "typesense"
+)
+
+// DefaultFields maps document fields to page fields
+const DefaultFields = "id=id,url=url,title=title,description=description,content=content"
+
+// sources are the page fields available to a field mapping
+var sources = map[string]func(*storage.Page) any{
+	"id":          func(p *storage.Page) any { return documentID(p.URL) },
+	"url":         func(p *storage.Page) any { return p.URL },
+	"title":       func(p *storage.Page) any { return p.Title },
+	"description": func(p *storage.Page) any { return p.Description },
+	"content":     func(p *storage.Page) any { return content(p) },
+	"text":        func(p *storage.Page) any { return p.Text },
+	"author":      func(p *storage.Page) any { return articleField(p, func(a *storage.Article) string { return a.Author }) },
+	"published": func(p *storage.Page) any {
+		return articleField(p, func(a *storage.Article) string { return a.Published })
+	},
+	"keywords":    keywords,
+	"headings":    func(p *storage.Page) any { return p.Headings },
+	"depth":       func(p *storage.Page) any { return p.Depth },
+	"status_code": func(p *storage.Page) any { return p.StatusCode },
+	"crawled_at":  func(p *storage.Page) any { return p.CrawledAt.Unix() },
+}
+
+// Field maps a document field to a page field
+type Field struct {
+	Name   string // field in the search document
+	Source string // key of sources
+}
+
+// ParseFields parses a mapping such as "id=id,body=content". The
+// document needs an id, so one is added if the mapping has none.
+func ParseFields(spec string) ([]Field, error) {
+	var fields []Field
+	hasID := false
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, source, ok := strings.Cut(pair, "=")
+		if !ok {
+			source = name
+		}
+		name, source = strings.TrimSpace(name), strings.TrimSpace(source)
+		if _, known := sources[source]; !known {
+			return nil, fmt.Errorf("unknown page field %q in %q (want one of id, url, title, description, content, text, headings, author, published, keywords, depth, status_code, crawled_at)", source, pair)
+		}
+		hasID = hasID || name == "id"
+		fields = append(fields, Field{Name: name, Source: source})
+	}
+	if !hasID {
+		fields = append([]Field{{Name: "id", Source: "id"}}, fields...)
+	}
+	return fields, nil
+}
+
+// Uses reports whether a mapping reads the given page field
+func Uses(fields []Field, source string) bool {
+	for _, f := range fields {
+		if f.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Document is one page in the search engine
+type Document map[string]any
+
+// Documents maps the successfully crawled pages to documents
+func Documents(pages []*storage.Page, fields []Field) []Document {
+	docs := make([]Document, 0, len(pages))
+	for _, page := range pages {
+		if !page.Success {
+			continue
+		}
+		doc := make(Document, len(fields))
+		for _, f := range fields {
+			doc[f.Name] = sources[f.Source](page)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// WriteJSONL writes documents one per line, the bulk format both
+// engines import
+func WriteJSONL(filename string, docs []Document) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	encoder := json.NewEncoder(w)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// documentID derives a stable id from the URL, using only characters
+// both engines accept
+func documentID(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// content is the article text when one was extracted, the whole
+// visible text otherwise
+func content(p *storage.Page) string {
+	if p.Article != nil && p.Article.Words >= storage.MinArticleWords {
+		return p.Article.Text
+	}
+	return p.Text
+}
+
+func articleField(p *storage.Page, field func(*storage.Article) string) string {
+	if p.Article == nil {
+		return ""
+	}
+	return field(p.Article)
+}
+
+func keywords(p *storage.Page) any {
+	terms := make([]string, len(p.Keywords))
+	for i, t := range p.Keywords {
+		terms[i] = t.Term
+	}
+	return terms
+}
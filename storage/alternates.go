@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Parity compares a canonical page with one of its alternate versions
+type Parity struct {
+	URL       string   `json:"url"`
+	Alternate string   `json:"alternate"`
+	Kind      string   `json:"kind"` // "amp" or "mobile"
+	Crawled   bool     `json:"crawled"`
+	Issues    []string `json:"issues,omitempty"`
+}
+
+// AlternateParity lists every AMP/mobile relationship found and, when the
+// alternate was crawled too, the differences between the two versions
+func (r *Results) AlternateParity() []Parity {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byURL := make(map[string]*Page, len(r.pages))
+	for _, page := range r.pages {
+		byURL[page.URL] = page
+	}
+
+	parity := make([]Parity, 0)
+	for _, page := range r.orderedPages() {
+		if page.AlternateOf != "" {
+			continue
+		}
+		alternates := []struct{ kind, url string }{{"amp", page.AMPURL}, {"mobile", page.MobileURL}}
+		for _, alt := range alternates {
+			if alt.url == "" {
+				continue
+			}
+			p := Parity{URL: page.URL, Alternate: alt.url, Kind: alt.kind}
+			if altPage, ok := byURL[alt.url]; ok {
+				p.Crawled = true
+				p.Issues = r.compareVersions(page, altPage)
+			}
+			parity = append(parity, p)
+		}
+	}
+
+	return parity
+}
+
+// compareVersions reports user-visible differences between two versions,
+// callers hold r.mu
+func (r *Results) compareVersions(canonical, alt *Page) []string {
+	var issues []string
+	if !alt.Success {
+		return append(issues, "alternate failed: "+alt.Error)
+	}
+	if canonical.Title != alt.Title {
+		issues = append(issues, "title differs")
+	}
+	if canonical.Description != alt.Description {
+		issues = append(issues, "description differs")
+	}
+	// Alternates usually trim navigation, only flag large gaps in links
+	altLinks, canonicalLinks := len(r.links.targets(alt.URL)), len(r.links.targets(canonical.URL))
+	if altLinks*2 < canonicalLinks {
+		issues = append(issues, fmt.Sprintf("links %d vs %d", altLinks, canonicalLinks))
+	}
+	return issues
+}
+
+// ExportAlternatesCSV exports AMP/mobile relationships and parity issues
+func (r *Results) ExportAlternatesCSV(filename string) error {
+	parity := r.AlternateParity()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		return writeAlternatesCSV(w, cfg, parity)
+	})
+}
+
+// writeAlternatesCSV writes one row per alternate relationship
+func writeAlternatesCSV(w io.Writer, cfg ExportConfig, parity []Parity) error {
+	writer := newCSVWriter(w, cfg)
+	if err := writeSchemaRow(writer, cfg, "alternates"); err != nil {
+		return err
+	}
+
+	header := []string{"URL", "Alternate", "Kind", "Crawled", "Issues"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, p := range parity {
+		row := []string{
+			p.URL,
+			p.Alternate,
+			p.Kind,
+			fmt.Sprintf("%t", p.Crawled),
+			strings.Join(p.Issues, "; "),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
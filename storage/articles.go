@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Article is the main content of a page, extracted with -articles
+type Article struct {
+	Title     string `json:"title"`
+	Author    string `json:"author,omitempty"`
+	Published string `json:"published,omitempty"`
+	Words     int    `json:"words"`
+	Text      string `json:"text"`
+}
+
+// MinArticleWords is the article length below which keywords are
+// extracted from, and search documents built from, the whole visible
+// text instead, short extractions are often wrong
+const MinArticleWords = 50
+
+// articleRecord is one line of the articles export
+type articleRecord struct {
+	URL string `json:"url"`
+	*Article
+}
+
+// Article returns the extracted article of url, or nil (thread-safe)
+func (r *Results) Article(url string) *Article {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, page := range r.pages {
+		if page.URL == url {
+			return page.Article
+		}
+	}
+	return nil
+}
+
+// ExportArticlesJSON exports the extracted articles as JSON Lines, one
+// object per page with content, ready for corpus and NLP tooling
+func (r *Results) ExportArticlesJSON(filename string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, page := range r.orderedPages() {
+			if page.Article == nil || page.Article.Words == 0 {
+				continue
+			}
+			if err := encoder.Encode(articleRecord{URL: page.URL, Article: page.Article}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+)
+
+// Asset kinds
+const (
+	AssetFavicon  = "favicon"
+	AssetManifest = "manifest"
+	AssetOGImage  = "og_image"
+)
+
+// Asset is the outcome of checking a resource referenced by pages
+type Asset struct {
+	URL        string `json:"url"`
+	Kind       string `json:"kind"`
+	StatusCode int    `json:"status_code,omitempty"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	Width      int    `json:"width,omitempty"` // images only
+	Height     int    `json:"height,omitempty"`
+}
+
+// SiteAsset is a favicon or manifest declared on a host, or a missing
+// one when URL is empty
+type SiteAsset struct {
+	Host       string `json:"host"`
+	Kind       string `json:"kind"`
+	URL        string `json:"url,omitempty"`
+	Pages      int    `json:"pages"` // pages declaring it
+	Checked    bool   `json:"checked"`
+	StatusCode int    `json:"status_code,omitempty"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+}
+
+// AddAsset stores the result of an asset check (thread-safe)
+func (r *Results) AddAsset(asset *Asset) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.assets[asset.URL] = asset
+}
+
+// SiteAssets lists the favicons and manifests of every crawled host,
+// with a row without URL for each kind a host doesn't declare
+func (r *Results) SiteAssets() []SiteAsset {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type key struct{ host, kind, url string }
+	pages := make(map[key]int)
+	declared := make(map[string]map[string]bool)
+	for _, page := range r.pages {
+		u, err := url.Parse(page.URL)
+		if err != nil || !page.Success {
+			continue
+		}
+		if declared[u.Host] == nil {
+			declared[u.Host] = make(map[string]bool)
+		}
+		for _, icon := range page.Icons {
+			pages[key{u.Host, AssetFavicon, icon}]++
+			declared[u.Host][AssetFavicon] = true
+		}
+		if page.Manifest != "" {
+			pages[key{u.Host, AssetManifest, page.Manifest}]++
+			declared[u.Host][AssetManifest] = true
+		}
+	}
+	for host, kinds := range declared {
+		for _, kind := range []string{AssetFavicon, AssetManifest} {
+			if !kinds[kind] {
+				pages[key{host, kind, ""}] = 0
+			}
+		}
+	}
+
+	assets := make([]SiteAsset, 0, len(pages))
+	for k, n := range pages {
+		sa := SiteAsset{Host: k.host, Kind: k.kind, URL: k.url, Pages: n}
+		if checked, ok := r.assets[k.url]; ok {
+			sa.Checked = true
+			sa.StatusCode, sa.OK, sa.Error = checked.StatusCode, checked.OK, checked.Error
+		}
+		assets = append(assets, sa)
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		a, b := assets[i], assets[j]
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.URL < b.URL
+	})
+	return assets
+}
+
+// ExportAssetsCSV exports the favicon/manifest audit
+func (r *Results) ExportAssetsCSV(filename string) error {
+	assets := r.SiteAssets()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		return writeAssetsCSV(w, cfg, assets)
+	})
+}
+
+// writeAssetsCSV writes one row per declared or missing asset
+func writeAssetsCSV(w io.Writer, cfg ExportConfig, assets []SiteAsset) error {
+	writer := newCSVWriter(w, cfg)
+	if err := writeSchemaRow(writer, cfg, "assets"); err != nil {
+		return err
+	}
+
+	header := []string{"Host", "Kind", "URL", "Pages", "Status Code", "OK", "Error"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, a := range assets {
+		status, ok, errMsg := "", "", a.Error
+		if a.URL == "" {
+			errMsg = "missing"
+		} else if a.Checked {
+			status, ok = fmt.Sprintf("%d", a.StatusCode), fmt.Sprintf("%t", a.OK)
+		}
+		row := []string{a.Host, a.Kind, a.URL, fmt.Sprintf("%d", a.Pages), status, ok, errMsg}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+)
+
+// BrokenLink is an internal link to a page that failed to load, or a
+// link to a failed external target (-check-external)
+type BrokenLink struct {
+	Source     string    `json:"source"`
+	Target     string    `json:"target"`
+	Text       string    `json:"text,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	ErrorType  ErrorType `json:"error_type"`
+	External   bool      `json:"external,omitempty"`
+	ArchiveURL string    `json:"archive_url,omitempty"` // Wayback Machine copy of an external target
+}
+
+// BrokenLinks lists the links pointing at failed pages, in crawl order
+// of the failed pages, then the links to failed external targets
+func (r *Results) BrokenLinks() []BrokenLink {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	broken := make([]BrokenLink, 0)
+	for _, page := range r.orderedPages() {
+		if page.Success {
+			continue
+		}
+		for _, e := range r.links.incoming(page.URL) {
+			broken = append(broken, BrokenLink{
+				Source:     e.Source,
+				Target:     e.Target,
+				Text:       e.Text,
+				StatusCode: page.StatusCode,
+				ErrorType:  page.ErrorType,
+			})
+		}
+	}
+	for _, link := range r.brokenExternal() {
+		for _, e := range r.links.incoming(link.URL) {
+			broken = append(broken, BrokenLink{
+				Source:     e.Source,
+				Target:     e.Target,
+				Text:       e.Text,
+				StatusCode: link.StatusCode,
+				ErrorType:  link.ErrorType,
+				External:   true,
+				ArchiveURL: link.ArchiveURL,
+			})
+		}
+	}
+	return broken
+}
+
+// MissingTitles lists successfully crawled pages without a title
+func (r *Results) MissingTitles() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	missing := make([]string, 0)
+	for _, page := range r.orderedPages() {
+		if page.Success && page.Title == "" {
+			missing = append(missing, page.URL)
+		}
+	}
+	return missing
+}
+
+// ExportBrokenLinksCSV exports internal links to failed pages and links
+// to failed external targets
+func (r *Results) ExportBrokenLinksCSV(filename string) error {
+	broken := r.BrokenLinks()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "broken_links"); err != nil {
+			return err
+		}
+
+		header := []string{"Source URL", "Broken URL", "Anchor Text", "Status Code", "Error Type", "External", "Archive URL"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, b := range broken {
+			row := []string{b.Source, b.Target, b.Text, fmt.Sprintf("%d", b.StatusCode), string(b.ErrorType), fmt.Sprintf("%t", b.External), b.ArchiveURL}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
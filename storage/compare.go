@@ -0,0 +1,73 @@
+package storage
+
+// Baseline is an earlier crawl of the same site, e.g. of the base branch
+// of a pull request, that a crawl is compared with
+type Baseline struct {
+	pages  map[string]*Page
+	broken map[[2]string]bool // (source, target) links to failed pages
+}
+
+// LoadBaseline reads the results.json of the crawl to compare with
+func LoadBaseline(filename string) (*Baseline, error) {
+	pages, err := readPages(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Baseline{
+		pages:  make(map[string]*Page, len(pages)),
+		broken: make(map[[2]string]bool),
+	}
+	for _, page := range pages {
+		b.pages[page.URL] = page
+	}
+	for _, page := range pages {
+		for _, target := range page.Links {
+			if t, ok := b.pages[target]; ok && !t.Success {
+				b.broken[[2]string{page.URL, target}] = true
+			}
+		}
+	}
+	return b, nil
+}
+
+// Comparison is how a crawl differs from its baseline
+type Comparison struct {
+	BaselinePages int          `json:"baseline_pages"`
+	NewBroken     []BrokenLink `json:"new_broken"`   // broken links that were fine or absent in the baseline
+	FixedBroken   int          `json:"fixed_broken"` // baseline broken links that are gone
+	Regressions   []*Page      `json:"regressions"`  // pages that loaded in the baseline but fail now
+}
+
+// Compare diffs the crawl against a baseline
+func (r *Results) Compare(base *Baseline) Comparison {
+	broken := r.BrokenLinks()
+
+	c := Comparison{
+		BaselinePages: len(base.pages),
+		NewBroken:     make([]BrokenLink, 0),
+		Regressions:   make([]*Page, 0),
+	}
+	current := make(map[[2]string]bool, len(broken))
+	for _, b := range broken {
+		key := [2]string{b.Source, b.Target}
+		current[key] = true
+		if !base.broken[key] {
+			c.NewBroken = append(c.NewBroken, b)
+		}
+	}
+	for key := range base.broken {
+		if !current[key] {
+			c.FixedBroken++
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, page := range r.orderedPages() {
+		if prev, ok := base.pages[page.URL]; ok && prev.Success && !page.Success {
+			c.Regressions = append(c.Regressions, page)
+		}
+	}
+	return c
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// utf8BOM tells Excel a CSV file is UTF-8 rather than the ANSI code page,
+// which would garble non-Latin titles and URLs
+const utf8BOM = "\ufeff"
+
+// csvDelimiters are the -csv-delimiter names. European Excel locales use
+// the comma as decimal separator and only split semicolon separated files.
+var csvDelimiters = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+}
+
+// ParseDelimiter validates a CSV delimiter, given by name or as the
+// character itself
+func ParseDelimiter(name string) (rune, error) {
+	if d, ok := csvDelimiters[name]; ok {
+		return d, nil
+	}
+	for _, d := range csvDelimiters {
+		if name == string(d) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid CSV delimiter %q (want comma, semicolon or tab)", name)
+}
+
+// maxHeaderPeek bounds how much of a CSV file is looked at to find the
+// delimiter of its first line
+const maxHeaderPeek = 64 << 10
+
+// newCSVReader reads a CSV file, skipping the byte order mark -csv-bom
+// (or Excel) puts in front of its first field. The delimiter is the one
+// of csvDelimiters found most often on the first line, so files written
+// with any -csv-delimiter read back.
+func newCSVReader(r io.Reader) *csv.Reader {
+	buf := bufio.NewReaderSize(r, maxHeaderPeek)
+	if bom, err := buf.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		buf.Discard(len(utf8BOM))
+	}
+	reader := csv.NewReader(buf)
+	reader.Comma = sniffDelimiter(buf)
+	return reader
+}
+
+// sniffDelimiter picks the delimiter of the first line buffered in buf,
+// ',' when the line has none
+func sniffDelimiter(buf *bufio.Reader) rune {
+	head, _ := buf.Peek(maxHeaderPeek)
+	line, _, _ := strings.Cut(string(head), "\n")
+	delimiter, most := ',', 0
+	for _, d := range []rune{',', ';', '\t'} {
+		if n := strings.Count(line, string(d)); n > most {
+			delimiter, most = d, n
+		}
+	}
+	return delimiter
+}
+
+// csvWriter writes the rows of a CSV export with the layout of an
+// ExportConfig. encoding/csv only quotes fields that need it, so QuoteAll
+// rows are encoded here.
+type csvWriter struct {
+	buf   *bufio.Writer
+	csv   *csv.Writer // nil with QuoteAll
+	comma rune
+}
+
+// newCSVWriter returns a CSV writer for an export, starting it with a
+// byte order mark when cfg asks for one
+func newCSVWriter(w io.Writer, cfg ExportConfig) *csvWriter {
+	writer := &csvWriter{buf: bufio.NewWriter(w), comma: cfg.Delimiter}
+	if writer.comma == 0 {
+		writer.comma = ','
+	}
+	if cfg.BOM {
+		writer.buf.WriteString(utf8BOM)
+	}
+	if !cfg.QuoteAll {
+		writer.csv = csv.NewWriter(writer.buf)
+		writer.csv.Comma = writer.comma
+	}
+	return writer
+}
+
+// Write writes one row, errors may only surface with Flush
+func (w *csvWriter) Write(record []string) error {
+	if w.csv != nil {
+		return w.csv.Write(record)
+	}
+	for i, field := range record {
+		if i > 0 {
+			w.buf.WriteRune(w.comma)
+		}
+		w.buf.WriteByte('"')
+		w.buf.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		w.buf.WriteByte('"')
+	}
+	return w.buf.WriteByte('\n')
+}
+
+// Flush writes the buffered rows to the underlying writer
+func (w *csvWriter) Flush() {
+	if w.csv != nil {
+		w.csv.Flush()
+	}
+	w.buf.Flush()
+}
+
+// Error reports any error of a previous Write or Flush
+func (w *csvWriter) Error() error {
+	if w.csv != nil {
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	// bufio.Writer keeps its first error, an empty write returns it
+	_, err := w.buf.Write(nil)
+	return err
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxEdits bounds the work of a line diff, texts differing by more
+// lines are reported as entirely replaced
+const maxEdits = 2000
+
+// DiffLine is one line of a line diff
+type DiffLine struct {
+	Op   byte   `json:"op"` // ' ' unchanged, '-' removed, '+' added
+	Text string `json:"text"`
+}
+
+// diffLines computes a shortest line diff from a to b (Myers)
+func diffLines(a, b []string) []DiffLine {
+	// Common prefix and suffix need no search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var diff []DiffLine
+	for _, line := range a[:prefix] {
+		diff = append(diff, DiffLine{' ', line})
+	}
+	diff = append(diff, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{' ', line})
+	}
+	return diff
+}
+
+// myers finds the edit script of a and b, keeping the furthest x per
+// diagonal of every step to walk the path back
+func myers(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // step down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // step right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	// Too different, replace everything
+	diff := make([]DiffLine, 0, n+m)
+	for _, line := range a {
+		diff = append(diff, DiffLine{'-', line})
+	}
+	for _, line := range b {
+		diff = append(diff, DiffLine{'+', line})
+	}
+	return diff
+}
+
+// backtrack walks the path found in d steps back from the end
+func backtrack(a, b []string, trace [][]int, d int) []DiffLine {
+	var rev []DiffLine
+	x, y := len(a), len(b)
+	k := x - y
+	for ; d > 0; d-- {
+		prev := trace[d-1] // diagonal k of step d-1 is at prev[k+d-1]
+		at := func(k int) int { return prev[k+d-1] }
+
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, DiffLine{' ', a[x]})
+		}
+		if prevK == k+1 {
+			y--
+			rev = append(rev, DiffLine{'+', b[y]})
+		} else {
+			x--
+			rev = append(rev, DiffLine{'-', a[x]})
+		}
+		k = prevK
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, DiffLine{' ', a[x]})
+	}
+
+	diff := make([]DiffLine, len(rev))
+	for i, line := range rev {
+		diff[len(rev)-1-i] = line
+	}
+	return diff
+}
+
+// diffStats counts added and removed lines
+func diffStats(diff []DiffLine) (added, removed int) {
+	for _, line := range diff {
+		switch line.Op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// unified renders a diff as unified diff hunks with context lines
+// around every change
+func unified(diff []DiffLine, context int) string {
+	var sb strings.Builder
+	for start := 0; start < len(diff); {
+		// Find the next change and extend the hunk while changes are
+		// closer than twice the context
+		first := start
+		for first < len(diff) && diff[first].Op == ' ' {
+			first++
+		}
+		if first == len(diff) {
+			break
+		}
+		end := first
+		for i := first; i < len(diff) && i <= end+2*context; i++ {
+			if diff[i].Op != ' ' {
+				end = i
+			}
+		}
+		from := max(first-context, start)
+		to := min(end+context+1, len(diff))
+
+		aLine, bLine := 1, 1
+		for _, line := range diff[:from] {
+			if line.Op != '+' {
+				aLine++
+			}
+			if line.Op != '-' {
+				bLine++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, line := range diff[from:to] {
+			if line.Op != '+' {
+				aCount++
+			}
+			if line.Op != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		for _, line := range diff[from:to] {
+			sb.WriteByte(line.Op)
+			sb.WriteString(line.Text)
+			sb.WriteByte('\n')
+		}
+		start = to
+	}
+	return sb.String()
+}
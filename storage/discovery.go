@@ -0,0 +1,52 @@
+package storage
+
+// DiscoveryPath returns the chain of pages that led the crawler to url,
+// starting at the seed and ending with url itself. Parents are matched by
+// their final URL too, since links on redirected pages resolve against it.
+func (r *Results) DiscoveryPath(url string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byURL := r.discoveryIndex()
+	return discoveryPath(byURL, byURL[url])
+}
+
+// DiscoveryPaths returns the DiscoveryPath of every page, indexed by page
+// id, sharing one lookup table
+func (r *Results) DiscoveryPaths() [][]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byURL := r.discoveryIndex()
+	paths := make([][]string, len(r.pages))
+	for id, page := range r.pages {
+		paths[id] = discoveryPath(byURL, page)
+	}
+	return paths
+}
+
+// discoveryIndex maps the URLs and final URLs to their page, the caller
+// must hold r.mu
+func (r *Results) discoveryIndex() map[string]*Page {
+	byURL := make(map[string]*Page, len(r.pages))
+	for _, page := range r.pages {
+		byURL[page.URL] = page
+		if n := len(page.Redirects); n > 0 {
+			if _, ok := byURL[page.Redirects[n-1]]; !ok {
+				byURL[page.Redirects[n-1]] = page
+			}
+		}
+	}
+	return byURL
+}
+
+// discoveryPath follows the parents of page back to the seed
+func discoveryPath(byURL map[string]*Page, page *Page) []string {
+	var path []string
+	seen := make(map[string]bool)
+	for ; page != nil && !seen[page.URL]; page = byURL[page.Parent] {
+		seen[page.URL] = true
+		path = append([]string{page.URL}, path...)
+	}
+	return path
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"io"
+	"sort"
+)
+
+// DuplicateGroup is a set of URLs answering with the same body
+type DuplicateGroup struct {
+	Canonical string   `json:"canonical"` // first URL crawled with the body
+	Hash      string   `json:"hash"`
+	Aliases   []string `json:"aliases"` // other URLs with the same body, sorted
+}
+
+// DuplicateGroups collapses the pages with identical bodies into groups
+// around the first URL crawled with each, sorted by that URL
+func (r *Results) DuplicateGroups() []DuplicateGroup {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byCanonical := make(map[string]*DuplicateGroup)
+	for _, page := range r.pages {
+		if page.DuplicateOf == "" {
+			continue
+		}
+		g, ok := byCanonical[page.DuplicateOf]
+		if !ok {
+			g = &DuplicateGroup{Canonical: page.DuplicateOf, Hash: page.ContentHash}
+			byCanonical[page.DuplicateOf] = g
+		}
+		g.Aliases = append(g.Aliases, page.URL)
+	}
+
+	groups := make([]DuplicateGroup, 0, len(byCanonical))
+	for _, g := range byCanonical {
+		sort.Strings(g.Aliases)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Canonical < groups[j].Canonical
+	})
+	return groups
+}
+
+// ExportDuplicatesCSV exports the alias sets, one row per duplicate URL
+func (r *Results) ExportDuplicatesCSV(filename string) error {
+	groups := r.DuplicateGroups()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "duplicates"); err != nil {
+			return err
+		}
+
+		header := []string{"Canonical URL", "Duplicate URL", "Content Hash"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, g := range groups {
+			for _, alias := range g.Aliases {
+				if err := writer.Write([]string{g.Canonical, alias, g.Hash}); err != nil {
+					return err
+				}
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
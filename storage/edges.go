@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Edge is one deduplicated link: a source page linking a target URL with
+// a given anchor text and rel
+type Edge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Text     string `json:"text"`
+	Rel      string `json:"rel,omitempty"`
+	Position string `json:"position,omitempty"` // of the first occurrence
+	NoFollow bool   `json:"nofollow"`           // rel=nofollow or a nofollow page
+	Count    int    `json:"count"`              // occurrences on the source page
+}
+
+// edge is an Edge with interned URLs
+type edge struct {
+	source, target int32
+	text, rel      string
+	position       string
+	nofollow       bool
+	count          int32
+}
+
+type edgeKey struct {
+	source, target int32
+	text, rel      string
+}
+
+// linkGraph is the edge table of every link found. URLs are interned so
+// each is stored once however many pages link it, and edges are indexed
+// both ways for inlink/outlink queries. Callers hold Results.mu.
+type linkGraph struct {
+	ids   map[string]int32
+	urls  []string
+	edges []edge
+	index map[edgeKey]int32
+	out   map[int32][]int32 // source -> edges, in page order
+	in    map[int32][]int32 // target -> edges
+}
+
+func newLinkGraph() *linkGraph {
+	return &linkGraph{
+		ids:   make(map[string]int32),
+		index: make(map[edgeKey]int32),
+		out:   make(map[int32][]int32),
+		in:    make(map[int32][]int32),
+	}
+}
+
+// intern returns the id of url, adding it if needed
+func (g *linkGraph) intern(url string) int32 {
+	if id, ok := g.ids[url]; ok {
+		return id
+	}
+	id := int32(len(g.urls))
+	g.urls = append(g.urls, url)
+	g.ids[url] = id
+	return id
+}
+
+// addPage moves a page's anchors into the table. Anchors that didn't
+// resolve to a URL are dropped.
+func (g *linkGraph) addPage(page *Page) {
+	source := g.intern(page.URL)
+	for _, a := range page.Anchors {
+		if a.URL == "" {
+			continue
+		}
+		k := edgeKey{source, g.intern(a.URL), a.Text, a.Rel}
+		if i, ok := g.index[k]; ok {
+			g.edges[i].count++
+			continue
+		}
+		i := int32(len(g.edges))
+		g.edges = append(g.edges, edge{
+			source:   k.source,
+			target:   k.target,
+			text:     a.Text,
+			rel:      a.Rel,
+			position: a.Position,
+			nofollow: page.NoFollow || hasRel(a.Rel, "nofollow"),
+			count:    1,
+		})
+		g.index[k] = i
+		g.out[k.source] = append(g.out[k.source], i)
+		g.in[k.target] = append(g.in[k.target], i)
+	}
+}
+
+// hasRel reports whether a rel attribute contains value
+func hasRel(rel, value string) bool {
+	for _, r := range strings.Fields(rel) {
+		if strings.EqualFold(r, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// edge expands edge i
+func (g *linkGraph) edge(i int32) Edge {
+	e := g.edges[i]
+	return Edge{
+		Source:   g.urls[e.source],
+		Target:   g.urls[e.target],
+		Text:     e.text,
+		Rel:      e.rel,
+		Position: e.position,
+		NoFollow: e.nofollow,
+		Count:    int(e.count),
+	}
+}
+
+// outgoing returns the edges of a source page in page order
+func (g *linkGraph) outgoing(source string) []Edge {
+	id, ok := g.ids[source]
+	if !ok {
+		return nil
+	}
+	edges := make([]Edge, 0, len(g.out[id]))
+	for _, i := range g.out[id] {
+		edges = append(edges, g.edge(i))
+	}
+	return edges
+}
+
+// incoming returns the edges pointing at target
+func (g *linkGraph) incoming(target string) []Edge {
+	id, ok := g.ids[target]
+	if !ok {
+		return nil
+	}
+	edges := make([]Edge, 0, len(g.in[id]))
+	for _, i := range g.in[id] {
+		edges = append(edges, g.edge(i))
+	}
+	return edges
+}
+
+// targets returns the distinct URLs a page links to, in page order
+func (g *linkGraph) targets(source string) []string {
+	id, ok := g.ids[source]
+	if !ok {
+		return nil
+	}
+	seen := make(map[int32]bool)
+	targets := make([]string, 0)
+	for _, i := range g.out[id] {
+		if t := g.edges[i].target; !seen[t] {
+			seen[t] = true
+			targets = append(targets, g.urls[t])
+		}
+	}
+	return targets
+}
+
+// sources returns the distinct pages linking to target, sorted
+func (g *linkGraph) sources(target string) []string {
+	id, ok := g.ids[target]
+	if !ok {
+		return nil
+	}
+	seen := make(map[int32]bool)
+	sources := make([]string, 0)
+	for _, i := range g.in[id] {
+		if s := g.edges[i].source; !seen[s] {
+			seen[s] = true
+			sources = append(sources, g.urls[s])
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// linked reports whether any crawled page links to target
+func (g *linkGraph) linked(target string) bool {
+	id, ok := g.ids[target]
+	return ok && len(g.in[id]) > 0
+}
+
+// withLinks returns a copy of page with Links and Anchors rebuilt from
+// the edge table, as exported in results.json
+func (r *Results) withLinks(page *Page) *Page {
+	p := *page
+	p.Links = r.links.targets(page.URL)
+	p.Anchors = nil
+	for _, e := range r.links.outgoing(page.URL) {
+		p.Anchors = append(p.Anchors, Link{Href: e.Target, URL: e.Target, Text: e.Text, Position: e.Position, Rel: e.Rel})
+	}
+	return &p
+}
+
+// Inlinks returns every edge pointing at url (thread-safe)
+func (r *Results) Inlinks(url string) []Edge {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.links.incoming(url)
+}
+
+// Outlinks returns every edge leaving the page at url (thread-safe)
+func (r *Results) Outlinks(url string) []Edge {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.links.outgoing(url)
+}
+
+// writeLinksCSV writes the edge table, one row per deduplicated link
+func (r *Results) writeLinksCSV(w io.Writer) error {
+	writer := newCSVWriter(w, r.export)
+	if err := writeSchemaRow(writer, r.export, "links"); err != nil {
+		return err
+	}
+
+	header := []string{"Source URL", "Found Link", "Link Depth", "Anchor Text", "Position", "Rel", "Nofollow", "Count"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, page := range r.orderedPages() {
+		if !page.Success {
+			continue
+		}
+		for _, e := range r.links.outgoing(page.URL) {
+			row := []string{
+				e.Source,
+				e.Target,
+				fmt.Sprintf("%d", page.Depth+1),
+				e.Text,
+				e.Position,
+				e.Rel,
+				fmt.Sprintf("%t", e.NoFollow),
+				fmt.Sprintf("%d", e.Count),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"io"
+)
+
+// Embed is an iframe or media resource embedded in a crawled page
+type Embed struct {
+	Kind string `json:"kind"` // iframe, video, audio, embed or object
+	URL  string `json:"url"`
+}
+
+// ExportEmbedsCSV exports the inventory of embedded content
+func (r *Results) ExportEmbedsCSV(filename string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return WriteFile(filename, r.writeEmbedsCSV)
+}
+
+// writeEmbedsCSV writes one row per embedded resource
+func (r *Results) writeEmbedsCSV(w io.Writer) error {
+	writer := newCSVWriter(w, r.export)
+	if err := writeSchemaRow(writer, r.export, "embeds"); err != nil {
+		return err
+	}
+
+	header := []string{"Page URL", "Kind", "Embedded URL"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, page := range r.orderedPages() {
+		for _, embed := range page.Embeds {
+			if err := writer.Write([]string{page.URL, embed.Kind, embed.URL}); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"io"
+	"sort"
+)
+
+// Endpoint is a URL-like string found in JavaScript
+type Endpoint struct {
+	URL    string `json:"url"`
+	Source string `json:"source"` // page with the inline script, or script URL
+}
+
+// AddEndpoint stores a discovered endpoint once per source (thread-safe)
+func (r *Results) AddEndpoint(endpoint *Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints[*endpoint] = true
+}
+
+// Endpoints returns the discovered endpoints sorted by URL then source
+func (r *Results) Endpoints() []Endpoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sortedEndpoints()
+}
+
+// sortedEndpoints returns the endpoints in a stable order, the caller
+// holds the lock
+func (r *Results) sortedEndpoints() []Endpoint {
+	endpoints := make([]Endpoint, 0, len(r.endpoints))
+	for e := range r.endpoints {
+		endpoints = append(endpoints, e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].URL != endpoints[j].URL {
+			return endpoints[i].URL < endpoints[j].URL
+		}
+		return endpoints[i].Source < endpoints[j].Source
+	})
+	return endpoints
+}
+
+// ExportEndpointsCSV exports the URLs found in JavaScript
+func (r *Results) ExportEndpointsCSV(filename string) error {
+	endpoints := r.Endpoints()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "endpoints"); err != nil {
+			return err
+		}
+
+		header := []string{"Endpoint", "Found In"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, e := range endpoints {
+			if err := writer.Write([]string{e.URL, e.Source}); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SchemaVersion is bumped whenever a CSV export changes its columns.
+// New columns are only ever appended, so readers can ignore extras.
+const SchemaVersion = 11
+
+// Export orders
+const (
+	OrderCrawl = "crawl" // order in which pages finished
+	OrderURL   = "url"   // lexicographic by URL, stable across runs
+)
+
+// ExportConfig controls the layout of exported files
+type ExportConfig struct {
+	Order     string // OrderCrawl or OrderURL
+	SchemaRow bool   // prefix CSV files with a "#schema" row
+	BOM       bool   // start CSV files with a UTF-8 byte order mark, for Excel
+	Delimiter rune   // CSV field separator, ',' when zero
+	QuoteAll  bool   // quote every CSV field, not only those that need it
+}
+
+// ParseOrder validates an export order name
+func ParseOrder(order string) (string, error) {
+	switch order {
+	case OrderCrawl, OrderURL:
+		return order, nil
+	default:
+		return "", fmt.Errorf("invalid order %q (want crawl or url)", order)
+	}
+}
+
+// SetExportConfig sets the layout used by all exporters
+func (r *Results) SetExportConfig(cfg ExportConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.export = cfg
+}
+
+// On-demand export formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ParseFormat validates an on-demand export format
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case FormatJSON, FormatCSV, FormatXLSX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid format %q (want json, csv or xlsx)", format)
+	}
+}
+
+// WriteExport writes the pages passing f in export order, as written at
+// exit by ExportJSON and ExportCSV or as an Excel workbook (thread-safe)
+func (r *Results) WriteExport(w io.Writer, format string, f PageFilter) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pages := make([]*Page, 0)
+	for _, page := range r.orderedPages() {
+		if f.Match(page) {
+			pages = append(pages, page)
+		}
+	}
+
+	switch format {
+	case FormatJSON:
+		exported := make([]*Page, len(pages))
+		for i, page := range pages {
+			exported[i] = r.withLinks(page)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(exported)
+	case FormatXLSX:
+		rows := [][]string{pagesHeader}
+		for _, page := range pages {
+			rows = append(rows, r.pageRow(page))
+		}
+		return writeXLSX(w, "pages", rows)
+	default:
+		return r.writeCSV(w, pages)
+	}
+}
+
+// orderedPages returns the pages in export order, callers hold r.mu
+func (r *Results) orderedPages() []*Page {
+	pages := make([]*Page, len(r.pages))
+	copy(pages, r.pages)
+
+	if r.export.Order == OrderURL {
+		sort.SliceStable(pages, func(i, j int) bool { return pages[i].URL < pages[j].URL })
+	}
+	return pages
+}
+
+// writeSchemaRow writes the optional "#schema,<name>,<version>" row
+func writeSchemaRow(writer *csvWriter, cfg ExportConfig, name string) error {
+	if !cfg.SchemaRow {
+		return nil
+	}
+	return writer.Write([]string{"#schema", name, fmt.Sprintf("%d", SchemaVersion)})
+}
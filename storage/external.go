@@ -0,0 +1,33 @@
+package storage
+
+// ExternalLink is the outcome of checking an out-of-scope link target
+type ExternalLink struct {
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code,omitempty"`
+	OK         bool      `json:"ok"`
+	Error      string    `json:"error,omitempty"`
+	ErrorType  ErrorType `json:"error_type,omitempty"`
+	ArchiveURL string    `json:"archive_url,omitempty"` // closest Wayback Machine snapshot of a broken target
+}
+
+// AddExternal stores the result of an external link check (thread-safe)
+func (r *Results) AddExternal(link *ExternalLink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.externals[link.URL]; !ok {
+		r.externalOrder = append(r.externalOrder, link.URL)
+	}
+	r.externals[link.URL] = link
+}
+
+// brokenExternal lists the failed external link targets in check
+// order, callers hold r.mu
+func (r *Results) brokenExternal() []*ExternalLink {
+	var broken []*ExternalLink
+	for _, u := range r.externalOrder {
+		if link := r.externals[u]; !link.OK {
+			broken = append(broken, link)
+		}
+	}
+	return broken
+}
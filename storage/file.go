@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionExt returns the file extension for a -compress setting
+func CompressionExt(compression string) (string, error) {
+	switch compression {
+	case "", "none":
+		return "", nil
+	case "gzip", "gz":
+		return ".gz", nil
+	case "zstd", "zst":
+		return ".zst", nil
+	default:
+		return "", fmt.Errorf("invalid compression %q (want none, gzip or zstd)", compression)
+	}
+}
+
+// compressor wraps w according to the filename extension (.gz, .zst)
+func compressor(filename string, w io.Writer) (io.WriteCloser, error) {
+	switch {
+	case strings.HasSuffix(filename, ".gz"):
+		return gzip.NewWriter(w), nil
+	case strings.HasSuffix(filename, ".zst"):
+		return zstd.NewWriter(w)
+	default:
+		return nopCloser{w}, nil
+	}
+}
+
+// decompressor wraps r according to the filename extension (.gz, .zst)
+func decompressor(filename string, r io.Reader) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(filename, ".gz"):
+		return gzip.NewReader(r)
+	case strings.HasSuffix(filename, ".zst"):
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// nopCloser adds a no-op Close to uncompressed writers
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// WriteFile writes an export through a temp file and renames it into
+// place, so an interrupted export never truncates the previous file.
+// Files ending in .gz or .zst are compressed on the fly. Outputs of
+// other packages (the HTML report) use it too so all files behave alike.
+func WriteFile(filename string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	buf := bufio.NewWriter(tmp)
+	cw, err := compressor(filename, buf)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := write(cw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// temp files are created owner-only, keep the mode of the replaced
+	// export or the usual one of a new file
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// readFile opens an earlier export, decompressing .gz and .zst files
+func readFile(filename string, read func(r io.Reader) error) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dr, err := decompressor(filename, bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	return read(dr)
+}
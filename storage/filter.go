@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Page filters of on-demand exports and the dashboard page list
+const (
+	FilterAll       = "all"
+	FilterSuccess   = "success"
+	FilterFailed    = "failed"
+	FilterNoIndex   = "noindex"
+	FilterDuplicate = "duplicate"
+)
+
+// PageFilter selects pages, the zero value matches every page
+type PageFilter struct {
+	Status string // one of the Filter constants, empty for FilterAll
+	Text   string // case-insensitive substring of the URL or title
+}
+
+// ParsePageFilter validates a filter name and pairs it with a search text
+func ParsePageFilter(status, text string) (PageFilter, error) {
+	switch status {
+	case "", FilterAll, FilterSuccess, FilterFailed, FilterNoIndex, FilterDuplicate:
+		return PageFilter{Status: status, Text: text}, nil
+	default:
+		return PageFilter{}, fmt.Errorf("invalid filter %q (want all, success, failed, noindex or duplicate)", status)
+	}
+}
+
+// Match reports whether page passes the filter
+func (f PageFilter) Match(page *Page) bool {
+	switch f.Status {
+	case FilterSuccess:
+		if !page.Success {
+			return false
+		}
+	case FilterFailed:
+		if page.Success {
+			return false
+		}
+	case FilterNoIndex:
+		if !page.NoIndex {
+			return false
+		}
+	case FilterDuplicate:
+		if page.DuplicateOf == "" {
+			return false
+		}
+	}
+	if f.Text == "" {
+		return true
+	}
+	text := strings.ToLower(f.Text)
+	return strings.Contains(strings.ToLower(page.URL), text) || strings.Contains(strings.ToLower(page.Title), text)
+}
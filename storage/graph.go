@@ -0,0 +1,97 @@
+package storage
+
+// GraphNode is a crawled page of the link graph, its ID is the page's
+// position in crawl order
+type GraphNode struct {
+	ID         int    `json:"id"`
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	Depth      int    `json:"depth"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Success    bool   `json:"success"`
+}
+
+// GraphEdge links two crawled pages by node ID, whatever the anchor
+// texts used
+type GraphEdge struct {
+	Source int `json:"source"`
+	Target int `json:"target"`
+	Count  int `json:"count"` // occurrences on the source page
+}
+
+// Graph is one page of the link graph. Nodes and edges are paginated
+// separately, the totals tell clients how many pages to fetch.
+type Graph struct {
+	Nodes      []GraphNode `json:"nodes"`
+	Edges      []GraphEdge `json:"edges"`
+	TotalNodes int         `json:"total_nodes"`
+	TotalEdges int         `json:"total_edges"`
+}
+
+// Graph returns the nodes in [nodeOffset, nodeOffset+nodeLimit) and the
+// edges in [edgeOffset, edgeOffset+edgeLimit), a limit <= 0 means all.
+// Only links between crawled pages are edges, a redirected page is
+// reached through any URL of its chain. (thread-safe)
+func (r *Results) Graph(nodeOffset, nodeLimit, edgeOffset, edgeLimit int) Graph {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make(map[int32]int, len(r.pages))
+	nodes := make([]GraphNode, len(r.pages))
+	for i, page := range r.pages {
+		nodes[i] = GraphNode{
+			ID:         i,
+			URL:        page.URL,
+			Title:      page.Title,
+			Depth:      page.Depth,
+			StatusCode: page.StatusCode,
+			Success:    page.Success,
+		}
+		for _, u := range append([]string{page.URL}, page.Redirects...) {
+			if id, ok := r.links.ids[u]; ok {
+				if _, taken := ids[id]; !taken {
+					ids[id] = i
+				}
+			}
+		}
+	}
+
+	type pair struct{ source, target int }
+	index := make(map[pair]int)
+	edges := make([]GraphEdge, 0)
+	for _, e := range r.links.edges {
+		source, ok := ids[e.source]
+		if !ok {
+			continue
+		}
+		target, ok := ids[e.target]
+		if !ok || target == source {
+			continue
+		}
+		p := pair{source, target}
+		if i, ok := index[p]; ok {
+			edges[i].Count += int(e.count)
+			continue
+		}
+		index[p] = len(edges)
+		edges = append(edges, GraphEdge{Source: source, Target: target, Count: int(e.count)})
+	}
+
+	return Graph{
+		Nodes:      paginate(nodes, nodeOffset, nodeLimit),
+		Edges:      paginate(edges, edgeOffset, edgeLimit),
+		TotalNodes: len(nodes),
+		TotalEdges: len(edges),
+	}
+}
+
+// paginate returns items[offset:offset+limit] clamped to the slice, a
+// limit <= 0 means up to the end
+func paginate[T any](items []T, offset, limit int) []T {
+	offset = min(max(offset, 0), len(items))
+	end := len(items)
+	if limit > 0 {
+		end = min(offset+limit, end)
+	}
+	return items[offset:end]
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// RunSummary is a compact record of one crawl run
+type RunSummary struct {
+	StartURL        string        `json:"start_url"`
+	FinishedAt      time.Time     `json:"finished_at"`
+	TotalPages      int           `json:"total_pages"`
+	SuccessCount    int           `json:"success_count"`
+	FailCount       int           `json:"fail_count"`
+	AvgResponseTime float64       `json:"avg_response_time_ms"`
+	Duration        time.Duration `json:"duration_ns"`
+	Exports         string        `json:"exports,omitempty"` // directory and -name of the export files, removed with the run
+}
+
+// NewRunSummary summarizes the statistics of a finished crawl
+func NewRunSummary(startURL string, stats Stats) RunSummary {
+	return RunSummary{
+		StartURL:        startURL,
+		FinishedAt:      time.Now(),
+		TotalPages:      stats.TotalPages,
+		SuccessCount:    stats.SuccessCount,
+		FailCount:       stats.FailCount,
+		AvgResponseTime: stats.AvgResponseTime,
+		Duration:        stats.Duration,
+	}
+}
+
+// History stores run summaries in a JSON file (thread-safe)
+type History struct {
+	path      string
+	runs      []RunSummary
+	retention Retention // applied on every Add
+	mu        sync.RWMutex
+}
+
+// LoadHistory reads the history file, a missing file starts empty
+func LoadHistory(path string) (*History, error) {
+	h := &History{
+		path: path,
+		runs: make([]RunSummary, 0),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &h.runs); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// SetRetention sets the retention applied whenever a run is added
+func (h *History) SetRetention(retention Retention) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.retention = retention
+}
+
+// Add appends a run, prunes the runs out of retention and saves the
+// history file
+func (h *History) Add(run RunSummary) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.runs = append(h.runs, run)
+	_, err := h.prune(h.retention)
+	return errors.Join(err, h.save())
+}
+
+// Prune drops the runs out of retention with their export files and
+// saves the history file, returning how many were dropped
+func (h *History) Prune(retention Retention) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pruned, err := h.prune(retention)
+	if pruned == 0 {
+		return 0, err
+	}
+	return pruned, errors.Join(err, h.save())
+}
+
+// prune drops the runs out of retention and removes their export
+// files, callers hold h.mu
+func (h *History) prune(retention Retention) (int, error) {
+	now := time.Now()
+	kept := make([]RunSummary, 0, len(h.runs))
+	var dropped []RunSummary
+	for i, run := range h.runs {
+		if retention.Keeps(len(h.runs)-1-i, run.FinishedAt, now) {
+			kept = append(kept, run)
+		} else {
+			dropped = append(dropped, run)
+		}
+	}
+	h.runs = kept
+
+	keptExports := make([]string, 0, len(kept))
+	for _, run := range kept {
+		if run.Exports != "" {
+			keptExports = append(keptExports, run.Exports)
+		}
+	}
+	var errs []error
+	for _, run := range dropped {
+		if run.Exports != "" {
+			errs = append(errs, RemoveExports(run.Exports, keptExports))
+		}
+	}
+	return len(dropped), errors.Join(errs...)
+}
+
+// save writes the history file atomically, a crash mid-write keeps the
+// previous history. Callers hold h.mu.
+func (h *History) save() error {
+	data, err := json.MarshalIndent(h.runs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(h.path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Last returns the most recent n runs, oldest first
+func (h *History) Last(n int) []RunSummary {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	start := 0
+	if n > 0 && len(h.runs) > n {
+		start = len(h.runs) - n
+	}
+
+	runs := make([]RunSummary, len(h.runs)-start)
+	copy(runs, h.runs[start:])
+	return runs
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryPruneRemovesExports(t *testing.T) {
+	dir := t.TempDir()
+	touch := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	oldResults := touch("crawl_a_1_results.json")
+	oldLinks := touch("crawl_a_1_links.csv.gz")
+	newResults := touch("crawl_a_12_results.json")
+	fixed := touch("crawl_results.json")
+	other := touch("notes.txt")
+
+	h, err := LoadHistory(filepath.Join(dir, "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, exports := range []string{"crawl", "crawl_a_1", "crawl"} {
+		run := RunSummary{FinishedAt: now.Add(time.Duration(i) * time.Minute), Exports: filepath.Join(dir, exports)}
+		if err := h.Add(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.SetRetention(Retention{Runs: 1})
+	if err := h.Add(RunSummary{FinishedAt: now.Add(time.Hour), Exports: filepath.Join(dir, "crawl_a_12")}); err != nil {
+		t.Fatal(err)
+	}
+	if runs := h.Last(0); len(runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(runs))
+	}
+
+	for _, path := range []string{oldResults, oldLinks, fixed} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s of a pruned run was kept", filepath.Base(path))
+		}
+	}
+	for _, path := range []string{newResults, other} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was removed: %v", filepath.Base(path), err)
+		}
+	}
+}
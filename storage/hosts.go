@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"net/url"
+	"slices"
+	"sort"
+	"time"
+)
+
+// HostStats summarizes the pages crawled on one host
+type HostStats struct {
+	Host            string          `json:"host"`
+	Pages           int             `json:"pages"`
+	Errors          int             `json:"errors"`
+	AvgResponseTime float64         `json:"avg_response_time_ms"`
+	Bytes           int64           `json:"bytes"`            // page bodies, decompressed
+	Downloaded      int64           `json:"downloaded_bytes"` // received over the network
+	BreakerTrips    int             `json:"breaker_trips,omitempty"`
+	P95ResponseTime float64         `json:"p95_response_time_ms"`
+	Latency         []LatencySample `json:"latency,omitempty"` // adaptive throttle curve (-throttle-p95)
+}
+
+// LatencySample is one point of a host's latency curve, taken whenever
+// the adaptive throttle reconsidered its delay
+type LatencySample struct {
+	At    time.Time `json:"at"`
+	P95   float64   `json:"p95_ms"`   // over the host's recent responses
+	Delay float64   `json:"delay_ms"` // pause between requests from then on
+}
+
+// HostStats aggregates statistics per host, busiest hosts first
+func (r *Results) HostStats() []HostStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byHost := make(map[string]*HostStats)
+	times := make(map[string][]time.Duration)
+
+	for _, page := range r.pages {
+		host := page.URL
+		if u, err := url.Parse(page.URL); err == nil {
+			host = u.Host
+		}
+
+		hs, ok := byHost[host]
+		if !ok {
+			hs = &HostStats{Host: host}
+			byHost[host] = hs
+		}
+		hs.Pages++
+		hs.Bytes += page.Size
+		if !page.Success {
+			hs.Errors++
+		}
+		times[host] = append(times[host], page.ResponseTime)
+	}
+
+	hosts := make([]HostStats, 0, len(byHost))
+	for host, hs := range byHost {
+		var total time.Duration
+		for _, t := range times[host] {
+			total += t
+		}
+		hs.AvgResponseTime = float64(total.Milliseconds()) / float64(hs.Pages)
+		slices.Sort(times[host])
+		hs.P95ResponseTime = float64(times[host][(len(times[host])*95-1)/100].Microseconds()) / 1000
+		hs.BreakerTrips = r.trips[host]
+		hs.Latency = slices.Clone(r.latency[host])
+		hs.Downloaded = r.downloaded[host]
+		hosts = append(hosts, *hs)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].Pages != hosts[j].Pages {
+			return hosts[i].Pages > hosts[j].Pages
+		}
+		return hosts[i].Host < hosts[j].Host
+	})
+	return hosts
+}
+
+// AddBreakerTrip records that host's circuit breaker tripped (thread-safe)
+func (r *Results) AddBreakerTrip(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trips[host]++
+}
+
+// AddLatencySample appends a point to host's latency curve (thread-safe)
+func (r *Results) AddLatencySample(host string, sample LatencySample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latency[host] = append(r.latency[host], sample)
+}
+
+// SetDownloaded records the bytes received per host so far (thread-safe)
+func (r *Results) SetDownloaded(byHost map[string]int64) {
+	if byHost == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downloaded = byHost
+}
+
+// SetBandwidthPrice sets the price of a GB (10⁹ bytes) received, for the
+// estimated cost of a crawl on a metered connection
+func (r *Results) SetBandwidthPrice(perGB float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pricePerGB = perGB
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Term kinds
+const (
+	TermKeyword = "keyword"
+	TermEntity  = "entity"
+)
+
+// Term is a keyword or entity with its number of occurrences
+type Term struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+	Pages int    `json:"pages,omitempty"` // site-wide summaries: pages using the term
+}
+
+// KeywordSummary aggregates the keywords and entities of all pages
+type KeywordSummary struct {
+	Keywords []Term `json:"keywords"`
+	Entities []Term `json:"entities"`
+}
+
+// Keywords sums the per-page terms site-wide and returns the n most
+// frequent of each kind. Pages only keep their top terms, so rare
+// terms are undercounted.
+func (r *Results) Keywords(n int) KeywordSummary {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keywords := make(map[string]*Term)
+	entities := make(map[string]*Term)
+	for _, page := range r.pages {
+		addTerms(keywords, page.Keywords)
+		addTerms(entities, page.Entities)
+	}
+	return KeywordSummary{
+		Keywords: topTerms(keywords, n),
+		Entities: topTerms(entities, n),
+	}
+}
+
+// addTerms adds the terms of one page to a site-wide tally
+func addTerms(tally map[string]*Term, terms []Term) {
+	for _, t := range terms {
+		total, ok := tally[t.Term]
+		if !ok {
+			total = &Term{Term: t.Term}
+			tally[t.Term] = total
+		}
+		total.Count += t.Count
+		total.Pages++
+	}
+}
+
+// topTerms returns the n most frequent terms, ties alphabetically
+func topTerms(tally map[string]*Term, n int) []Term {
+	terms := make([]Term, 0, len(tally))
+	for _, t := range tally {
+		terms = append(terms, *t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	if n > 0 && len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
+
+// ExportKeywordsCSV exports the site-wide top n keywords and entities,
+// followed by the top n of every page
+func (r *Results) ExportKeywordsCSV(filename string, n int) error {
+	summary := r.Keywords(n)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, r.export)
+		if err := writeSchemaRow(writer, r.export, "keywords"); err != nil {
+			return err
+		}
+
+		header := []string{"Scope", "Kind", "Term", "Count", "Pages"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		write := func(scope, kind string, terms []Term, limit int) error {
+			for i, t := range terms {
+				if limit > 0 && i == limit {
+					break
+				}
+				pages := ""
+				if t.Pages > 0 {
+					pages = fmt.Sprintf("%d", t.Pages)
+				}
+				if err := writer.Write([]string{scope, kind, t.Term, fmt.Sprintf("%d", t.Count), pages}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if err := write("site", TermKeyword, summary.Keywords, 0); err != nil {
+			return err
+		}
+		if err := write("site", TermEntity, summary.Entities, 0); err != nil {
+			return err
+		}
+		for _, page := range r.orderedPages() {
+			if err := write(page.URL, TermKeyword, page.Keywords, n); err != nil {
+				return err
+			}
+			if err := write(page.URL, TermEntity, page.Entities, n); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
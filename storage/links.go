@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Link is one link occurrence on a crawled page
+type Link struct {
+	Href string `json:"href"`          // as written in the page
+	URL  string `json:"url,omitempty"` // resolved and normalized, empty if invalid
+	Text string `json:"text"`
+	// Position is where the link sits: nav, header, footer, sidebar or
+	// content, so boilerplate links can be told from editorial ones
+	Position string `json:"position,omitempty"`
+	Rel      string `json:"rel,omitempty"` // e.g. "nofollow", "sponsored", "ugc", "noopener"
+}
+
+// AnchorText is how often a target URL is linked with one anchor text
+type AnchorText struct {
+	Target string `json:"target"`
+	Text   string `json:"text"`
+	Links  int    `json:"links"` // occurrences
+	Pages  int    `json:"pages"` // distinct linking pages
+}
+
+// AnchorTexts aggregates the anchor texts used for every linked URL,
+// sorted by target then most used text
+func (r *Results) AnchorTexts() []AnchorText {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type key struct{ target, text string }
+	counts := make(map[key]*AnchorText)
+	sources := make(map[key]map[string]bool)
+	for _, page := range r.pages {
+		for _, e := range r.links.outgoing(page.URL) {
+			k := key{e.Target, e.Text}
+			at, ok := counts[k]
+			if !ok {
+				at = &AnchorText{Target: e.Target, Text: e.Text}
+				counts[k] = at
+				sources[k] = make(map[string]bool)
+			}
+			at.Links += e.Count
+			sources[k][page.URL] = true
+		}
+	}
+
+	texts := make([]AnchorText, 0, len(counts))
+	for k, at := range counts {
+		at.Pages = len(sources[k])
+		texts = append(texts, *at)
+	}
+	sort.Slice(texts, func(i, j int) bool {
+		a, b := texts[i], texts[j]
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.Links != b.Links {
+			return a.Links > b.Links
+		}
+		return a.Text < b.Text
+	})
+	return texts
+}
+
+// ExportAnchorsCSV exports the anchor texts used per target URL
+func (r *Results) ExportAnchorsCSV(filename string) error {
+	texts := r.AnchorTexts()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "anchors"); err != nil {
+			return err
+		}
+
+		header := []string{"Target URL", "Anchor Text", "Links", "Pages"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, at := range texts {
+			row := []string{at.Target, at.Text, fmt.Sprintf("%d", at.Links), fmt.Sprintf("%d", at.Pages)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+)
+
+// ExportMatchesCSV exports one row per -grep match
+func (r *Results) ExportMatchesCSV(filename string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return WriteFile(filename, r.writeMatchesCSV)
+}
+
+// writeMatchesCSV writes the matched snippets of every page that matched
+func (r *Results) writeMatchesCSV(w io.Writer) error {
+	writer := newCSVWriter(w, r.export)
+	if err := writeSchemaRow(writer, r.export, "matches"); err != nil {
+		return err
+	}
+
+	header := []string{"URL", "Match", "Snippet"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, page := range r.orderedPages() {
+		for i, snippet := range page.GrepMatches {
+			row := []string{page.URL, fmt.Sprintf("%d", i+1), snippet}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Migration verdicts
+const (
+	MigrationOK          = "ok"           // one redirect to the expected URL, which answers 200
+	MigrationChain       = "chain"        // reaches the expected URL through several redirects
+	MigrationWrongTarget = "wrong_target" // redirects elsewhere
+	MigrationNoRedirect  = "no_redirect"  // the old URL answers without redirecting
+	MigrationTargetError = "target_error" // redirects to the expected URL, which fails
+	MigrationFailed      = "failed"       // no response, e.g. a redirect loop
+)
+
+// MigrationRule maps an old URL to the URL it must redirect to
+type MigrationRule struct {
+	Old      string
+	Expected string
+}
+
+// MigrationCheck is the verification of one MigrationRule
+type MigrationCheck struct {
+	Old        string `json:"old_url"`
+	Expected   string `json:"expected_url"`
+	Actual     string `json:"actual_url"` // final URL after redirects
+	Hops       int    `json:"hops"`
+	StatusCode int    `json:"status_code"` // of the final URL
+	Verdict    string `json:"verdict"`
+}
+
+// LoadMigrationMap reads old,new URL pairs from a CSV file, compressed
+// or not. A header row is skipped, relative new URLs resolve against
+// the old one.
+func LoadMigrationMap(filename string) ([]MigrationRule, error) {
+	var rules []MigrationRule
+	err := readFile(filename, func(r io.Reader) error {
+		reader := newCSVReader(r)
+		reader.FieldsPerRecord = -1
+		reader.Comment = '#'
+		for line := 1; ; line++ {
+			row, err := reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if len(row) < 2 {
+				return fmt.Errorf("%s:%d: want old URL,new URL", filename, line)
+			}
+			oldURL, err := url.Parse(strings.TrimSpace(row[0]))
+			if err != nil || !oldURL.IsAbs() {
+				if line == 1 {
+					continue // header
+				}
+				return fmt.Errorf("%s:%d: invalid old URL %q", filename, line, row[0])
+			}
+			expected, err := oldURL.Parse(strings.TrimSpace(row[1]))
+			if err != nil {
+				return fmt.Errorf("%s:%d: invalid new URL %q", filename, line, row[1])
+			}
+			rules = append(rules, MigrationRule{Old: oldURL.String(), Expected: expected.String()})
+		}
+	})
+	return rules, err
+}
+
+// SetMigrationMap stores the redirects a -migration-map run verifies
+func (r *Results) SetMigrationMap(rules []MigrationRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.migration = rules
+}
+
+// MigrationChecks verifies every migration rule against the crawled
+// old URLs, in file order. Old URLs that weren't crawled are left out.
+func (r *Results) MigrationChecks() []MigrationCheck {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byURL := make(map[string]*Page, len(r.pages))
+	for _, page := range r.pages {
+		byURL[page.URL] = page
+	}
+
+	var checks []MigrationCheck
+	for _, rule := range r.migration {
+		page := byURL[rule.Old]
+		if page == nil {
+			continue
+		}
+		check := MigrationCheck{Old: rule.Old, Expected: rule.Expected, Actual: page.URL, Hops: len(page.Redirects), StatusCode: page.StatusCode}
+		if check.Hops > 0 {
+			check.Actual = page.Redirects[check.Hops-1]
+		}
+		check.Verdict = migrationVerdict(check, page.Success, page.ErrorType)
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// migrationVerdict classifies a check, an old URL kept as is only
+// needs to answer 200
+func migrationVerdict(c MigrationCheck, success bool, errorType ErrorType) string {
+	switch {
+	case errorType == ErrorRedirectLoop || errorType == ErrorTooManyRedirects || (c.StatusCode == 0 && !success):
+		return MigrationFailed
+	case c.Actual != c.Expected && c.Hops == 0:
+		return MigrationNoRedirect
+	case c.Actual != c.Expected:
+		return MigrationWrongTarget
+	case !success:
+		return MigrationTargetError
+	case c.Hops > 1:
+		return MigrationChain
+	}
+	return MigrationOK
+}
+
+// ExportMigrationCSV exports the migration checks
+func (r *Results) ExportMigrationCSV(filename string) error {
+	checks := r.MigrationChecks()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "migration"); err != nil {
+			return err
+		}
+
+		header := []string{"Old URL", "Expected URL", "Actual URL", "Hops", "Status Code", "Verdict"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, c := range checks {
+			row := []string{c.Old, c.Expected, c.Actual, fmt.Sprintf("%d", c.Hops), fmt.Sprintf("%d", c.StatusCode), c.Verdict}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+)
+
+// OGImageIssue is a page whose social preview image is missing or broken
+type OGImageIssue struct {
+	URL        string `json:"url"`
+	Image      string `json:"image,omitempty"`
+	Problem    string `json:"problem"` // "missing", "broken" or "unchecked"
+	StatusCode int    `json:"status_code,omitempty"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// OGImageIssues lists successful pages without a usable og:image
+func (r *Results) OGImageIssues() []OGImageIssue {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	issues := make([]OGImageIssue, 0)
+	for _, page := range r.orderedPages() {
+		if !page.Success || page.AlternateOf != "" {
+			continue
+		}
+		if page.OGImage == "" {
+			issues = append(issues, OGImageIssue{URL: page.URL, Problem: "missing"})
+			continue
+		}
+
+		asset, ok := r.assets[page.OGImage]
+		switch {
+		case !ok:
+			issues = append(issues, OGImageIssue{URL: page.URL, Image: page.OGImage, Problem: "unchecked"})
+		case !asset.OK:
+			issues = append(issues, OGImageIssue{
+				URL:        page.URL,
+				Image:      page.OGImage,
+				Problem:    "broken",
+				StatusCode: asset.StatusCode,
+				Width:      asset.Width,
+				Height:     asset.Height,
+				Detail:     asset.Error,
+			})
+		}
+	}
+	return issues
+}
+
+// ExportOGImagesCSV exports pages with missing or broken og:images
+func (r *Results) ExportOGImagesCSV(filename string) error {
+	issues := r.OGImageIssues()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "og_images"); err != nil {
+			return err
+		}
+
+		header := []string{"URL", "OG Image", "Problem", "Status Code", "Width", "Height", "Detail"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			row := []string{
+				issue.URL,
+				issue.Image,
+				issue.Problem,
+				fmt.Sprintf("%d", issue.StatusCode),
+				fmt.Sprintf("%d", issue.Width),
+				fmt.Sprintf("%d", issue.Height),
+				issue.Detail,
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// LoadPages reads the results.json of an earlier run, keyed by URL.
+// Only successful pages are kept, a missing file returns no pages.
+func LoadPages(filename string) (map[string]*Page, error) {
+	pages, err := readPages(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byURL := make(map[string]*Page, len(pages))
+	for _, page := range pages {
+		if page.Success {
+			byURL[page.URL] = page
+		}
+	}
+	return byURL, nil
+}
+
+// readPages decodes every page of a results.json
+func readPages(filename string) ([]*Page, error) {
+	var pages []*Page
+	err := readFile(filename, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&pages)
+	})
+	return pages, err
+}
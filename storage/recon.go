@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Recon finding categories
+const (
+	ReconSubdomain        = "subdomain"
+	ReconDirectoryListing = "directory_listing"
+	ReconInterestingFile  = "interesting_file"
+	ReconCommentURL       = "comment_url"
+)
+
+// interestingExts are references worth a look during reconnaissance:
+// backups, dumps, configuration and key material
+var interestingExts = map[string]bool{
+	".bak": true, ".backup": true, ".old": true, ".orig": true, ".swp": true, ".tmp": true,
+	".sql": true, ".db": true, ".sqlite": true, ".dump": true,
+	".env": true, ".ini": true, ".conf": true, ".config": true, ".yml": true, ".yaml": true,
+	".log": true, ".key": true, ".pem": true, ".p12": true,
+	".zip": true, ".tar": true, ".gz": true, ".tgz": true, ".7z": true, ".rar": true,
+	".git": true, ".svn": true, ".ds_store": true,
+}
+
+// Finding is one item of the reconnaissance report
+type Finding struct {
+	Category string `json:"category"`
+	Value    string `json:"value"`
+	FoundOn  string `json:"found_on"` // first page or script it was seen on
+}
+
+// Recon aggregates subdomains of the start URLs' registrable domains
+// (from links and certificates),
+// directory listings, references to interesting files and URLs hidden
+// in HTML comments, from everything the crawl has seen
+func (r *Results) Recon() []Finding {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	findings := make([]Finding, 0)
+	seen := make(map[[2]string]bool)
+	add := func(category, value, foundOn string) {
+		key := [2]string{category, value}
+		if !seen[key] {
+			seen[key] = true
+			findings = append(findings, Finding{Category: category, Value: value, FoundOn: foundOn})
+		}
+	}
+	inspect := func(rawURL, foundOn string) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return
+		}
+		if interestingExts[strings.ToLower(path.Ext(u.Path))] {
+			add(ReconInterestingFile, rawURL, foundOn)
+		}
+	}
+
+	for _, sub := range r.subdomains() {
+		if !sub.Crawled || sub.Source == SubdomainCertificate {
+			add(ReconSubdomain, sub.Host, sub.FoundOn)
+		}
+	}
+	for _, page := range r.orderedPages() {
+		inspect(page.URL, page.URL)
+		if page.Success && strings.HasPrefix(page.Title, "Index of ") {
+			add(ReconDirectoryListing, page.URL, page.URL)
+		}
+		for _, e := range r.links.outgoing(page.URL) {
+			inspect(e.Target, page.URL)
+		}
+		for _, e := range page.Embeds {
+			inspect(e.URL, page.URL)
+		}
+		for _, u := range page.CommentURLs {
+			add(ReconCommentURL, u, page.URL)
+			inspect(u, page.URL)
+		}
+	}
+	for _, e := range r.sortedEndpoints() {
+		inspect(e.URL, e.Source)
+	}
+	return findings
+}
+
+// ExportReconCSV exports the reconnaissance report
+func (r *Results) ExportReconCSV(filename string) error {
+	findings := r.Recon()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "recon"); err != nil {
+			return err
+		}
+
+		header := []string{"Category", "Value", "Found On"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, f := range findings {
+			if err := writer.Write([]string{f.Category, f.Value, f.FoundOn}); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
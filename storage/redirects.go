@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Chain kinds
+const (
+	ChainRedirectLink = "redirect_link"   // internal link to a URL that redirects
+	ChainCanonical    = "canonical_chain" // canonical pointing at a URL that isn't final
+	ChainLong         = "long_redirect"   // page redirecting through more than maxRedirectHops
+	ChainLoop         = "redirect_loop"   // page whose redirects come back to a visited URL
+	ChainMetaRefresh  = "meta_refresh"    // page redirecting with a meta refresh or Refresh header
+)
+
+// maxRedirectHops is the longest redirect chain not reported as long
+const maxRedirectHops = 5
+
+// Chain is a link or canonical that takes more than one hop to resolve
+type Chain struct {
+	Kind      string   `json:"kind"`
+	Source    string   `json:"source"` // linking page, or page declaring the canonical
+	Target    string   `json:"target"` // linked URL or canonical URL
+	Hops      []string `json:"hops"`   // URLs after Target, ending with the suggested final URL
+	Suggested string   `json:"suggested"`
+}
+
+// Chains reports internal links whose targets redirect, canonical chains
+// longer than one hop, redirect loops, long redirect chains and meta
+// refreshes, with the URL they should point to instead
+func (r *Results) Chains() []Chain {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	crawled := make(map[string]*Page, len(r.pages))
+	for _, page := range r.pages {
+		crawled[page.URL] = page
+	}
+
+	chains := make([]Chain, 0)
+	for _, page := range r.orderedPages() {
+		broken := page.ErrorType == ErrorRedirectLoop || page.ErrorType == ErrorTooManyRedirects
+		if len(page.Redirects) > 0 && !broken {
+			for _, source := range r.links.sources(page.URL) {
+				chains = append(chains, Chain{
+					Kind:      ChainRedirectLink,
+					Source:    source,
+					Target:    page.URL,
+					Hops:      page.Redirects,
+					Suggested: page.Redirects[len(page.Redirects)-1],
+				})
+			}
+		}
+
+		switch {
+		case page.ErrorType == ErrorRedirectLoop:
+			chains = append(chains, Chain{Kind: ChainLoop, Source: page.URL, Target: page.URL, Hops: page.Redirects})
+		case len(page.Redirects) > maxRedirectHops:
+			chains = append(chains, Chain{
+				Kind:      ChainLong,
+				Source:    page.URL,
+				Target:    page.URL,
+				Hops:      page.Redirects,
+				Suggested: page.Redirects[len(page.Redirects)-1],
+			})
+		}
+		if page.MetaRefresh != "" && page.MetaRefresh != page.URL {
+			chains = append(chains, Chain{
+				Kind:      ChainMetaRefresh,
+				Source:    page.URL,
+				Target:    page.URL,
+				Hops:      []string{page.MetaRefresh},
+				Suggested: page.MetaRefresh,
+			})
+		}
+
+		if page.Canonical != "" && page.Canonical != page.URL {
+			if hops := canonicalHops(crawled, page.Canonical); len(hops) > 0 {
+				chains = append(chains, Chain{
+					Kind:      ChainCanonical,
+					Source:    page.URL,
+					Target:    page.Canonical,
+					Hops:      hops,
+					Suggested: hops[len(hops)-1],
+				})
+			}
+		}
+	}
+	return chains
+}
+
+// canonicalHops follows redirects and canonicals from a canonical
+// target until a crawled page is its own canonical, stopping on loops
+func canonicalHops(crawled map[string]*Page, target string) []string {
+	var hops []string
+	seen := map[string]bool{target: true}
+	for current := target; ; {
+		page := crawled[current]
+		if page == nil {
+			return hops
+		}
+
+		next := page.Canonical
+		if len(page.Redirects) > 0 {
+			next = page.Redirects[len(page.Redirects)-1]
+		}
+		if next == "" || next == current || seen[next] {
+			return hops
+		}
+		seen[next] = true
+		hops = append(hops, next)
+		current = next
+	}
+}
+
+// ExportChainsCSV exports redirecting links and canonical chains
+func (r *Results) ExportChainsCSV(filename string) error {
+	chains := r.Chains()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "chains"); err != nil {
+			return err
+		}
+
+		header := []string{"Kind", "Source URL", "Target URL", "Hops", "Chain", "Suggested URL"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, chain := range chains {
+			row := []string{
+				chain.Kind,
+				chain.Source,
+				chain.Target,
+				fmt.Sprintf("%d", len(chain.Hops)),
+				strings.Join(chain.Hops, " -> "),
+				chain.Suggested,
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Slowest returns the n pages with the highest response time
+func (r *Results) Slowest(n int) []*Page {
+	return r.top(n, func(a, b *Page) bool { return a.ResponseTime > b.ResponseTime })
+}
+
+// Largest returns the n pages with the biggest payload
+func (r *Results) Largest(n int) []*Page {
+	return r.top(n, func(a, b *Page) bool { return a.Size > b.Size })
+}
+
+// top sorts a copy of the successful pages and keeps the first n
+func (r *Results) top(n int, less func(a, b *Page) bool) []*Page {
+	r.mu.RLock()
+	pages := make([]*Page, 0, len(r.pages))
+	for _, page := range r.orderedPages() {
+		if page.Success {
+			pages = append(pages, page)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.SliceStable(pages, func(i, j int) bool { return less(pages[i], pages[j]) })
+	if n > 0 && len(pages) > n {
+		pages = pages[:n]
+	}
+	return pages
+}
+
+// ExportTopCSV exports a ranked page list such as Slowest or Largest
+func (r *Results) ExportTopCSV(filename string, pages []*Page) error {
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		return writeTopCSV(w, cfg, pages)
+	})
+}
+
+// writeTopCSV writes one ranked row per page
+func writeTopCSV(w io.Writer, cfg ExportConfig, pages []*Page) error {
+	writer := newCSVWriter(w, cfg)
+	if err := writeSchemaRow(writer, cfg, "top"); err != nil {
+		return err
+	}
+
+	header := []string{"Rank", "URL", "Response Time (ms)", "Size (bytes)"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for i, page := range pages {
+		row := []string{
+			fmt.Sprintf("%d", i+1),
+			page.URL,
+			fmt.Sprintf("%d", page.ResponseTime.Milliseconds()),
+			fmt.Sprintf("%d", page.Size),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
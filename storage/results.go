@@ -1,24 +1,82 @@
 package storage
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"net/http"
 	"sync"
 	"time"
 )
 
+// ErrorType classifies why a page failed
+type ErrorType string
+
+const (
+	ErrorDNS              ErrorType = "dns"
+	ErrorTLS              ErrorType = "tls"
+	ErrorTimeout          ErrorType = "timeout"
+	ErrorConnRefused      ErrorType = "connection_refused"
+	ErrorNetwork          ErrorType = "network"
+	ErrorHTTP4xx          ErrorType = "http_4xx"
+	ErrorHTTP5xx          ErrorType = "http_5xx"
+	ErrorHTTPOther        ErrorType = "http_other"
+	ErrorParse            ErrorType = "parse"
+	ErrorRedirectLoop     ErrorType = "redirect_loop"
+	ErrorTooManyRedirects ErrorType = "too_many_redirects"
+)
+
 // Page represents a crawled page
 type Page struct {
-	URL          string        `json:"url"`
-	Title        string        `json:"title"`
-	Description  string        `json:"description"`
-	Links        []string      `json:"links"`
-	ResponseTime time.Duration `json:"response_time_ms"`
-	Success      bool          `json:"success"`
-	Error        string        `json:"error,omitempty"`
-	CrawledAt    time.Time     `json:"crawled_at"`
+	URL           string        `json:"url"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	Links         []string      `json:"links"` // filled from the edge table on export
+	Depth         int           `json:"depth"`
+	StatusCode    int           `json:"status_code,omitempty"`
+	ResponseTime  time.Duration `json:"response_time_ms"`
+	Size          int64         `json:"size_bytes"`
+	Success       bool          `json:"success"`
+	Error         string        `json:"error,omitempty"`
+	ErrorType     ErrorType     `json:"error_type,omitempty"`
+	CrawledAt     time.Time     `json:"crawled_at"`
+	Series        string        `json:"series,omitempty"`      // pagination series key
+	SeriesPage    int           `json:"series_page,omitempty"` // position within the series
+	AMPURL        string        `json:"amp_url,omitempty"`
+	MobileURL     string        `json:"mobile_url,omitempty"`
+	AlternateOf   string        `json:"alternate_of,omitempty"`   // canonical page of an AMP/mobile version
+	NoIndex       bool          `json:"noindex,omitempty"`        // excluded from indexing by meta robots or X-Robots-Tag
+	NoFollow      bool          `json:"nofollow,omitempty"`       // links were not followed because of robots directives
+	NotModified   bool          `json:"not_modified,omitempty"`   // 304 since the previous run, data carried over
+	GrepMatches   []string      `json:"grep_matches,omitempty"`   // -grep matches with surrounding context
+	Icons         []string      `json:"icons,omitempty"`          // declared favicons
+	Manifest      string        `json:"manifest,omitempty"`       // declared web app manifest
+	OGImage       string        `json:"og_image,omitempty"`       // social preview image
+	Redirects     []string      `json:"redirects,omitempty"`      // URLs redirected through, ending with the final URL
+	Canonical     string        `json:"canonical,omitempty"`      // rel=canonical target
+	Anchors       []Link        `json:"anchors,omitempty"`        // deduplicated links with anchor text, as Links
+	Embeds        []Embed       `json:"embeds,omitempty"`         // iframes and media sources
+	CommentURLs   []string      `json:"comment_urls,omitempty"`   // URLs in HTML comments (-recon)
+	TLSUnverified bool          `json:"tls_unverified,omitempty"` // fetched with -insecure-skip-verify
+	Parent        string        `json:"parent,omitempty"`         // page the URL was first discovered on
+	MetaRefresh   string        `json:"meta_refresh,omitempty"`   // meta refresh or Refresh header target
+	Language      string        `json:"language,omitempty"`       // <html lang>, or the Content-Language header
+	ContentHash   string        `json:"content_hash,omitempty"`   // sha256 of the body
+	DuplicateOf   string        `json:"duplicate_of,omitempty"`   // first URL crawled with the same body, links not followed
+	Text          string        `json:"-"`                        // visible text, kept for snapshots
+	Headers       http.Header   `json:"-"`                        // response headers, of the last redirect
+	Body          string        `json:"-"`                        // raw HTML, kept with -store-bodies
+	Article       *Article      `json:"-"`                        // main content, exported as JSON Lines
+	Headings      []string      `json:"-"`                        // h1-h6 texts, kept for search records
+	Keywords      []Term        `json:"keywords,omitempty"`       // most frequent words of the body text (-keywords)
+	Entities      []Term        `json:"entities,omitempty"`       // most frequent capitalized names (-keywords)
+}
+
+// DepthStats summarizes the pages crawled at one depth level
+type DepthStats struct {
+	Pages           int
+	Errors          int
+	AvgResponseTime float64
 }
 
 // Stats represents crawling statistics
@@ -28,97 +86,243 @@ type Stats struct {
 	AvgResponseTime float64
 	SuccessCount    int
 	FailCount       int
+	ErrorTypes      map[ErrorType]int
+	StatusCodes     map[int]int
+	Depths          map[int]DepthStats
 	Duration        time.Duration
+	Queued          int           // URLs waiting in the frontier or in flight
+	Progress        float64       // estimated completion percentage
+	ETA             time.Duration // estimated time until the frontier is drained
+	NoIndex         int           // pages excluded from indexing by robots directives
+	NoFollow        int           // pages whose links were not followed
+	NotModified     int           // pages unchanged since the previous run
+	GrepMatches     int           // pages matching the -grep pattern
+	BreakerTrips    int           // times a host was paused by its circuit breaker
+	Downloaded      int64         // bytes received over the network, TLS and headers included
+	Duplicates      int           // pages with the same body as an earlier page
+	BandwidthCost   float64       // Downloaded priced with SetBandwidthPrice
 }
 
 // Results stores all crawled pages (thread-safe)
 type Results struct {
-	pages    []*Page
-	mu       sync.RWMutex
-	duration time.Duration
+	pages         []*Page
+	mu            sync.RWMutex
+	duration      time.Duration
+	queued        int
+	elapsed       time.Duration
+	export        ExportConfig
+	assets        map[string]*Asset          // checked favicons/manifests/og:images by URL
+	links         *linkGraph                 // deduplicated link edges
+	sitemap       []string                   // URLs listed in the site's sitemap
+	traffic       map[string]int             // hits per URL imported from an access log
+	endpoints     map[Endpoint]bool          // URLs found in JavaScript
+	certNames     map[string][]string        // TLS certificate DNS names by host
+	skipped       map[string]*Skipped        // URLs refused by the crawl guards
+	textChanges   []TextChange               // pages whose text changed since their last snapshot
+	trips         map[string]int             // circuit breaker trips by host
+	latency       map[string][]LatencySample // adaptive throttle curves by host
+	downloaded    map[string]int64           // bytes received by host
+	pricePerGB    float64                    // bandwidth price, see SetBandwidthPrice
+	externals     map[string]*ExternalLink   // checked out-of-scope link targets by URL
+	externalOrder []string                   // keys of externals in check order
+	violations    []*RuleViolation           // failed page rule checks
+	migration     []MigrationRule            // redirects verified by -migration-map
 }
 
 // NewResults creates a new Results instance
 func NewResults() *Results {
 	return &Results{
-		pages: make([]*Page, 0),
+		pages:     make([]*Page, 0),
+		assets:    make(map[string]*Asset),
+		links:     newLinkGraph(),
+		endpoints: make(map[Endpoint]bool),
+		certNames: make(map[string][]string),
+		skipped:   make(map[string]*Skipped),
+		trips:     make(map[string]int),
+		latency:   make(map[string][]LatencySample),
+		externals: make(map[string]*ExternalLink),
 	}
 }
 
 // AddPage adds a crawled page to results (thread-safe)
-func (r *Results) AddPage(url, title, description string, links []string, responseTime time.Duration, err error) {
+func (r *Results) AddPage(page *Page, err error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	page := &Page{
-		URL:          url,
-		Title:        title,
-		Description:  description,
-		Links:        links,
-		ResponseTime: responseTime,
-		Success:      err == nil,
-		CrawledAt:    time.Now(),
-	}
+	page.Success = err == nil
+	page.CrawledAt = time.Now()
 
 	if err != nil {
 		page.Error = err.Error()
 	}
 
+	// Links live in the edge table, not on the page
+	r.links.addPage(page)
+	page.Links, page.Anchors = nil, nil
+
 	r.pages = append(r.pages, page)
 }
 
+// Reset discards all pages before a new crawl
+func (r *Results) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pages = make([]*Page, 0)
+	r.assets = make(map[string]*Asset)
+	r.links = newLinkGraph()
+	r.sitemap = nil
+	r.traffic = nil
+	r.migration = nil
+	r.endpoints = make(map[Endpoint]bool)
+	r.certNames = make(map[string][]string)
+	r.skipped = make(map[string]*Skipped)
+	r.textChanges = nil
+	r.trips = make(map[string]int)
+	r.latency = make(map[string][]LatencySample)
+	r.downloaded = nil
+	r.externals = make(map[string]*ExternalLink)
+	r.externalOrder = nil
+	r.violations = nil
+	r.duration = 0
+	r.queued = 0
+	r.elapsed = 0
+}
+
 // GetPages returns all pages (thread-safe)
 func (r *Results) GetPages() []*Page {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Return copy to prevent race conditions
+	// Return copies to prevent race conditions
 	pages := make([]*Page, len(r.pages))
-	copy(pages, r.pages)
+	for i, page := range r.pages {
+		pages[i] = r.withLinks(page)
+	}
 	return pages
 }
 
+// PageByID returns the page at position id in crawl order, as numbered
+// by GetPages and Graph (thread-safe)
+func (r *Results) PageByID(id int) (*Page, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if id < 0 || id >= len(r.pages) {
+		return nil, false
+	}
+	return r.withLinks(r.pages[id]), true
+}
+
+// Count returns the number of stored pages (thread-safe)
+func (r *Results) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.pages)
+}
+
 // GetStats calculates and returns statistics
 func (r *Results) GetStats() Stats {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	stats := Stats{
-		TotalPages: len(r.pages),
-		Duration:   r.duration,
+		TotalPages:  len(r.pages),
+		ErrorTypes:  make(map[ErrorType]int),
+		StatusCodes: make(map[int]int),
+		Depths:      make(map[int]DepthStats),
+		Duration:    r.duration,
+		Queued:      r.queued,
 	}
+	for _, n := range r.trips {
+		stats.BreakerTrips += n
+	}
+	for _, n := range r.downloaded {
+		stats.Downloaded += n
+	}
+	stats.BandwidthCost = float64(stats.Downloaded) / 1e9 * r.pricePerGB
 
 	if stats.TotalPages == 0 {
 		return stats
 	}
 
+	// Extrapolate from the throughput so far
+	stats.Progress = float64(stats.TotalPages) / float64(stats.TotalPages+stats.Queued) * 100
+	if r.elapsed > 0 {
+		perPage := r.elapsed / time.Duration(stats.TotalPages)
+		stats.ETA = perPage * time.Duration(stats.Queued)
+	}
+
 	var totalTime time.Duration
-	uniqueLinks := make(map[string]bool)
+	depthTime := make(map[int]time.Duration)
 
 	for _, page := range r.pages {
 		totalTime += page.ResponseTime
+		if page.StatusCode != 0 {
+			stats.StatusCodes[page.StatusCode]++
+		}
+
+		depth := stats.Depths[page.Depth]
+		depth.Pages++
+		if !page.Success {
+			depth.Errors++
+		}
+		stats.Depths[page.Depth] = depth
+		depthTime[page.Depth] += page.ResponseTime
 		if page.Success {
 			stats.SuccessCount++
 		} else {
 			stats.FailCount++
+			stats.ErrorTypes[page.ErrorType]++
 		}
 
-		for _, link := range page.Links {
-			uniqueLinks[link] = true
+		if page.NoIndex {
+			stats.NoIndex++
+		}
+		if page.NoFollow {
+			stats.NoFollow++
 		}
+		if page.NotModified {
+			stats.NotModified++
+		}
+		if page.DuplicateOf != "" {
+			stats.Duplicates++
+		}
+		if len(page.GrepMatches) > 0 {
+			stats.GrepMatches++
+		}
+
 	}
 
-	stats.UniqueLinks = len(uniqueLinks)
+	stats.UniqueLinks = len(r.links.in)
 	stats.AvgResponseTime = float64(totalTime.Milliseconds()) / float64(stats.TotalPages)
+	for d, depth := range stats.Depths {
+		depth.AvgResponseTime = float64(depthTime[d].Milliseconds()) / float64(depth.Pages)
+		stats.Depths[d] = depth
+	}
 
 	return stats
 }
 
-// SetDuration sets the total crawl duration
+// SetDuration sets the total crawl duration and clears the frontier
 func (r *Results) SetDuration(d time.Duration) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.duration = d
+	r.queued = 0
+}
+
+// SetProgress records the frontier size for completion estimates.
+// visited URLs without a stored page yet are counted as in flight.
+func (r *Results) SetProgress(queued, visited int, elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if inFlight := visited - len(r.pages); inFlight > 0 {
+		queued += inFlight
+	}
+	r.queued = queued
+	r.elapsed = elapsed
 }
 
 // ExportJSON exports results to JSON file
@@ -126,15 +330,16 @@ func (r *Results) ExportJSON(filename string) error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(r.pages)
+	return WriteFile(filename, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		pages := r.orderedPages()
+		exported := make([]*Page, len(pages))
+		for i, page := range pages {
+			exported[i] = r.withLinks(page)
+		}
+		return encoder.Encode(exported)
+	})
 }
 
 // ExportCSV exports results to CSV file
@@ -142,38 +347,56 @@ func (r *Results) ExportCSV(filename string) error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+	return WriteFile(filename, func(w io.Writer) error {
+		return r.writeCSV(w, r.orderedPages())
+	})
+}
+
+// pagesHeader are the columns of the page summary
+var pagesHeader = []string{"URL", "Title", "Description", "Links Count", "Response Time (ms)", "Success", "Error", "Series", "Size (bytes)", "Error Type", "Status Code", "Noindex", "Nofollow", "Not Modified", "Grep Matches", "TLS Unverified", "Parent"}
+
+// pageRow returns the page summary columns of page, callers hold r.mu
+func (r *Results) pageRow(page *Page) []string {
+	return []string{
+		page.URL,
+		page.Title,
+		page.Description,
+		fmt.Sprintf("%d", len(r.links.targets(page.URL))),
+		fmt.Sprintf("%d", page.ResponseTime.Milliseconds()),
+		fmt.Sprintf("%t", page.Success),
+		page.Error,
+		page.Series,
+		fmt.Sprintf("%d", page.Size),
+		string(page.ErrorType),
+		fmt.Sprintf("%d", page.StatusCode),
+		fmt.Sprintf("%t", page.NoIndex),
+		fmt.Sprintf("%t", page.NoFollow),
+		fmt.Sprintf("%t", page.NotModified),
+		fmt.Sprintf("%d", len(page.GrepMatches)),
+		fmt.Sprintf("%t", page.TLSUnverified),
+		page.Parent,
 	}
-	defer file.Close()
+}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// writeCSV writes the page summary rows of pages
+func (r *Results) writeCSV(w io.Writer, pages []*Page) error {
+	writer := newCSVWriter(w, r.export)
+	if err := writeSchemaRow(writer, r.export, "pages"); err != nil {
+		return err
+	}
 
-	// Write header
-	header := []string{"URL", "Title", "Description", "Links Count", "Response Time (ms)", "Success", "Error"}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(pagesHeader); err != nil {
 		return err
 	}
 
-	// Write rows
-	for _, page := range r.pages {
-		row := []string{
-			page.URL,
-			page.Title,
-			page.Description,
-			fmt.Sprintf("%d", len(page.Links)),
-			fmt.Sprintf("%d", page.ResponseTime.Milliseconds()),
-			fmt.Sprintf("%t", page.Success),
-			page.Error,
-		}
-		if err := writer.Write(row); err != nil {
+	for _, page := range pages {
+		if err := writer.Write(r.pageRow(page)); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	writer.Flush()
+	return writer.Error()
 }
 
 // ExportLinksCSV exports all links found to a separate CSV file
@@ -181,37 +404,5 @@ func (r *Results) ExportLinksCSV(filename string) error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
-	header := []string{"Source URL", "Found Link", "Link Depth"}
-	if err := writer.Write(header); err != nil {
-		return err
-	}
-
-	// Write rows - one row per link found
-	for _, page := range r.pages {
-		if !page.Success {
-			continue
-		}
-		for _, link := range page.Links {
-			row := []string{
-				page.URL,
-				link,
-				"", // Depth could be calculated if needed
-			}
-			if err := writer.Write(row); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	return WriteFile(filename, r.writeLinksCSV)
 }
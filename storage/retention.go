@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Retention bounds what long-lived stores keep, zero values keep everything
+type Retention struct {
+	Runs   int           // most recent runs kept
+	MaxAge time.Duration // runs finished longer ago are dropped
+}
+
+// NewRetention builds a retention from run and day counts
+func NewRetention(runs, days int) (Retention, error) {
+	if runs < 0 || days < 0 {
+		return Retention{}, fmt.Errorf("retention counts must not be negative")
+	}
+	return Retention{Runs: runs, MaxAge: time.Duration(days) * 24 * time.Hour}, nil
+}
+
+// IsZero reports whether the retention keeps everything
+func (r Retention) IsZero() bool {
+	return r.Runs == 0 && r.MaxAge == 0
+}
+
+// Keeps reports whether a run finished at at is retained at now, rank
+// being its position from the most recent run (0)
+func (r Retention) Keeps(rank int, at, now time.Time) bool {
+	if r.Runs > 0 && rank >= r.Runs {
+		return false
+	}
+	return r.MaxAge == 0 || now.Sub(at) <= r.MaxAge
+}
+
+// RemoveExports deletes the export files of a pruned run, the files
+// named <prefix>_* with prefix its directory and -name. Files of the
+// kept runs stay: all of them when a kept run has the same prefix (a
+// fixed -name overwritten by every run), those matching a longer one.
+func RemoveExports(prefix string, kept []string) error {
+	prefix = filepath.Clean(prefix)
+	for _, k := range kept {
+		if filepath.Clean(k) == prefix {
+			return nil
+		}
+	}
+
+	dir, name := filepath.Split(prefix)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+entries:
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), name+"_") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		for _, k := range kept {
+			if k = filepath.Clean(k); len(k) > len(prefix) && strings.HasPrefix(path, k+"_") {
+				continue entries
+			}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r Retention) String() string {
+	switch {
+	case r.IsZero():
+		return "everything"
+	case r.MaxAge == 0:
+		return fmt.Sprintf("last %d runs", r.Runs)
+	case r.Runs == 0:
+		return fmt.Sprintf("runs of the last %d days", int(r.MaxAge.Hours()/24))
+	default:
+		return fmt.Sprintf("last %d runs of the last %d days", r.Runs, int(r.MaxAge.Hours()/24))
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// FailedPages returns the pages of a results.json that failed and the
+// URL its crawl started from
+func FailedPages(filename string) (failed []*Page, startURL string, err error) {
+	pages, err := readPages(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, page := range pages {
+		if !page.Success {
+			failed = append(failed, page)
+		}
+		if page.Depth == 0 && page.Parent == "" && startURL == "" {
+			startURL = page.URL
+		}
+	}
+	return failed, startURL, nil
+}
+
+// MergePages rewrites a results.json with fresh versions of some of its
+// pages, each replacing the stored page of the same URL in place
+func MergePages(filename string, fresh []*Page) error {
+	pages, err := readPages(filename)
+	if err != nil {
+		return err
+	}
+	byURL := make(map[string]*Page, len(fresh))
+	for _, page := range fresh {
+		byURL[page.URL] = page
+	}
+	for i, page := range pages {
+		if f, ok := byURL[page.URL]; ok {
+			pages[i] = f
+		}
+	}
+
+	return WriteFile(filename, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(pages)
+	})
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"io"
+	"sort"
+)
+
+// RuleViolation is a page failing one check of a page rule
+type RuleViolation struct {
+	URL      string `json:"url"`
+	Rule     string `json:"rule"`
+	Check    string `json:"check"` // status, title, response_time or header
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// AddViolation records a rule violation (thread-safe)
+func (r *Results) AddViolation(v *RuleViolation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.violations = append(r.violations, v)
+}
+
+// Violations returns the rule violations sorted by rule then URL
+func (r *Results) Violations() []RuleViolation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	violations := make([]RuleViolation, len(r.violations))
+	for i, v := range r.violations {
+		violations[i] = *v
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Rule != violations[j].Rule {
+			return violations[i].Rule < violations[j].Rule
+		}
+		return violations[i].URL < violations[j].URL
+	})
+	return violations
+}
+
+// ExportRulesCSV exports the rule violations
+func (r *Results) ExportRulesCSV(filename string) error {
+	violations := r.Violations()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "rules"); err != nil {
+			return err
+		}
+
+		header := []string{"Rule", "URL", "Check", "Expected", "Actual"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, v := range violations {
+			if err := writer.Write([]string{v.Rule, v.URL, v.Check, v.Expected, v.Actual}); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
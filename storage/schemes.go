@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"io"
+	"net/url"
+	"sort"
+)
+
+// HTTPLink is a link to the plain http version of a URL on a host that
+// was crawled over https
+type HTTPLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	HTTPS  string `json:"https"` // the same URL over https
+}
+
+// HTTPLinks reports links to http URLs whose host serves crawled https
+// pages, sorted by source then target
+func (r *Results) HTTPLinks() []HTTPLink {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secureHosts := make(map[string]bool)
+	for _, page := range r.pages {
+		if u, err := url.Parse(page.URL); err == nil && u.Scheme == "https" && page.Success {
+			secureHosts[u.Hostname()] = true
+		}
+	}
+
+	links := make([]HTTPLink, 0)
+	seen := make(map[[2]string]bool)
+	for _, page := range r.pages {
+		for _, e := range r.links.outgoing(page.URL) {
+			u, err := url.Parse(e.Target)
+			if err != nil || u.Scheme != "http" || !secureHosts[u.Hostname()] {
+				continue
+			}
+			if k := [2]string{e.Source, e.Target}; !seen[k] {
+				seen[k] = true
+				u.Scheme = "https"
+				if u.Port() == "80" {
+					u.Host = u.Hostname()
+				}
+				links = append(links, HTTPLink{Source: e.Source, Target: e.Target, HTTPS: u.String()})
+			}
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Source != links[j].Source {
+			return links[i].Source < links[j].Source
+		}
+		return links[i].Target < links[j].Target
+	})
+	return links
+}
+
+// ExportHTTPLinksCSV exports the links to http versions of https URLs
+func (r *Results) ExportHTTPLinksCSV(filename string) error {
+	links := r.HTTPLinks()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "http_links"); err != nil {
+			return err
+		}
+
+		header := []string{"Source URL", "HTTP Link", "HTTPS URL"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, l := range links {
+			if err := writer.Write([]string{l.Source, l.Target, l.HTTPS}); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LoadSeedURLs reads the page URLs of an earlier results.json or
+// results.csv export, compressed or not, in file order
+func LoadSeedURLs(filename string) ([]string, error) {
+	plain := strings.TrimSuffix(strings.TrimSuffix(filename, ".gz"), ".zst")
+	switch {
+	case strings.HasSuffix(plain, ".json"):
+		pages, err := readPages(filename)
+		if err != nil {
+			return nil, err
+		}
+		urls := make([]string, len(pages))
+		for i, page := range pages {
+			urls[i] = page.URL
+		}
+		return urls, nil
+	case strings.HasSuffix(plain, ".csv"):
+		var urls []string
+		err := readFile(filename, func(r io.Reader) error {
+			reader := newCSVReader(r)
+			reader.FieldsPerRecord = -1
+			column := -1
+			for {
+				row, err := reader.Read()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if len(row) > 0 && row[0] == "#schema" {
+					continue
+				}
+				if column < 0 {
+					// the header row names the URL column
+					for i, name := range row {
+						if name == "URL" {
+							column = i
+						}
+					}
+					if column < 0 {
+						return fmt.Errorf("no URL column in %s", filename)
+					}
+					continue
+				}
+				if column < len(row) && row[column] != "" {
+					urls = append(urls, row[column])
+				}
+			}
+		})
+		return urls, err
+	}
+	return nil, fmt.Errorf("%s is not a results .json or .csv export", filename)
+}
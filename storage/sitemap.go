@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Sitemap gap kinds
+const (
+	GapOrphan       = "orphan"         // in the sitemap but never linked internally
+	GapNotInSitemap = "not_in_sitemap" // crawled but missing from the sitemap
+)
+
+// SitemapGap is a URL found on only one side of the sitemap/crawl comparison
+type SitemapGap struct {
+	URL     string `json:"url"`
+	Gap     string `json:"gap"`
+	Crawled bool   `json:"crawled"`
+}
+
+// SetSitemap stores the URLs listed in the site's sitemap
+func (r *Results) SetSitemap(urls []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sitemap = urls
+}
+
+// SitemapGaps compares the sitemap with the crawl: sitemap URLs no crawled
+// page links to, and indexable crawled pages the sitemap doesn't list
+func (r *Results) SitemapGaps() []SitemapGap {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	gaps := make([]SitemapGap, 0)
+	if r.sitemap == nil {
+		return gaps
+	}
+
+	crawled := make(map[string]*Page, len(r.pages))
+	for _, page := range r.pages {
+		crawled[page.URL] = page
+	}
+	inSitemap := make(map[string]bool, len(r.sitemap))
+	for _, u := range r.sitemap {
+		if inSitemap[u] {
+			continue
+		}
+		inSitemap[u] = true
+		if !r.links.linked(u) {
+			gaps = append(gaps, SitemapGap{URL: u, Gap: GapOrphan, Crawled: crawled[u] != nil})
+		}
+	}
+	if r.export.Order == OrderURL {
+		sort.Slice(gaps, func(i, j int) bool { return gaps[i].URL < gaps[j].URL })
+	}
+	for _, page := range r.orderedPages() {
+		if page.Success && !page.NoIndex && page.AlternateOf == "" && !inSitemap[page.URL] {
+			gaps = append(gaps, SitemapGap{URL: page.URL, Gap: GapNotInSitemap, Crawled: true})
+		}
+	}
+
+	return gaps
+}
+
+// ExportSitemapGapsCSV exports the sitemap/crawl comparison
+func (r *Results) ExportSitemapGapsCSV(filename string) error {
+	gaps := r.SitemapGaps()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "sitemap_gaps"); err != nil {
+			return err
+		}
+
+		header := []string{"URL", "Gap", "Crawled"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, gap := range gaps {
+			row := []string{gap.URL, gap.Gap, fmt.Sprintf("%t", gap.Crawled)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
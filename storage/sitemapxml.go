@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// MaxSitemapURLs is the limit of URLs in one sitemap file
+const MaxSitemapURLs = 50000
+
+// IndexableURLs lists the crawled pages search engines may index: loaded
+// successfully without redirecting, not excluded by robots directives,
+// not an AMP/mobile alternate and canonical to themselves
+func (r *Results) IndexableURLs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	urls := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, page := range r.orderedPages() {
+		if !page.Success || page.NoIndex || page.AlternateOf != "" || len(page.Redirects) > 0 || page.MetaRefresh != "" {
+			continue
+		}
+		if page.Canonical != "" && page.Canonical != page.URL {
+			continue
+		}
+		if !seen[page.URL] {
+			seen[page.URL] = true
+			urls = append(urls, page.URL)
+		}
+	}
+	return urls
+}
+
+// sitemapURLSet is the <urlset> document of the sitemaps protocol
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapLoc `xml:"url"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// ExportSitemapXML writes a sitemap of the indexable pages, the first
+// MaxSitemapURLs of them, returning how many URLs it lists
+func (r *Results) ExportSitemapXML(filename string) (int, error) {
+	urls := r.IndexableURLs()
+	if len(urls) > MaxSitemapURLs {
+		urls = urls[:MaxSitemapURLs]
+	}
+
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, u := range urls {
+		set.URLs = append(set.URLs, sitemapLoc{Loc: u})
+	}
+	err := WriteFile(filename, func(w io.Writer) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		encoder := xml.NewEncoder(w)
+		encoder.Indent("", "  ")
+		if err := encoder.Encode(set); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	})
+	return len(urls), err
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"io"
+	"sort"
+)
+
+// Skip reasons
+const (
+	SkipURLTooLong    = "url_too_long"
+	SkipTooManyParams = "too_many_params"
+	SkipLanguage      = "language" // language path segment outside -languages
+)
+
+// Skipped is an in-scope URL the crawler refused to queue
+type Skipped struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+	Source string `json:"source"` // page the URL was first found on
+}
+
+// AddSkipped records a skipped URL once, keeping the first source (thread-safe)
+func (r *Results) AddSkipped(skipped *Skipped) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.skipped[skipped.URL]; !ok {
+		r.skipped[skipped.URL] = skipped
+	}
+}
+
+// SkippedURLs returns the skipped URLs sorted by reason then URL
+func (r *Results) SkippedURLs() []Skipped {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	skipped := make([]Skipped, 0, len(r.skipped))
+	for _, s := range r.skipped {
+		skipped = append(skipped, *s)
+	}
+	sort.Slice(skipped, func(i, j int) bool {
+		if skipped[i].Reason != skipped[j].Reason {
+			return skipped[i].Reason < skipped[j].Reason
+		}
+		return skipped[i].URL < skipped[j].URL
+	})
+	return skipped
+}
+
+// ExportSkippedCSV exports the URLs skipped by the crawl guards
+func (r *Results) ExportSkippedCSV(filename string) error {
+	skipped := r.SkippedURLs()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "skipped"); err != nil {
+			return err
+		}
+
+		header := []string{"URL", "Reason", "Found On"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, s := range skipped {
+			if err := writer.Write([]string{s.URL, s.Reason, s.Source}); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot is the visible text of a page as seen by one run
+type Snapshot struct {
+	Run   time.Time `json:"run"`
+	Hash  string    `json:"hash"` // sha256 of the text, names the file holding it
+	Lines int       `json:"lines"`
+}
+
+// TextChange is a page whose text differs from its previous snapshot
+type TextChange struct {
+	URL      string    `json:"url"`
+	Previous time.Time `json:"previous_run"`
+	Added    int       `json:"lines_added"`
+	Removed  int       `json:"lines_removed"`
+}
+
+// SnapshotStore keeps the text of pages across runs in a directory:
+// index.json lists the snapshots of every URL, each distinct text is
+// stored once as texts/<hash>.txt (thread-safe)
+type SnapshotStore struct {
+	dir       string
+	retention int       // snapshots kept per URL, 0 keeps all
+	run       time.Time // run the saved snapshots belong to
+	index     map[string][]Snapshot
+	added     []string // URLs first seen by this run
+	mu        sync.RWMutex
+}
+
+// OpenSnapshots opens or creates a snapshot directory for a run
+func OpenSnapshots(dir string, retention int, run time.Time) (*SnapshotStore, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "texts"), 0755); err != nil {
+		return nil, err
+	}
+
+	s := &SnapshotStore{
+		dir:       dir,
+		retention: retention,
+		run:       run,
+		index:     make(map[string][]Snapshot),
+	}
+	data, err := os.ReadFile(s.indexPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.index); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.indexPath(), err)
+	}
+	return s, nil
+}
+
+func (s *SnapshotStore) indexPath() string {
+	return filepath.Join(s.dir, "index.json")
+}
+
+func (s *SnapshotStore) textPath(hash string) string {
+	return filepath.Join(s.dir, "texts", hash+".txt")
+}
+
+// Save records the text of url for this run, returning how it changed
+// since the previous snapshot (nil for new or unchanged pages)
+func (s *SnapshotStore) Save(url, text string) (*TextChange, error) {
+	sum := sha256.Sum256([]byte(text))
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots := s.index[url]
+	var prev *Snapshot
+	if len(snapshots) > 0 {
+		prev = &snapshots[len(snapshots)-1]
+		if prev.Run.Equal(s.run) {
+			return nil, nil // already saved by this run
+		}
+	} else {
+		s.added = append(s.added, url)
+	}
+
+	if _, err := os.Stat(s.textPath(hash)); errors.Is(err, fs.ErrNotExist) {
+		if err := os.WriteFile(s.textPath(hash), []byte(text), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	var change *TextChange
+	if prev != nil && prev.Hash != hash {
+		old, err := os.ReadFile(s.textPath(prev.Hash))
+		if err != nil {
+			return nil, err
+		}
+		added, removed := diffStats(diffLines(splitLines(string(old)), splitLines(text)))
+		change = &TextChange{URL: url, Previous: prev.Run, Added: added, Removed: removed}
+	}
+
+	snapshots = append(snapshots, Snapshot{Run: s.run, Hash: hash, Lines: len(splitLines(text))})
+	if s.retention > 0 && len(snapshots) > s.retention {
+		snapshots = snapshots[len(snapshots)-s.retention:]
+	}
+	s.index[url] = snapshots
+	return change, nil
+}
+
+// PruneBefore drops the snapshots taken by runs before t, returning how
+// many were dropped. Their texts are deleted by Close.
+func (s *SnapshotStore) PruneBefore(t time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pruned := 0
+	for url, snapshots := range s.index {
+		kept := make([]Snapshot, 0, len(snapshots))
+		for _, snap := range snapshots {
+			if snap.Run.Before(t) {
+				pruned++
+				continue
+			}
+			kept = append(kept, snap)
+		}
+		if len(kept) == 0 {
+			delete(s.index, url)
+			continue
+		}
+		s.index[url] = kept
+	}
+	return pruned
+}
+
+// Close writes the index and deletes texts no snapshot refers to anymore
+func (s *SnapshotStore) Close() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.indexPath()); err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	for _, snapshots := range s.index {
+		for _, snap := range snapshots {
+			used[snap.Hash+".txt"] = true
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(s.dir, "texts"))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !used[entry.Name()] {
+			os.Remove(filepath.Join(s.dir, "texts", entry.Name()))
+		}
+	}
+	return nil
+}
+
+// Added returns the URLs this run snapshotted for the first time
+func (s *SnapshotStore) Added() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.added...)
+}
+
+// History returns the snapshots kept for url, oldest first
+func (s *SnapshotStore) History(url string) []Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Snapshot(nil), s.index[url]...)
+}
+
+// Diff returns the unified diff between two snapshots of url, picked by
+// position in its history; negative positions count from the end, so
+// -2 and -1 compare the last two runs
+func (s *SnapshotStore) Diff(url string, from, to int) (string, error) {
+	history := s.History(url)
+	pick := func(i int) (Snapshot, error) {
+		if i < 0 {
+			i += len(history)
+		}
+		if i < 0 || i >= len(history) {
+			return Snapshot{}, fmt.Errorf("no snapshot %d of %s (%d kept)", i, url, len(history))
+		}
+		return history[i], nil
+	}
+
+	a, err := pick(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := pick(to)
+	if err != nil {
+		return "", err
+	}
+	oldText, err := os.ReadFile(s.textPath(a.Hash))
+	if err != nil {
+		return "", err
+	}
+	newText, err := os.ReadFile(s.textPath(b.Hash))
+	if err != nil {
+		return "", err
+	}
+
+	header := fmt.Sprintf("--- %s\t%s\n+++ %s\t%s\n", url, a.Run.Format(time.RFC3339), url, b.Run.Format(time.RFC3339))
+	return header + unified(diffLines(splitLines(string(oldText)), splitLines(string(newText))), 3), nil
+}
+
+// splitLines splits text into lines, empty text has none
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// AddTextChange records a page whose text changed since the last run (thread-safe)
+func (r *Results) AddTextChange(change *TextChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.textChanges = append(r.textChanges, *change)
+}
+
+// TextChanges returns the pages whose text changed, most changed first
+func (r *Results) TextChanges() []TextChange {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := append([]TextChange(nil), r.textChanges...)
+	sort.Slice(changes, func(i, j int) bool {
+		ci, cj := changes[i].Added+changes[i].Removed, changes[j].Added+changes[j].Removed
+		if ci != cj {
+			return ci > cj
+		}
+		return changes[i].URL < changes[j].URL
+	})
+	return changes
+}
+
+// ExportTextChangesCSV exports the pages whose text changed since the last run
+func (r *Results) ExportTextChangesCSV(filename string) error {
+	changes := r.TextChanges()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "text_changes"); err != nil {
+			return err
+		}
+
+		header := []string{"URL", "Previous Run", "Lines Added", "Lines Removed"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, c := range changes {
+			row := []string{c.URL, c.Previous.Format(time.RFC3339), fmt.Sprintf("%d", c.Added), fmt.Sprintf("%d", c.Removed)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
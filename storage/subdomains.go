@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Subdomain sources
+const (
+	SubdomainCertificate = "certificate" // TLS certificate SAN
+	SubdomainLink        = "link"        // linked or embedded from a crawled page
+)
+
+// Subdomain is a host under a start URL's registrable domain that could
+// seed another crawl
+type Subdomain struct {
+	Host    string `json:"host"`
+	Source  string `json:"source"`
+	FoundOn string `json:"found_on"` // page linking to it, or host serving the certificate
+	Crawled bool   `json:"crawled"`  // pages of this host are already in the results
+	Seed    string `json:"seed"`
+}
+
+// AddCertNames records the DNS names of a host's TLS certificate (thread-safe)
+func (r *Results) AddCertNames(host string, names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certNames[host] = names
+}
+
+// startDomains returns the registrable domains of the start URLs and
+// their schemes, the caller holds the lock
+func (r *Results) startDomains() map[string]string {
+	domains := make(map[string]string)
+	for _, page := range r.pages {
+		if page.Depth != 0 {
+			continue
+		}
+		if u, err := url.Parse(page.URL); err == nil {
+			if domain, err := publicsuffix.EffectiveTLDPlusOne(u.Hostname()); err == nil {
+				domains[domain] = u.Scheme
+			}
+		}
+	}
+	return domains
+}
+
+// subdomains lists the hosts of the start domains seen in certificates
+// and links, first sighting wins; the caller holds the lock
+func (r *Results) subdomains() []Subdomain {
+	domains := r.startDomains()
+	crawled := make(map[string]bool)
+	for _, page := range r.pages {
+		if u, err := url.Parse(page.URL); err == nil {
+			crawled[strings.ToLower(u.Hostname())] = true
+		}
+	}
+
+	found := make(map[string]bool)
+	subs := make([]Subdomain, 0)
+	add := func(host, source, foundOn string) {
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+		if found[host] {
+			return
+		}
+		for domain, scheme := range domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				found[host] = true
+				subs = append(subs, Subdomain{
+					Host:    host,
+					Source:  source,
+					FoundOn: foundOn,
+					Crawled: crawled[host],
+					Seed:    scheme + "://" + host + "/",
+				})
+				return
+			}
+		}
+	}
+	addURL := func(rawURL, foundOn string) {
+		if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+			add(u.Hostname(), SubdomainLink, foundOn)
+		}
+	}
+
+	hosts := make([]string, 0, len(r.certNames))
+	for host := range r.certNames {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		for _, name := range r.certNames[host] {
+			add(strings.TrimPrefix(name, "*."), SubdomainCertificate, host)
+		}
+	}
+	for _, page := range r.orderedPages() {
+		for _, e := range r.links.outgoing(page.URL) {
+			addURL(e.Target, page.URL)
+		}
+		for _, e := range page.Embeds {
+			addURL(e.URL, page.URL)
+		}
+	}
+
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].Host < subs[j].Host })
+	return subs
+}
+
+// Subdomains lists candidate seeds under the start URLs' registrable
+// domains, from TLS certificates and links
+func (r *Results) Subdomains() []Subdomain {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.subdomains()
+}
+
+// ExportSubdomainsCSV exports the candidate seeds
+func (r *Results) ExportSubdomainsCSV(filename string) error {
+	subs := r.Subdomains()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "subdomains"); err != nil {
+			return err
+		}
+
+		header := []string{"Host", "Source", "Found On", "Crawled", "Seed URL"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, s := range subs {
+			row := []string{s.Host, s.Source, s.FoundOn, fmt.Sprintf("%t", s.Crawled), s.Seed}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
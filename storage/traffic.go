@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TrafficOrphan is a URL with traffic that no crawled page links to
+type TrafficOrphan struct {
+	URL     string `json:"url"`
+	Hits    int    `json:"hits"`
+	Crawled bool   `json:"crawled"`
+}
+
+// SetTraffic stores the hits per URL imported from an access log
+func (r *Results) SetTraffic(hits map[string]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.traffic = hits
+}
+
+// TrafficOrphans lists URLs receiving traffic that are unreachable
+// through internal links, most visited first. Start URLs don't count.
+func (r *Results) TrafficOrphans() []TrafficOrphan {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	crawled := make(map[string]*Page, len(r.pages))
+	for _, page := range r.pages {
+		crawled[page.URL] = page
+	}
+
+	orphans := make([]TrafficOrphan, 0)
+	for u, hits := range r.traffic {
+		page := crawled[u]
+		if r.links.linked(u) || (page != nil && page.Depth == 0) {
+			continue
+		}
+		orphans = append(orphans, TrafficOrphan{URL: u, Hits: hits, Crawled: page != nil})
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Hits != orphans[j].Hits {
+			return orphans[i].Hits > orphans[j].Hits
+		}
+		return orphans[i].URL < orphans[j].URL
+	})
+	return orphans
+}
+
+// ExportTrafficOrphansCSV exports URLs with traffic but no internal links
+func (r *Results) ExportTrafficOrphansCSV(filename string) error {
+	orphans := r.TrafficOrphans()
+
+	r.mu.RLock()
+	cfg := r.export
+	r.mu.RUnlock()
+
+	return WriteFile(filename, func(w io.Writer) error {
+		writer := newCSVWriter(w, cfg)
+		if err := writeSchemaRow(writer, cfg, "traffic_orphans"); err != nil {
+			return err
+		}
+
+		header := []string{"URL", "Hits", "Crawled"}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, orphan := range orphans {
+			row := []string{orphan.URL, fmt.Sprintf("%d", orphan.Hits), fmt.Sprintf("%t", orphan.Crawled)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xlsxParts are the fixed parts of a single-sheet workbook, the sheet
+// itself is written by writeXLSX
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// writeXLSX writes rows as the only sheet of an Excel workbook, integers
+// become number cells and everything else inline strings
+func writeXLSX(w io.Writer, sheet string, rows [][]string) error {
+	z := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := z.Create("xl/workbook.xml")
+	if err != nil {
+		return err
+	}
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(sheet))
+	fmt.Fprintf(f, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`, name.String())
+
+	f, err = z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	io.WriteString(f, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(f, `<row r="%d">`, i+1)
+		for j, value := range row {
+			ref := xlsxColumn(j) + strconv.Itoa(i+1)
+			if isXLSXNumber(value) {
+				fmt.Fprintf(f, `<c r="%s"><v>%s</v></c>`, ref, value)
+				continue
+			}
+			fmt.Fprintf(f, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(f, []byte(value))
+			io.WriteString(f, `</t></is></c>`)
+		}
+		io.WriteString(f, `</row>`)
+	}
+	if _, err := io.WriteString(f, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// xlsxColumn returns the letters of the 0-based column i: A, B, ..., Z, AA
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// isXLSXNumber reports whether value is an integer spreadsheets show as
+// typed, leading zeros (IDs, codes) stay strings
+func isXLSXNumber(value string) bool {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return false
+	}
+	digits := strings.TrimPrefix(value, "-")
+	return value[0] != '+' && (digits == "0" || digits[0] != '0')
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gocrawler/storage"
+)
+
+// summary is the -summary-json document describing a finished run
+type summary struct {
+	StartURL   string             `json:"start_url"`
+	Stats      storage.Stats      `json:"stats"`
+	Thresholds map[string]float64 `json:"thresholds"` // enabled limits by flag name
+	Checks     []summaryCheck     `json:"checks,omitempty"`
+	Aborted    bool               `json:"aborted"`
+	ExitCode   int                `json:"exit_code"`
+}
+
+// summaryCheck is the outcome of one -ci threshold
+type summaryCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Failure string `json:"failure,omitempty"`
+}
+
+// newSummary describes a finished run, t is nil outside -ci
+func newSummary(a audit, errorThreshold float64, t *ciThresholds) summary {
+	s := summary{
+		StartURL:   a.startURL,
+		Stats:      a.stats,
+		Thresholds: make(map[string]float64),
+		Aborted:    a.code == exitAborted,
+		ExitCode:   a.code,
+	}
+	if errorThreshold >= 0 {
+		s.Thresholds["error-threshold"] = errorThreshold
+	}
+	if t != nil {
+		for name, limit := range map[string]float64{
+			"ci-max-broken":             float64(t.maxBroken),
+			"ci-max-5xx":                t.max5xx,
+			"ci-max-missing-titles":     float64(t.maxMissingTitles),
+			"ci-max-rule-violations":    float64(t.maxViolations),
+			"ci-max-migration-failures": float64(t.maxMigration),
+		} {
+			if limit >= 0 {
+				s.Thresholds[name] = limit
+			}
+		}
+	}
+	for _, check := range a.checks {
+		s.Checks = append(s.Checks, summaryCheck{Name: check.name, Passed: check.failure == "", Failure: check.failure})
+	}
+	return s
+}
+
+// writeSummary writes s to filename, or as a single line to stdout for
+// "-" so that scripts can take the last line of the output
+func writeSummary(filename string, s summary) error {
+	if filename == "-" {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
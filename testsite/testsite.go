@@ -0,0 +1,98 @@
+// Package testsite serves a synthetic website for benchmarking the
+// crawler without hitting real sites.
+package testsite
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Config shapes the generated site
+type Config struct {
+	Pages   int           // number of pages, /page/0 to /page/<Pages-1>
+	Links   int           // links per page
+	Latency time.Duration // added to every response
+}
+
+// Site is a running synthetic site
+type Site struct {
+	URL      string // of the home page
+	cfg      Config
+	server   *http.Server
+	requests atomic.Int64
+}
+
+// Start serves the site on a free loopback port
+func Start(cfg Config) (*Site, error) {
+	if cfg.Pages < 1 {
+		return nil, fmt.Errorf("a test site needs at least one page")
+	}
+	cfg.Links = max(cfg.Links, 1)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Site{URL: fmt.Sprintf("http://%s/page/0", listener.Addr()), cfg: cfg}
+	s.server = &http.Server{Handler: s}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// Requests returns the number of requests served so far
+func (s *Site) Requests() int64 {
+	return s.requests.Load()
+}
+
+// Close stops the server
+func (s *Site) Close() error {
+	return s.server.Close()
+}
+
+// Depth is how many link hops from the home page reach every page
+func (s *Site) Depth() int {
+	depth, last := 0, 0 // last is the highest page at depth
+	for last < s.cfg.Pages-1 {
+		last = last*s.cfg.Links + s.cfg.Links
+		depth++
+	}
+	return depth
+}
+
+// ServeHTTP serves /page/<n>. Pages form a tree, page n linking to
+// pages n*links+1 to n*links+links, plus a few deterministic cross
+// links and a link home so the crawler also meets known URLs.
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	if s.cfg.Latency > 0 {
+		time.Sleep(s.cfg.Latency)
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/page/"))
+	if err != nil || n < 0 || n >= s.cfg.Pages || !strings.HasPrefix(r.URL.Path, "/page/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "<!DOCTYPE html><html><head><title>Page %d</title>", n)
+	fmt.Fprintf(&body, `<meta name="description" content="Synthetic page %d of %d"></head><body>`, n, s.cfg.Pages)
+	fmt.Fprintf(&body, "<h1>Page %d</h1><p>This page is generated for benchmarking. It has %d links.</p><ul>", n, s.cfg.Links)
+	for i := 1; i <= s.cfg.Links; i++ {
+		child := n*s.cfg.Links + i
+		if child >= s.cfg.Pages {
+			// leaves link across the site instead
+			child = (n*7919 + i*104729) % s.cfg.Pages
+		}
+		fmt.Fprintf(&body, `<li><a href="/page/%d">Page %d</a></li>`, child, child)
+	}
+	body.WriteString(`</ul><a href="/page/0">Home</a></body></html>`)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(body.String()))
+}
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer returns the tracer used for crawler spans. Until Setup is called
+// it is a no-op, so instrumentation costs nothing when tracing is off.
+func Tracer() trace.Tracer {
+	return otel.Tracer("gocrawler")
+}
+
+// Setup exports spans over OTLP/HTTP to endpoint (host:port, e.g. a
+// Jaeger or Tempo collector on localhost:4318). The returned function
+// flushes pending spans and must be called before exit.
+func Setup(ctx context.Context, endpoint string, insecure bool) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName("gocrawler"),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// URL is the span attribute carrying the crawled URL
+func URL(u string) attribute.KeyValue {
+	return semconv.URLFull(u)
+}
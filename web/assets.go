@@ -0,0 +1,82 @@
+package web
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+
+	"gocrawler/report"
+)
+
+// dashboardFiles are the built-in page templates, with the stylesheets
+// and scripts served under /static/ in dashboard/static
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// builtinAssets is dashboardFiles rooted at the dashboard directory
+var builtinAssets = func() fs.FS {
+	assets, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	return assets
+}()
+
+// overlayFS serves the files of upper, falling back to lower for those
+// upper doesn't have
+type overlayFS struct {
+	upper, lower fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.upper.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.lower.Open(name)
+	}
+	return f, err
+}
+
+// parseTemplates parses the page templates of assets, replacing the
+// current ones only if all parse
+func (s *Server) parseTemplates(assets fs.FS) error {
+	pages := []struct {
+		tmpl **template.Template
+		file string
+	}{
+		{&s.template, "index.html"},
+		{&s.runsTemplate, "runs.html"},
+		{&s.profilesTemplate, "profiles.html"},
+		{&s.widgetTemplate, "widget.html"},
+		{&s.graphTemplate, "graph.html"},
+		{&s.pageListTemplate, "pagelist.html"},
+	}
+	parsed := make([]*template.Template, len(pages))
+	for i, page := range pages {
+		tmpl, err := template.New(page.file).Funcs(report.Funcs).ParseFS(assets, page.file)
+		if err != nil {
+			return err
+		}
+		parsed[i] = tmpl
+	}
+	for i, page := range pages {
+		*page.tmpl = parsed[i]
+	}
+	s.assets = assets
+	return nil
+}
+
+// SetDashboardDir serves the dashboard files found in dir instead of the
+// built-in ones: templates (index.html, pagelist.html, ...) and static/
+// files. Call it before SetBotInfo so a botinfo.html there applies too.
+func (s *Server) SetDashboardDir(dir string) error {
+	if info, err := os.Stat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return s.parseTemplates(overlayFS{upper: os.DirFS(dir), lower: builtinAssets})
+}
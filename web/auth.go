@@ -0,0 +1,110 @@
+package web
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Token roles, admin tokens can also do everything read-only tokens can
+const (
+	RoleRead  = "read"  // dashboard and GET APIs: stats, pages, exports
+	RoleAdmin = "admin" // crawl control: jobs, profiles, settings, pruning
+)
+
+// APIToken grants a role to the requests bearing it
+type APIToken struct {
+	Role  string
+	Token string
+}
+
+// ParseAPIToken parses "[role=]token", the role defaults to admin
+func ParseAPIToken(s string) (APIToken, error) {
+	role, token, ok := strings.Cut(s, "=")
+	if !ok {
+		role, token = RoleAdmin, s
+	}
+	if role != RoleRead && role != RoleAdmin {
+		return APIToken{}, fmt.Errorf("invalid role %q in %q (want read or admin)", role, s)
+	}
+	if token == "" {
+		return APIToken{}, fmt.Errorf("empty token in %q", s)
+	}
+	return APIToken{Role: role, Token: token}, nil
+}
+
+// tokenCookie keeps a ?token= for the dashboard's own requests and links
+const tokenCookie = "gocrawler_token"
+
+// SetTokens enables authentication, every request then needs a token
+// with the role of its route. No tokens leaves the server open.
+func (s *Server) SetTokens(tokens []APIToken) {
+	s.tokens = tokens
+}
+
+// requiredRole is the role a request needs: changes and crawl settings
+// need admin, reads only read
+func requiredRole(r *http.Request) string {
+	if r.URL.Path == "/api/config" {
+		return RoleAdmin
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return RoleRead
+	}
+	return RoleAdmin
+}
+
+// requestToken finds the token of a request in the Authorization header,
+// the ?token= parameter or the cookie a ?token= leaves
+func requestToken(r *http.Request) (token string, fromQuery bool) {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer "), false
+	}
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token, true
+	}
+	if c, err := r.Cookie(tokenCookie); err == nil {
+		return c.Value, false
+	}
+	return "", false
+}
+
+// role returns the role of token, or "" for unknown tokens
+func (s *Server) role(token string) string {
+	role := ""
+	for _, t := range s.tokens {
+		// constant time, and no early return, so timing doesn't leak tokens
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 && role != RoleAdmin {
+			role = t.Role
+		}
+	}
+	return role
+}
+
+// authorize checks the token of every request when tokens are set. The
+// bot info page stays public for the owners of crawled sites.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.tokens) == 0 || r.URL.Path == "/bot-info" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, fromQuery := requestToken(r)
+		role := s.role(token)
+		switch {
+		case role == "":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gocrawler"`)
+			http.Error(w, "API token required, send Authorization: Bearer <token> or ?token=", http.StatusUnauthorized)
+			return
+		case requiredRole(r) == RoleAdmin && role != RoleAdmin:
+			http.Error(w, "admin token required", http.StatusForbidden)
+			return
+		}
+		if fromQuery {
+			http.SetCookie(w, &http.Cookie{Name: tokenCookie, Value: token, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		}
+		next.ServeHTTP(w, r)
+	})
+}
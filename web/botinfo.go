@@ -0,0 +1,44 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// BotInfo describes the crawler to the owners of the sites it visits
+type BotInfo struct {
+	UserAgent string
+	Contact   string   // e-mail or URL to reach the operator, may be empty
+	Seeds     []string // start URLs of the crawl, empty in daemon mode
+	Workers   int
+	RateLimit int // requests per second
+	Started   time.Time
+}
+
+// SetBotInfo serves info on /bot-info, rendered with the template in
+// file or botinfo.html of the dashboard files when file is empty
+func (s *Server) SetBotInfo(info BotInfo, file string) error {
+	tmpl, err := template.ParseFS(s.assets, "botinfo.html")
+	if file != "" {
+		tmpl, err = template.ParseFiles(file)
+	}
+	if err != nil {
+		return err
+	}
+	s.botInfo = &info
+	s.botInfoTemplate = tmpl
+	return nil
+}
+
+// handleBotInfo serves the identification page linked from the User-Agent
+func (s *Server) handleBotInfo(w http.ResponseWriter, r *http.Request) {
+	if s.botInfo == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.botInfoTemplate.Execute(w, s.botInfo); err != nil {
+		http.Error(w, "Error rendering template", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gocrawler/crawler"
+)
+
+// SetCrawler enables the settings API for the crawler of a single crawl
+func (s *Server) SetCrawler(c *crawler.Crawler) {
+	s.crawler = c
+}
+
+// handleConfig returns the settings that can change during a crawl, a
+// POST changes them: fields missing from the JSON body keep their value
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if s.crawler == nil {
+		http.Error(w, "settings can only change during a single crawl", http.StatusServiceUnavailable)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		settings := s.crawler.Settings()
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.crawler.Apply(settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.crawler.Settings())
+}
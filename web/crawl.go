@@ -0,0 +1,86 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gocrawler/jobs"
+)
+
+// CrawlRequest is the body of POST /api/crawl, zero values use the
+// defaults. It predates the job queue and is submitted to it as a job.
+type CrawlRequest struct {
+	URL     string `json:"url"`
+	Depth   int    `json:"depth,omitempty"`
+	Workers int    `json:"workers,omitempty"`
+	Rate    int    `json:"rate,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+// CrawlStatus summarizes the job queue for GET /api/crawl
+type CrawlStatus struct {
+	Running   bool      `json:"running"`
+	URL       string    `json:"url,omitempty"` // oldest running job
+	StartedAt time.Time `json:"started_at,omitzero"`
+	Completed int       `json:"completed"`     // jobs finished, failed or not
+	Job       *jobs.Job `json:"job,omitempty"` // job created by a POST
+}
+
+// crawlStatus summarizes the jobs of the queue
+func (s *Server) crawlStatus() CrawlStatus {
+	var status CrawlStatus
+	for _, job := range s.jobs.List("") {
+		switch job.Status {
+		case jobs.Running:
+			if !status.Running || job.StartedAt.Before(status.StartedAt) {
+				status.Running, status.URL, status.StartedAt = true, job.Request.URL, job.StartedAt
+			}
+		case jobs.Done, jobs.Failed:
+			status.Completed++
+		}
+	}
+	return status
+}
+
+// handleCrawl submits a crawl to the job queue (POST) or reports the
+// running one (GET), a shim over /api/jobs kept for existing clients
+func (s *Server) handleCrawl(w http.ResponseWriter, r *http.Request) {
+	if s.jobs == nil {
+		http.Error(w, "crawl submission requires -daemon mode", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.crawlStatus())
+	case http.MethodPost:
+		var req CrawlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+			http.Error(w, "body must be JSON with at least a \"url\"", http.StatusBadRequest)
+			return
+		}
+
+		job, err := s.jobs.Submit(jobs.Request{
+			URL:     req.URL,
+			Depth:   req.Depth,
+			Workers: req.Workers,
+			Rate:    req.Rate,
+			Scope:   req.Scope,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		status := s.crawlStatus()
+		status.Job = &job
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(status)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
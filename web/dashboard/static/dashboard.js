@@ -0,0 +1,268 @@
+// setLanguage remembers the chosen language and reloads the dashboard in it
+function setLanguage(code) {
+    document.cookie = 'lang=' + encodeURIComponent(code) + '; path=/; max-age=31536000';
+    var params = new URLSearchParams(location.search);
+    params.delete('lang');
+    location.search = params.toString();
+}
+
+// toggleTheme switches between the light and dark theme and remembers the choice
+function toggleTheme() {
+    var dark = document.body.classList.toggle('dark');
+    localStorage.setItem('theme', dark ? 'dark' : 'light');
+}
+
+// renderBreakdown draws a labelled bar list from a {label: count} map
+function renderBreakdown(title, counts, color) {
+    var labels = Object.keys(counts || {});
+    if (labels.length === 0) {
+        return '';
+    }
+    var max = Math.max.apply(null, labels.map(function(l) { return counts[l]; }));
+    return '<div class="breakdown"><h3>' + title + '</h3>' + labels.sort().map(function(label) {
+        return '<div class="breakdown-row"><span>' + label + '</span>' +
+            '<div class="breakdown-bar" style="width:' + (counts[label] / max * 100) + '%; background:' + color + '"></div>' +
+            '<span>' + counts[label] + '</span></div>';
+    }).join('') + '</div>';
+}
+
+// renderDepths draws pages, errors and latency per depth level
+function renderDepths(depths) {
+    var levels = Object.keys(depths || {});
+    if (levels.length === 0) {
+        return '';
+    }
+    var max = Math.max.apply(null, levels.map(function(d) { return depths[d].Pages; }));
+    return '<div class="breakdown"><h3>🪜 ' + T.pages_by_depth + '</h3>' + levels.sort(function(a, b) { return a - b; }).map(function(d) {
+        var s = depths[d];
+        return '<div class="breakdown-row"><span>' + T.depth + ' ' + d + ' · ' + s.Errors + ' ' + T.err_short + ' · ' + Math.round(s.AvgResponseTime) + 'ms</span>' +
+            '<div class="breakdown-bar" style="width:' + (s.Pages / max * 100) + '%; background: #48bb78"></div>' +
+            '<span>' + s.Pages + '</span></div>';
+    }).join('') + '</div>';
+}
+
+// formatDuration turns nanoseconds into a short "1h 2m 3s" label
+function formatDuration(ns) {
+    var s = Math.round(ns / 1e9);
+    var h = Math.floor(s / 3600), m = Math.floor(s % 3600 / 60);
+    return (h ? h + 'h ' : '') + (h || m ? m + 'm ' : '') + (s % 60) + 's';
+}
+
+// formatBytes renders a byte count with decimal units, as metered connections are billed
+function formatBytes(n) {
+    var units = ['B', 'kB', 'MB', 'GB', 'TB'], i = 0;
+    while (n >= 1000 && i < units.length - 1) {
+        n /= 1000;
+        i++;
+    }
+    return (i ? n.toFixed(1) : n) + ' ' + units[i];
+}
+
+// Auto-refresh every 2 seconds
+function fetchStats() {
+    fetch('/api/stats' + location.search)
+        .then(res => res.json())
+        .then(data => {
+            document.getElementById('stats').innerHTML = `
+                <div class="stat-card">
+                    <div class="stat-label">${T.total_pages}</div>
+                    <div class="stat-value">${data.TotalPages || 0}</div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.unique_links}</div>
+                    <div class="stat-value">${data.UniqueLinks || 0}</div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.success_rate}</div>
+                    <div class="stat-value">${data.TotalPages ? Math.round(data.SuccessCount / data.TotalPages * 100) : 0}%</div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.avg_response}</div>
+                    <div class="stat-value">${Math.round(data.AvgResponseTime || 0)}<span style="font-size: 0.5em;">ms</span></div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.progress}</div>
+                    <div class="stat-value">${Math.round(data.Progress || 0)}%</div>
+                    <div class="stat-label">${data.Queued || 0} ${T.queued} · ${T.eta} ${formatDuration(data.ETA || 0)}</div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.successful}</div>
+                    <div class="stat-value" style="color: #48bb78;">${data.SuccessCount || 0}</div>
+                    <div class="stat-label">${data.NotModified || 0} ${T.not_modified}</div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.failed}</div>
+                    <div class="stat-value" style="color: #f56565;">${data.FailCount || 0}</div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.downloaded}</div>
+                    <div class="stat-value">${formatBytes(data.Downloaded || 0)}</div>
+                    <div class="stat-label">${data.BandwidthCost ? T.est_cost + ' ' + (data.BandwidthCost < 0.01 ? '< 0.01' : data.BandwidthCost.toFixed(2)) : ''}</div>
+                </div>
+                <div class="stat-card">
+                    <div class="stat-label">${T.noindex}</div>
+                    <div class="stat-value" style="color: #ed8936;">${data.NoIndex || 0}</div>
+                    <div class="stat-label">${data.NoFollow || 0} ${T.nofollow}</div>
+                </div>
+            `;
+            document.getElementById('breakdowns').innerHTML =
+                renderBreakdown('📶 ' + T.status_codes, data.StatusCodes, '#5a67d8') +
+                renderBreakdown('❌ ' + T.errors_by_type, data.ErrorTypes, '#f56565') +
+                renderDepths(data.Depths);
+        })
+        .catch(err => console.error('Error fetching stats:', err));
+}
+
+// downloadExport generates an export of the filtered pages
+function downloadExport(format) {
+    var params = new URLSearchParams(location.search);
+    params.set('format', format);
+    params.set('filter', document.getElementById('filter').value);
+    params.set('q', document.getElementById('search').value);
+    location.href = '/api/export?' + params.toString();
+}
+
+// fetchPages renders the filtered page list server side, from the
+// pagelist.html template
+function fetchPages() {
+    var params = new URLSearchParams(location.search);
+    params.set('filter', document.getElementById('filter').value);
+    params.set('q', document.getElementById('search').value);
+    fetch('/api/page-list?' + params.toString())
+        .then(res => res.text())
+        .then(html => {
+            document.getElementById('pages').innerHTML = html;
+        })
+        .catch(err => console.error('Error fetching pages:', err));
+}
+
+// spoofable matches the characters displayURL keeps escaped: bidi
+// controls, zero-width and other format characters, controls and spaces
+var spoofable = /[\p{Cf}\p{Cc}\p{Z}]/gu;
+
+// displayURL decodes the non-ASCII UTF-8 escapes of a URL for display,
+// as the server side displayURL does. ASCII escapes and characters that
+// could make the URL read as another one stay escaped.
+function displayURL(url) {
+    return url.replace(/(%[89A-Fa-f][0-9A-Fa-f])+/g, function(run) {
+        try {
+            return decodeURIComponent(run).replace(spoofable, encodeURIComponent);
+        } catch (e) {
+            return run;
+        }
+    });
+}
+
+// element creates a tag holding text, escaped as text
+function element(tag, text) {
+    var el = document.createElement(tag);
+    if (text !== undefined) el.textContent = text;
+    return el;
+}
+
+// table renders [name, value] rows
+function table(rows) {
+    var t = element('table');
+    rows.forEach(function(row) {
+        var tr = t.insertRow();
+        tr.insertCell().textContent = row[0];
+        tr.insertCell().textContent = row[1];
+    });
+    return t;
+}
+
+// showPage opens the drill-down of a page, ids are positions in /api/pages
+function showPage(id) {
+    fetch('/api/pages/' + id + location.search)
+        .then(res => res.json())
+        .then(page => {
+            var detail = document.getElementById('page-detail');
+            detail.innerHTML = '';
+            detail.append(element('h2', page.title || displayURL(page.url)), element('p', displayURL(page.url)));
+
+            detail.append(element('h3', T.metadata), table([
+                [T.status, page.status_code || page.error_type],
+                [T.depth, page.depth],
+                ['⏱️', page.response_time_ms / 1000000 + 'ms'],
+                [T.bytes, formatBytes(page.size_bytes)],
+                [T.language, page.language || '–'],
+                ['canonical', page.canonical || '–'],
+                ['description', page.description || '–'],
+                [T.found_on, page.parent || '–'],
+                [T.duplicate_of, page.duplicate_of || '–'],
+                [T.error, page.error || '–']
+            ]));
+
+            detail.append(element('h3', T.response_headers));
+            detail.append(table(Object.keys(page.headers).sort().map(function(name) {
+                return [name, page.headers[name].join(', ')];
+            })));
+
+            if (page.redirects && page.redirects.length) {
+                var chain = element('ol');
+                [page.url].concat(page.redirects).forEach(function(u) { chain.append(element('li', u)); });
+                detail.append(element('h3', T.redirect_chain), chain);
+            }
+
+            detail.append(element('h3', T.extracted_links + ' (' + page.outlinks.length + ')'));
+            detail.append(table(page.outlinks.map(function(e) {
+                return [e.text || '–', e.target + (e.nofollow ? ' (nofollow)' : '') + (e.count > 1 ? ' ×' + e.count : '')];
+            })));
+            detail.append(element('h3', T.inlinks + ' (' + page.inlinks.length + ')'));
+            detail.append(table(page.inlinks.map(function(e) { return [e.text || '–', e.source]; })));
+
+            detail.append(element('h3', T.body_preview));
+            if (page.has_body) {
+                var frame = element('iframe');
+                frame.setAttribute('sandbox', '');
+                frame.src = '/api/pages/' + id + '/body' + location.search;
+                detail.append(frame);
+            } else {
+                detail.append(element('p', T.no_body));
+            }
+            document.getElementById('page-modal').classList.add('open');
+            history.replaceState(null, '', '#page=' + id);
+        })
+        .catch(err => console.error('Error fetching page:', err));
+}
+
+function closePage() {
+    document.getElementById('page-modal').classList.remove('open');
+    history.replaceState(null, '', location.pathname + location.search);
+}
+
+function fetchHosts() {
+    fetch('/api/hosts' + location.search)
+        .then(res => res.json())
+        .then(hosts => {
+            document.getElementById('hosts').innerHTML = hosts.map(function(h) {
+                var row = document.createElement('tr');
+                var curve = h.latency || [];
+                var delay = curve.length ? curve[curve.length - 1].delay_ms : 0;
+                [h.host, h.pages, h.errors, h.avg_response_time_ms.toFixed(1) + 'ms', h.p95_response_time_ms.toFixed(1) + 'ms',
+                 delay ? delay + 'ms' : '–', formatBytes(h.bytes), formatBytes(h.downloaded_bytes)].forEach(function(v) {
+                    var cell = document.createElement('td');
+                    cell.textContent = v;
+                    row.appendChild(cell);
+                });
+                return row.outerHTML;
+            }).join('');
+        })
+        .catch(err => console.error('Error fetching hosts:', err));
+}
+
+// Initial fetch, #page=<id> links open a drill-down
+var linked = location.hash.match(/^#page=(\d+)$/);
+if (linked) {
+    showPage(linked[1]);
+}
+fetchStats();
+fetchHosts();
+fetchPages();
+
+// Auto-refresh every 2 seconds
+setInterval(() => {
+    fetchStats();
+    fetchHosts();
+    fetchPages();
+}, 2000);
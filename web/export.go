@@ -0,0 +1,49 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+
+	"gocrawler/storage"
+)
+
+// exportTypes are the Content-Type of each on-demand export format
+var exportTypes = map[string]string{
+	storage.FormatJSON: "application/json",
+	storage.FormatCSV:  "text/csv; charset=utf-8",
+	storage.FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// handleExport generates a page export as a download, ?format=json|csv|xlsx
+// with the dashboard's ?filter= and ?q= applied
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = storage.FormatJSON
+	}
+	format, err := storage.ParseFormat(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := storage.ParsePageFilter(q.Get("filter"), q.Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// buffered so a failed export is still reported as an error
+	var buf bytes.Buffer
+	if err := results.WriteExport(&buf, format, filter); err != nil {
+		http.Error(w, "Error generating export: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", exportTypes[format])
+	w.Header().Set("Content-Disposition", `attachment; filename="pages.`+format+`"`)
+	w.Write(buf.Bytes())
+}
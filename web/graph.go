@@ -0,0 +1,43 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// graphPageSize is how many nodes and edges /api/graph returns when no
+// limit is given
+const graphPageSize = 500
+
+// handleGraph returns one page of the link graph, nodes are paginated with
+// ?offset= and ?limit=, edges with ?edge_offset= and ?edge_limit=
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	q := r.URL.Query()
+	graph := results.Graph(queryInt(q.Get("offset"), 0), queryInt(q.Get("limit"), graphPageSize),
+		queryInt(q.Get("edge_offset"), 0), queryInt(q.Get("edge_limit"), graphPageSize))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graph)
+}
+
+// queryInt parses a non-negative query parameter, falling back to def
+func queryInt(value string, def int) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+// handleGraphPage serves the interactive link graph
+func (s *Server) handleGraphPage(w http.ResponseWriter, r *http.Request) {
+	lang := requestLanguage(r)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.graphTemplate.Execute(w, dashboardData{Lang: lang, T: translations[lang], Languages: languages()}); err != nil {
+		http.Error(w, "Error rendering template", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// translationFiles are the dashboard labels, one <language code>.json
+// file per language
+//
+//go:embed i18n/*.json
+var translationFiles embed.FS
+
+// defaultLanguage is used when no requested language is available, its
+// labels fill in those missing from other languages
+const defaultLanguage = "en"
+
+// translations maps language codes to their labels by key
+var translations = loadTranslations()
+
+func loadTranslations() map[string]map[string]string {
+	files, err := translationFiles.ReadDir("i18n")
+	if err != nil {
+		panic(err)
+	}
+	all := make(map[string]map[string]string)
+	for _, f := range files {
+		data, err := translationFiles.ReadFile("i18n/" + f.Name())
+		if err != nil {
+			panic(err)
+		}
+		labels := make(map[string]string)
+		if err := json.Unmarshal(data, &labels); err != nil {
+			panic("web/i18n/" + f.Name() + ": " + err.Error())
+		}
+		all[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = labels
+	}
+	for _, labels := range all {
+		for key, label := range all[defaultLanguage] {
+			if _, ok := labels[key]; !ok {
+				labels[key] = label
+			}
+		}
+	}
+	return all
+}
+
+// Language is a language the dashboard can be shown in
+type Language struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// languages lists the available languages by code
+func languages() []Language {
+	langs := make([]Language, 0, len(translations))
+	for code, labels := range translations {
+		langs = append(langs, Language{Code: code, Name: labels["language_name"]})
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i].Code < langs[j].Code })
+	return langs
+}
+
+// requestLanguage picks the language of a request from ?lang=, the lang
+// cookie set by the language selector, then Accept-Language
+func requestLanguage(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); translations[lang] != nil {
+		return lang
+	}
+	if c, err := r.Cookie("lang"); err == nil && translations[c.Value] != nil {
+		return c.Value
+	}
+	// browsers list languages by preference, weights can be ignored
+	for _, tag := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ = strings.Cut(strings.TrimSpace(tag), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if translations[primary] != nil {
+			return primary
+		}
+	}
+	return defaultLanguage
+}
+
+// handleI18n returns the labels of the request's language and the
+// available languages, for API clients rendering their own views
+func (s *Server) handleI18n(w http.ResponseWriter, r *http.Request) {
+	lang := requestLanguage(r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"language":  lang,
+		"labels":    translations[lang],
+		"languages": languages(),
+	})
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gocrawler/jobs"
+	"gocrawler/storage"
+)
+
+// SetJobs enables the job queue API (daemon mode)
+func (s *Server) SetJobs(m *jobs.Manager) {
+	s.jobs = m
+}
+
+// resultsFor picks the results namespace of a request: ?job=ID or the
+// most recent job in daemon mode, the crawl's results otherwise. It
+// writes a 404 and returns nil for unknown jobs.
+func (s *Server) resultsFor(w http.ResponseWriter, r *http.Request) *storage.Results {
+	if s.jobs == nil {
+		return s.results
+	}
+
+	if id := r.URL.Query().Get("job"); id != "" {
+		results, ok := s.jobs.Results(id)
+		if !ok {
+			http.Error(w, "no results for job "+id, http.StatusNotFound)
+			return nil
+		}
+		return results
+	}
+
+	if latest := s.jobs.Latest(); latest != nil {
+		return latest
+	}
+	return s.results
+}
+
+// handleJobs lists jobs (GET, ?tenant=) or submits one (POST)
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if s.jobs == nil {
+		http.Error(w, "job queue requires -daemon mode", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.jobs.List(r.URL.Query().Get("tenant")))
+	case http.MethodPost:
+		var req jobs.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "body must be a JSON crawl request", http.StatusBadRequest)
+			return
+		}
+
+		job, err := s.jobs.Submit(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(job)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleJob returns (GET) or cancels (DELETE) a single job
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	if s.jobs == nil {
+		http.Error(w, "job queue requires -daemon mode", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := r.PathValue("id")
+	switch r.Method {
+	case http.MethodGet:
+		job, ok := s.jobs.Get(id)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	case http.MethodDelete:
+		if err := s.jobs.Cancel(id); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"net/http"
+
+	"gocrawler/storage"
+)
+
+// pageListData is what the page list template (pagelist.html, replaceable
+// with -dashboard-dir) is executed with. Besides the exported fields of
+// storage.Page (.URL, .Title, .StatusCode, .Success, .Error, .ErrorType,
+// .ResponseTime, .Links, .CrawledAt, ...) items have the .ID opening the
+// page drill-down and the .Path of URLs that led to the page. The
+// functions of the report package (ms, bytes, percent, join) are available.
+type pageListData struct {
+	Pages []pageListItem // pages passing the filter, in crawl order
+	Total int            // pages crawled, filtered or not
+	Lang  string
+	T     map[string]string
+}
+
+// pageListItem is one page of the list
+type pageListItem struct {
+	ID int
+	*storage.Page
+	Path []string // discovery path from the seed to the page
+}
+
+// handlePageList renders the dashboard page list, with the ?filter= and
+// ?q= of /api/export and ?job= applied
+func (s *Server) handlePageList(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	filter, err := storage.ParsePageFilter(r.URL.Query().Get("filter"), r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lang := requestLanguage(r)
+	data := pageListData{Lang: lang, T: translations[lang]}
+	pages := results.GetPages()
+	paths := results.DiscoveryPaths()
+	data.Total = len(pages)
+	for id, page := range pages {
+		if !filter.Match(page) {
+			continue
+		}
+		item := pageListItem{ID: id, Page: page}
+		if id < len(paths) {
+			item.Path = paths[id]
+		}
+		data.Pages = append(data.Pages, item)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.pageListTemplate.Execute(w, data); err != nil {
+		http.Error(w, "Error rendering template", http.StatusInternalServerError)
+	}
+}
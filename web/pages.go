@@ -0,0 +1,95 @@
+package web
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gocrawler/storage"
+)
+
+// pageDetail is a page with everything the dashboard drill-down shows
+type pageDetail struct {
+	ID int `json:"id"`
+	*storage.Page
+	Headers  http.Header    `json:"headers"`
+	Outlinks []storage.Edge `json:"outlinks"`
+	Inlinks  []storage.Edge `json:"inlinks"`
+	HasBody  bool           `json:"has_body"` // kept with -store-bodies, served by /api/pages/{id}/body
+}
+
+// pageFor looks up the page of the {id} path value, writing a 404 when
+// there is none
+func (s *Server) pageFor(w http.ResponseWriter, r *http.Request) (int, *storage.Page, *storage.Results) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return 0, nil, nil
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "page id must be a number", http.StatusBadRequest)
+		return 0, nil, nil
+	}
+	page, ok := results.PageByID(id)
+	if !ok {
+		http.NotFound(w, r)
+		return 0, nil, nil
+	}
+	return id, page, results
+}
+
+// handlePage returns the metadata, response headers, redirect chain and
+// links of one page, ids are positions in /api/pages
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	id, page, results := s.pageFor(w, r)
+	if page == nil {
+		return
+	}
+	detail := pageDetail{
+		ID:       id,
+		Page:     page,
+		Headers:  page.Headers,
+		Outlinks: results.Outlinks(page.URL),
+		Inlinks:  results.Inlinks(page.URL),
+		HasBody:  page.Body != "",
+	}
+	if detail.Headers == nil {
+		detail.Headers = http.Header{}
+	}
+	if detail.Outlinks == nil {
+		detail.Outlinks = []storage.Edge{}
+	}
+	if detail.Inlinks == nil {
+		detail.Inlinks = []storage.Edge{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
+
+// handlePageBody serves the captured HTML of a page for the preview
+// iframe. The sandbox policy keeps its scripts from running on the
+// dashboard's origin, a <base> makes its relative URLs resolve.
+func (s *Server) handlePageBody(w http.ResponseWriter, r *http.Request) {
+	_, page, _ := s.pageFor(w, r)
+	if page == nil {
+		return
+	}
+	if page.Body == "" {
+		http.Error(w, "body not stored, crawl with -store-bodies", http.StatusNotFound)
+		return
+	}
+
+	base := `<base href="` + template.HTMLEscapeString(page.URL) + `">`
+	body := base + page.Body
+	if i := strings.Index(strings.ToLower(page.Body), "<head>"); i >= 0 {
+		i += len("<head>")
+		body = page.Body[:i] + base + page.Body[i:]
+	}
+	// no charset, the page's own <meta charset> applies
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write([]byte(body))
+}
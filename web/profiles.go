@@ -0,0 +1,109 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gocrawler/jobs"
+)
+
+// SetProfiles enables the saved crawl profiles API (daemon mode)
+func (s *Server) SetProfiles(p *jobs.Profiles) {
+	s.profiles = p
+}
+
+// handleProfilesPage serves the page listing and launching profiles
+func (s *Server) handleProfilesPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.profilesTemplate.Execute(w, nil); err != nil {
+		http.Error(w, "Error rendering template", http.StatusInternalServerError)
+	}
+}
+
+// handleProfiles lists profiles (GET) or saves one (POST), the body is
+// {"name": "...", "request": {crawl request}}
+func (s *Server) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	if s.profiles == nil {
+		http.Error(w, "crawl profiles require -daemon mode", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.profiles.List())
+	case http.MethodPost:
+		var p jobs.Profile
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, "body must be a JSON profile", http.StatusBadRequest)
+			return
+		}
+		saved, err := s.profiles.Save(p.Name, p.Request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(saved)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleProfile returns (GET) or deletes (DELETE) a single profile
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	if s.profiles == nil {
+		http.Error(w, "crawl profiles require -daemon mode", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := r.PathValue("name")
+	switch r.Method {
+	case http.MethodGet:
+		profile, ok := s.profiles.Get(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profile)
+	case http.MethodDelete:
+		if err := s.profiles.Delete(name); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleProfileRun queues a crawl of a saved profile (POST)
+func (s *Server) handleProfileRun(w http.ResponseWriter, r *http.Request) {
+	if s.profiles == nil || s.jobs == nil {
+		http.Error(w, "crawl profiles require -daemon mode", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	profile, ok := s.profiles.Get(r.PathValue("name"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	job, err := s.jobs.Submit(profile.Request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(job)
+}
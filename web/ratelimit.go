@@ -0,0 +1,103 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIRate is how many API requests per second a client may send,
+// a dashboard tab needs about two
+const DefaultAPIRate = 20
+
+// apiLimiter is a token bucket per client address, buckets hold two
+// seconds of requests so page loads can burst (thread-safe)
+type apiLimiter struct {
+	rate      float64 // tokens per second
+	burst     float64
+	clients   map[string]*bucket
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newAPILimiter(rate int) *apiLimiter {
+	return &apiLimiter{
+		rate:    float64(rate),
+		burst:   float64(2 * rate),
+		clients: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from the bucket of client, or returns how long
+// until the next one
+func (l *apiLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep forgets clients whose bucket refilled, at most once a minute so
+// the map stays bounded by recent clients. Callers hold l.mu.
+func (l *apiLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for client, b := range l.clients {
+		if now.Sub(b.last) >= refill {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// SetAPIRate limits each client to rate API requests per second, 0
+// disables the limit
+func (s *Server) SetAPIRate(rate int) {
+	s.limiter = nil
+	if rate > 0 {
+		s.limiter = newAPILimiter(rate)
+	}
+}
+
+// limit answers 429 to clients sending API requests faster than the
+// limiter allows, pages and assets aren't limited
+func (s *Server) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.limiter == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+		if ok, wait := s.limiter.allow(client, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait/time.Second)+1))
+			http.Error(w, "too many API requests, slow down", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
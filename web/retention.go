@@ -0,0 +1,62 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gocrawler/storage"
+)
+
+// SetRetention sets the default retention of POST /api/prune
+func (s *Server) SetRetention(retention storage.Retention) {
+	s.retention = retention
+}
+
+// handlePrune drops the runs, finished jobs and snapshots out of
+// retention (POST). ?runs= and ?days= override -retain-runs and
+// -retain-days.
+func (s *Server) handlePrune(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	retention, err := storage.NewRetention(
+		queryInt(q.Get("runs"), s.retention.Runs),
+		queryInt(q.Get("days"), int(s.retention.MaxAge/(24*time.Hour))))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if retention.IsZero() {
+		http.Error(w, "no retention to apply, start with -retain-runs or -retain-days or pass ?runs= or ?days=", http.StatusBadRequest)
+		return
+	}
+
+	pruned := struct {
+		Retention string `json:"retention"`
+		Runs      int    `json:"runs"`
+		Jobs      int    `json:"jobs"`
+		Snapshots int    `json:"snapshots"`
+	}{Retention: retention.String()}
+	if s.history != nil {
+		if pruned.Runs, err = s.history.Prune(retention); err != nil {
+			http.Error(w, "Error saving run history: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if s.jobs != nil {
+		if pruned.Jobs, err = s.jobs.Prune(retention); err != nil {
+			http.Error(w, "Error saving job queue: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if s.snapshots != nil && retention.MaxAge > 0 {
+		pruned.Snapshots = s.snapshots.PruneBefore(time.Now().Add(-retention.MaxAge))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pruned)
+}
@@ -0,0 +1,27 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// handleRuns serves the multi-run comparison page
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.runsTemplate.Execute(w, nil); err != nil {
+		http.Error(w, "Error rendering template", http.StatusInternalServerError)
+	}
+}
+
+// defaultRuns is how many run summaries /api/runs returns without ?n=
+const defaultRuns = 10
+
+// handleRunsAPI returns the last N run summaries as JSON (?n=10)
+func (s *Server) handleRunsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if s.history == nil {
+		w.Write([]byte("[]"))
+		return
+	}
+	json.NewEncoder(w).Encode(s.history.Last(topN(r, defaultRuns)))
+}
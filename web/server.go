@@ -1,32 +1,60 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
+	"gocrawler/crawler"
+	"gocrawler/jobs"
 	"gocrawler/storage"
+
+	"github.com/klauspost/compress/gzhttp"
 )
 
 // Server represents the web dashboard server
 type Server struct {
-	port     int
-	results  *storage.Results
-	template *template.Template
+	port             int
+	results          *storage.Results
+	history          *storage.History
+	template         *template.Template
+	runsTemplate     *template.Template
+	jobs             *jobs.Manager
+	snapshots        *storage.SnapshotStore
+	botInfo          *BotInfo
+	botInfoTemplate  *template.Template
+	crawler          *crawler.Crawler // of a single crawl, nil in daemon mode
+	profiles         *jobs.Profiles
+	profilesTemplate *template.Template
+	widgetTemplate   *template.Template
+	graphTemplate    *template.Template
+	pageListTemplate *template.Template
+	assets           fs.FS             // templates and static/ files, builtinAssets unless overridden
+	retention        storage.Retention // default of POST /api/prune
+	tokens           []APIToken        // required when set
+	limiter          *apiLimiter       // of /api/ requests, nil disables
+	server           *http.Server
+	mu               sync.Mutex
 }
 
-// NewServer creates a new Server instance
-func NewServer(port int, results *storage.Results) *Server {
-	// Parse template once at startup for security and performance
-	tmpl := template.Must(template.New("dashboard").Parse(dashboardHTML))
-
-	return &Server{
-		port:     port,
-		results:  results,
-		template: tmpl,
+// NewServer creates a new Server instance, history may be nil
+func NewServer(port int, results *storage.Results, history *storage.History) *Server {
+	s := &Server{
+		port:    port,
+		results: results,
+		history: history,
 	}
+	// Parse templates once at startup for security and performance
+	if err := s.parseTemplates(builtinAssets); err != nil {
+		panic(err)
+	}
+	return s
 }
 
 // Start starts the web server
@@ -34,263 +62,385 @@ func (s *Server) Start() error {
 	mux := http.NewServeMux()
 
 	// Serve static files
+	static, err := fs.Sub(s.assets, "static")
+	if err != nil {
+		return err
+	}
+	mux.Handle("/static/", http.StripPrefix("/static", http.FileServerFS(static)))
 	mux.HandleFunc("/", s.handleIndex)
 	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.HandleFunc("/api/i18n", s.handleI18n)
+	mux.HandleFunc("/widget/{widget}", s.handleWidget)
+	mux.HandleFunc("/graph", s.handleGraphPage)
+	mux.HandleFunc("/api/graph", s.handleGraph)
 	mux.HandleFunc("/api/pages", s.handlePages)
+	mux.HandleFunc("/api/page-list", s.handlePageList)
+	mux.HandleFunc("/api/pages/{id}", s.handlePage)
+	mux.HandleFunc("/api/pages/{id}/body", s.handlePageBody)
+	mux.HandleFunc("/api/export", s.handleExport)
+	mux.HandleFunc("/api/prune", s.handlePrune)
+	mux.HandleFunc("/api/alternates", s.handleAlternates)
+	mux.HandleFunc("/api/hosts", s.handleHosts)
+	mux.HandleFunc("/api/assets", s.handleAssets)
+	mux.HandleFunc("/api/og-images", s.handleOGImages)
+	mux.HandleFunc("/api/sitemap-gaps", s.handleSitemapGaps)
+	mux.HandleFunc("/api/traffic-orphans", s.handleTrafficOrphans)
+	mux.HandleFunc("/api/redirects", s.handleRedirects)
+	mux.HandleFunc("/api/path", s.handlePath)
+	mux.HandleFunc("/api/links", s.handleLinks)
+	mux.HandleFunc("/api/skipped", s.handleSkipped)
+	mux.HandleFunc("/api/duplicates", s.handleDuplicates)
+	mux.HandleFunc("/api/http-links", s.handleHTTPLinks)
+	mux.HandleFunc("/api/broken-links", s.handleBrokenLinks)
+	mux.HandleFunc("/api/snapshots", s.handleSnapshots)
+	mux.HandleFunc("/api/snapshots/diff", s.handleSnapshotDiff)
+	mux.HandleFunc("/api/text-changes", s.handleTextChanges)
+	mux.HandleFunc("/api/article", s.handleArticle)
+	mux.HandleFunc("/api/keywords", s.handleKeywords)
+	mux.HandleFunc("/api/anchors", s.handleAnchors)
+	mux.HandleFunc("/api/endpoints", s.handleEndpoints)
+	mux.HandleFunc("/api/recon", s.handleRecon)
+	mux.HandleFunc("/api/subdomains", s.handleSubdomains)
+	mux.HandleFunc("/api/slowest", s.handleSlowest)
+	mux.HandleFunc("/api/largest", s.handleLargest)
+	mux.HandleFunc("/bot-info", s.handleBotInfo)
+	mux.HandleFunc("/api/config", s.handleConfig)
+	mux.HandleFunc("/api/workers", s.handleWorkers)
+	mux.HandleFunc("/runs", s.handleRuns)
+	mux.HandleFunc("/api/runs", s.handleRunsAPI)
+	mux.HandleFunc("/api/jobs", s.handleJobs)
+	mux.HandleFunc("/api/crawl", s.handleCrawl)
+	mux.HandleFunc("/api/jobs/{id}", s.handleJob)
+	mux.HandleFunc("/profiles", s.handleProfilesPage)
+	mux.HandleFunc("/api/profiles", s.handleProfiles)
+	mux.HandleFunc("/api/profiles/{name}", s.handleProfile)
+	mux.HandleFunc("/api/profiles/{name}/run", s.handleProfileRun)
 
 	addr := fmt.Sprintf(":%d", s.port)
 	fmt.Printf("🌐 Dashboard starting on http://localhost%s\n", addr)
 
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      gzhttp.GzipHandler(s.limit(s.authorize(mux))),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
+	s.mu.Lock()
+	s.server = server
+	s.mu.Unlock()
+
 	return server.ListenAndServe()
 }
 
+// Shutdown gracefully stops the web server
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	server := s.server
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// dashboardData fills the dashboard template in the request's language
+type dashboardData struct {
+	Lang      string
+	T         map[string]string
+	Languages []Language
+}
+
 // handleIndex serves the main dashboard HTML
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	// Use template execution for proper HTML escaping (security best practice)
-	if err := s.template.Execute(w, nil); err != nil {
+	lang := requestLanguage(r)
+	data := dashboardData{Lang: lang, T: translations[lang], Languages: languages()}
+	if err := s.template.Execute(w, data); err != nil {
 		http.Error(w, "Error rendering template", http.StatusInternalServerError)
 	}
 }
 
 // handleStats returns crawling statistics as JSON
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
-	stats := s.results.GetStats()
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	stats := results.GetStats()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
 
 // handlePages returns all crawled pages as JSON
 func (s *Server) handlePages(w http.ResponseWriter, r *http.Request) {
-	pages := s.results.GetPages()
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	pages := results.GetPages()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pages)
+}
+
+// handleAlternates returns AMP/mobile relationships with parity issues
+func (s *Server) handleAlternates(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	parity := results.AlternateParity()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(parity)
+}
+
+// handleAssets returns the favicon/manifest audit per host
+func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	assets := results.SiteAssets()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assets)
+}
+
+// handleOGImages returns pages with missing or broken og:images
+func (s *Server) handleOGImages(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	issues := results.OGImageIssues()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(issues)
+}
+
+// handleSitemapGaps returns the sitemap/crawl comparison
+func (s *Server) handleSitemapGaps(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	gaps := results.SitemapGaps()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gaps)
+}
+
+// handleTrafficOrphans returns URLs with traffic but no internal links
+func (s *Server) handleTrafficOrphans(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	orphans := results.TrafficOrphans()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orphans)
+}
+
+// handleRedirects returns redirecting links and canonical chains
+func (s *Server) handleRedirects(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	chains := results.Chains()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chains)
+}
+
+// handlePath returns the discovery path of ?url= from the seed
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	path := results.DiscoveryPath(r.URL.Query().Get("url"))
+	if path == nil {
+		path = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(path)
+}
+
+// handleLinks returns the inlinks and outlinks of ?url=
+func (s *Server) handleLinks(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	u := r.URL.Query().Get("url")
+	links := struct {
+		Inlinks  []storage.Edge `json:"inlinks"`
+		Outlinks []storage.Edge `json:"outlinks"`
+	}{results.Inlinks(u), results.Outlinks(u)}
+	if links.Inlinks == nil {
+		links.Inlinks = []storage.Edge{}
+	}
+	if links.Outlinks == nil {
+		links.Outlinks = []storage.Edge{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+// handleSkipped returns the URLs refused by the crawl guards
+func (s *Server) handleSkipped(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	skipped := results.SkippedURLs()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(skipped)
+}
+
+// handleDuplicates returns the sets of URLs with identical content
+func (s *Server) handleDuplicates(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	groups := results.DuplicateGroups()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// handleHTTPLinks returns links to http versions of https pages
+func (s *Server) handleHTTPLinks(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	links := results.HTTPLinks()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+// handleBrokenLinks returns internal links to failed pages
+func (s *Server) handleBrokenLinks(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	broken := results.BrokenLinks()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(broken)
+}
+
+// handleArticle returns the extracted main content of ?url=
+func (s *Server) handleArticle(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	article := results.Article(r.URL.Query().Get("url"))
+	if article == nil {
+		http.Error(w, "no article for this URL (crawl with -articles)", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(article)
+}
+
+// handleKeywords returns the site-wide top keywords and entities (?n=20)
+func (s *Server) handleKeywords(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	summary := results.Keywords(topN(r, defaultTopN))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// handleAnchors returns the anchor texts used per linked URL
+func (s *Server) handleAnchors(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	texts := results.AnchorTexts()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(texts)
+}
+
+// handleEndpoints returns URLs found in JavaScript
+func (s *Server) handleEndpoints(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	endpoints := results.Endpoints()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(endpoints)
+}
+
+// handleRecon returns the reconnaissance findings
+func (s *Server) handleRecon(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	findings := results.Recon()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(findings)
+}
+
+// handleSubdomains returns candidate subdomain seeds
+func (s *Server) handleSubdomains(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	subs := results.Subdomains()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// handleHosts returns per-host statistics as JSON
+func (s *Server) handleHosts(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	hosts := results.HostStats()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hosts)
+}
+
+// handleSlowest returns the top-N slowest pages as JSON (?n=20)
+func (s *Server) handleSlowest(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	pages := results.Slowest(topN(r, defaultTopN))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(pages)
 }
 
-const dashboardHTML = `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Go Crawler Dashboard</title>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body {
-            font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            color: #333;
-            padding: 20px;
-            min-height: 100vh;
-        }
-        .container {
-            max-width: 1200px;
-            margin: 0 auto;
-            background: white;
-            border-radius: 15px;
-            box-shadow: 0 20px 60px rgba(0,0,0,0.3);
-            overflow: hidden;
-        }
-        header {
-            background: linear-gradient(135deg, #5a67d8 0%, #6b46c1 100%);
-            color: white;
-            padding: 30px;
-            text-align: center;
-        }
-        h1 { font-size: 2.5em; margin-bottom: 10px; }
-        .subtitle { opacity: 0.9; font-size: 1.1em; }
-        .stats {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
-            gap: 20px;
-            padding: 30px;
-            background: #f7fafc;
-        }
-        .stat-card {
-            background: white;
-            padding: 20px;
-            border-radius: 10px;
-            box-shadow: 0 4px 6px rgba(0,0,0,0.1);
-            text-align: center;
-            transition: transform 0.2s;
-        }
-        .stat-card:hover { transform: translateY(-5px); }
-        .stat-value {
-            font-size: 2.5em;
-            font-weight: bold;
-            color: #5a67d8;
-            margin: 10px 0;
-        }
-        .stat-label {
-            color: #718096;
-            font-size: 0.9em;
-            text-transform: uppercase;
-            letter-spacing: 1px;
-        }
-        .pages-section {
-            padding: 30px;
-        }
-        .pages-section h2 {
-            color: #2d3748;
-            margin-bottom: 20px;
-            font-size: 1.8em;
-        }
-        .page-item {
-            background: #f7fafc;
-            padding: 15px;
-            margin-bottom: 15px;
-            border-radius: 8px;
-            border-left: 4px solid #5a67d8;
-            transition: all 0.2s;
-        }
-        .page-item:hover {
-            background: #edf2f7;
-            transform: translateX(5px);
-        }
-        .page-item.error {
-            border-left-color: #f56565;
-        }
-        .page-url {
-            color: #5a67d8;
-            font-weight: bold;
-            word-break: break-all;
-            margin-bottom: 5px;
-        }
-        .page-title {
-            color: #2d3748;
-            margin-bottom: 5px;
-        }
-        .page-meta {
-            color: #718096;
-            font-size: 0.85em;
-        }
-        .page-error {
-            color: #f56565;
-            font-weight: bold;
-            margin-top: 5px;
-        }
-        .loading {
-            text-align: center;
-            padding: 40px;
-            color: #718096;
-            font-size: 1.2em;
-        }
-        .spinner {
-            border: 4px solid #f3f4f6;
-            border-top: 4px solid #5a67d8;
-            border-radius: 50%;
-            width: 40px;
-            height: 40px;
-            animation: spin 1s linear infinite;
-            margin: 20px auto;
-        }
-        @keyframes spin {
-            0% { transform: rotate(0deg); }
-            100% { transform: rotate(360deg); }
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <header>
-            <h1>🚀 Go Concurrent Web Crawler</h1>
-            <p class="subtitle">Real-time Dashboard - Demonstrating Goroutines & Channels</p>
-        </header>
-
-        <div class="stats" id="stats">
-            <div class="loading">
-                <div class="spinner"></div>
-                Loading statistics...
-            </div>
-        </div>
-
-        <div class="pages-section">
-            <h2>📄 Crawled Pages</h2>
-            <div id="pages">
-                <div class="loading">
-                    <div class="spinner"></div>
-                    Loading pages...
-                </div>
-            </div>
-        </div>
-    </div>
-
-    <script>
-        // Auto-refresh every 2 seconds
-        function fetchStats() {
-            fetch('/api/stats')
-                .then(res => res.json())
-                .then(data => {
-                    document.getElementById('stats').innerHTML = ` + "`" + `
-                        <div class="stat-card">
-                            <div class="stat-label">Total Pages</div>
-                            <div class="stat-value">${data.TotalPages || 0}</div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-label">Unique Links</div>
-                            <div class="stat-value">${data.UniqueLinks || 0}</div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-label">Success Rate</div>
-                            <div class="stat-value">${data.TotalPages ? Math.round(data.SuccessCount / data.TotalPages * 100) : 0}%</div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-label">Avg Response</div>
-                            <div class="stat-value">${Math.round(data.AvgResponseTime || 0)}<span style="font-size: 0.5em;">ms</span></div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-label">Successful</div>
-                            <div class="stat-value" style="color: #48bb78;">${data.SuccessCount || 0}</div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-label">Failed</div>
-                            <div class="stat-value" style="color: #f56565;">${data.FailCount || 0}</div>
-                        </div>
-                    ` + "`" + `;
-                })
-                .catch(err => console.error('Error fetching stats:', err));
-        }
-
-        function fetchPages() {
-            fetch('/api/pages')
-                .then(res => res.json())
-                .then(data => {
-                    if (!data || data.length === 0) {
-                        document.getElementById('pages').innerHTML = '<div class="loading">No pages crawled yet...</div>';
-                        return;
-                    }
-
-                    document.getElementById('pages').innerHTML = data.map(page => ` + "`" + `
-                        <div class="page-item ${page.success ? '' : 'error'}">
-                            <div class="page-url">${page.url}</div>
-                            ${page.title ? ` + "`<div class=\"page-title\">${page.title}</div>`" + ` : ''}
-                            <div class="page-meta">
-                                ⏱️ ${page.response_time_ms / 1000000}ms |
-                                🔗 ${page.links ? page.links.length : 0} links |
-                                📅 ${new Date(page.crawled_at).toLocaleTimeString()}
-                            </div>
-                            ${!page.success ? ` + "`<div class=\"page-error\">❌ Error: ${page.error}</div>`" + ` : ''}
-                        </div>
-                    ` + "`" + `).join('');
-                })
-                .catch(err => console.error('Error fetching pages:', err));
-        }
-
-        // Initial fetch
-        fetchStats();
-        fetchPages();
-
-        // Auto-refresh every 2 seconds
-        setInterval(() => {
-            fetchStats();
-            fetchPages();
-        }, 2000);
-    </script>
-</body>
-</html>`
+// handleLargest returns the top-N largest pages as JSON (?n=20)
+func (s *Server) handleLargest(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	pages := results.Largest(topN(r, defaultTopN))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pages)
+}
+
+// defaultTopN is how many entries the top-N reports return without ?n=
+const defaultTopN = 20
+
+// topN reads the ?n= query parameter, defaulting to def
+func topN(r *http.Request, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"gocrawler/storage"
+)
+
+// SetSnapshots enables the text snapshot API (-snapshots)
+func (s *Server) SetSnapshots(store *storage.SnapshotStore) {
+	s.snapshots = store
+}
+
+// handleSnapshots returns the text snapshots kept for ?url=
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	if s.snapshots == nil {
+		http.Error(w, "text snapshots require -snapshots", http.StatusServiceUnavailable)
+		return
+	}
+	url := r.URL.Query().Get("url")
+	if url == "" {
+		http.Error(w, "missing url parameter", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.snapshots.History(url))
+}
+
+// handleSnapshotDiff returns the unified diff between two snapshots of
+// ?url=, ?from= and ?to= are history positions (default -2 and -1, the
+// last two runs)
+func (s *Server) handleSnapshotDiff(w http.ResponseWriter, r *http.Request) {
+	if s.snapshots == nil {
+		http.Error(w, "text snapshots require -snapshots", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	url := q.Get("url")
+	if url == "" {
+		http.Error(w, "missing url parameter", http.StatusBadRequest)
+		return
+	}
+	from, to := -2, -1
+	if v, err := strconv.Atoi(q.Get("from")); err == nil {
+		from = v
+	}
+	if v, err := strconv.Atoi(q.Get("to")); err == nil {
+		to = v
+	}
+
+	diff, err := s.snapshots.Diff(url, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(diff))
+}
+
+// handleTextChanges returns the pages whose text changed since their
+// last snapshot
+func (s *Server) handleTextChanges(w http.ResponseWriter, r *http.Request) {
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+	changes := results.TextChanges()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"gocrawler/storage"
+)
+
+// defaultWidgetRefresh is how often widgets reload, in seconds
+const defaultWidgetRefresh = 5
+
+// widgetErrors is how many of the latest failed pages the errors widget lists
+const widgetErrors = 5
+
+// widgetData fills a widget template, rendered server side so a wall
+// display only needs an iframe
+type widgetData struct {
+	Widget      string
+	Lang        string
+	T           map[string]string
+	Theme       string // "dark", "light" or "" to follow the viewer's preference
+	Refresh     int
+	Stats       storage.Stats
+	SuccessRate int
+	Failed      []*storage.Page // latest first
+}
+
+// handleWidget serves a minimal auto-refreshing panel meant to be embedded
+// in an iframe, ?theme=dark|light, ?refresh=<seconds> and ?job= apply
+func (s *Server) handleWidget(w http.ResponseWriter, r *http.Request) {
+	widget := r.PathValue("widget")
+	if widget != "stats" && widget != "errors" {
+		http.NotFound(w, r)
+		return
+	}
+	results := s.resultsFor(w, r)
+	if results == nil {
+		return
+	}
+
+	lang := requestLanguage(r)
+	data := widgetData{
+		Widget:  widget,
+		Lang:    lang,
+		T:       translations[lang],
+		Refresh: defaultWidgetRefresh,
+		Stats:   results.GetStats(),
+	}
+	if theme := r.URL.Query().Get("theme"); theme == "dark" || theme == "light" {
+		data.Theme = theme
+	}
+	if refresh, err := strconv.Atoi(r.URL.Query().Get("refresh")); err == nil && refresh > 0 {
+		data.Refresh = refresh
+	}
+	if data.Stats.TotalPages > 0 {
+		data.SuccessRate = data.Stats.SuccessCount * 100 / data.Stats.TotalPages
+	}
+	if widget == "errors" {
+		pages := results.GetPages()
+		for i := len(pages) - 1; i >= 0 && len(data.Failed) < widgetErrors; i-- {
+			if !pages[i].Success {
+				data.Failed = append(data.Failed, pages[i])
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.widgetTemplate.Execute(w, data); err != nil {
+		http.Error(w, "Error rendering template", http.StatusInternalServerError)
+	}
+}
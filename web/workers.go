@@ -0,0 +1,57 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"gocrawler/crawler"
+)
+
+// hostLoad summarizes the busy workers of one host
+type hostLoad struct {
+	Host          string `json:"host"`
+	Workers       int    `json:"workers"`         // busy on this host
+	LongestBusyMS int64  `json:"longest_busy_ms"` // of those workers
+}
+
+// handleWorkers reports what every worker is doing, the hosts busy
+// workers are waiting on, slowest first, and keep-alive connection reuse
+func (s *Server) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	if s.crawler == nil {
+		http.Error(w, "worker introspection requires a single crawl, not -daemon", http.StatusServiceUnavailable)
+		return
+	}
+
+	workers := s.crawler.Workers()
+	if workers == nil {
+		workers = []crawler.WorkerStatus{}
+	}
+	byHost := make(map[string]*hostLoad)
+	for _, worker := range workers {
+		if worker.URL == "" {
+			continue
+		}
+		load, ok := byHost[worker.Host]
+		if !ok {
+			load = &hostLoad{Host: worker.Host}
+			byHost[worker.Host] = load
+		}
+		load.Workers++
+		load.LongestBusyMS = max(load.LongestBusyMS, worker.BusyMS)
+	}
+	hosts := make([]hostLoad, 0, len(byHost))
+	for _, load := range byHost {
+		hosts = append(hosts, *load)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].LongestBusyMS > hosts[j].LongestBusyMS })
+
+	w.Header().Set("Content-Type", "application/json")
+	conns := s.crawler.ConnStats()
+	json.NewEncoder(w).Encode(struct {
+		Workers     []crawler.WorkerStatus `json:"workers"`
+		Hosts       []hostLoad             `json:"hosts"`
+		Connections crawler.ConnStats      `json:"connections"`
+		ReuseRate   float64                `json:"connection_reuse_rate"`
+	}{workers, hosts, conns, conns.ReuseRate()})
+}